@@ -0,0 +1,272 @@
+package bootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/garslo/rdap-client/protocol"
+)
+
+const (
+	IANABootstrapDNS        = "https://data.iana.org/rdap/dns.json"
+	IANABootstrapIPv4       = "https://data.iana.org/rdap/ipv4.json"
+	IANABootstrapIPv6       = "https://data.iana.org/rdap/ipv6.json"
+	IANABootstrapASN        = "https://data.iana.org/rdap/asn.json"
+	IANABootstrapObjectTags = "https://data.iana.org/rdap/object-tags.json"
+)
+
+func FetchServiceRegistry(ctx context.Context, client *http.Client, url string) (ServiceRegistry, error) {
+	var registry ServiceRegistry
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return registry, err
+	}
+
+	resp, err := client.Do(req)
+
+	if err != nil {
+		return registry, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return registry, fmt.Errorf("unexpected status %d fetching %s", resp.StatusCode, url)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&registry)
+
+	return registry, err
+}
+
+// RegistryChange describes how the URIs for one bootstrap entry differ
+// between two snapshots. Old is nil for added entries and New is nil for
+// removed ones.
+type RegistryChange struct {
+	Entry string
+	Old   []string
+	New   []string
+}
+
+func (c RegistryChange) String() string {
+	switch {
+	case c.Old == nil:
+		return fmt.Sprintf("+ %s: %s", c.Entry, strings.Join(c.New, " "))
+	case c.New == nil:
+		return fmt.Sprintf("- %s: %s", c.Entry, strings.Join(c.Old, " "))
+	}
+
+	return fmt.Sprintf("~ %s: %s -> %s", c.Entry, strings.Join(c.Old, " "), strings.Join(c.New, " "))
+}
+
+func (s ServiceRegistry) entryURIs() map[string][]string {
+	uris := map[string][]string{}
+
+	for _, service := range s.Services {
+		for _, entry := range service.Entries() {
+			uris[strings.ToLower(entry)] = service.URIs()
+		}
+	}
+
+	return uris
+}
+
+// Changes lists the entries added, removed or pointed at different URIs in s
+// compared with previous, sorted by entry.
+func (s ServiceRegistry) Changes(previous ServiceRegistry) []RegistryChange {
+	var (
+		changes []RegistryChange
+		old     = previous.entryURIs()
+		current = s.entryURIs()
+	)
+
+	for entry, uris := range current {
+		if oldURIs, ok := old[entry]; !ok {
+			changes = append(changes, RegistryChange{Entry: entry, New: uris})
+		} else if !reflect.DeepEqual([]string(oldURIs), []string(uris)) {
+			changes = append(changes, RegistryChange{Entry: entry, Old: oldURIs, New: uris})
+		}
+	}
+
+	for entry, uris := range old {
+		if _, ok := current[entry]; !ok {
+			changes = append(changes, RegistryChange{Entry: entry, Old: uris})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Entry < changes[j].Entry
+	})
+
+	return changes
+}
+
+// RefreshServiceRegistry fetches url and returns the fetched registry along
+// with its changes since previous. When the fetched publication is not newer
+// than previous, previous is returned unchanged. Pairing client with a
+// client.CachingTransport makes the fetch conditional.
+func RefreshServiceRegistry(ctx context.Context, client *http.Client, url string, previous ServiceRegistry) (ServiceRegistry, []RegistryChange, error) {
+	registry, err := FetchServiceRegistry(ctx, client, url)
+
+	if err != nil {
+		return previous, nil, err
+	}
+
+	if !previous.Publication.IsZero() && !registry.Publication.After(previous.Publication) {
+		return previous, nil, nil
+	}
+
+	return registry, registry.Changes(previous), nil
+}
+
+type EndpointState string
+
+const (
+	EndpointUp          EndpointState = "up"
+	EndpointDown        EndpointState = "down"
+	EndpointRedirecting EndpointState = "redirecting"
+	EndpointInvalid     EndpointState = "invalid"
+)
+
+type EndpointStatus struct {
+	URL    string
+	State  EndpointState
+	Detail string
+}
+
+func (s EndpointStatus) String() string {
+	if s.Detail == "" {
+		return fmt.Sprintf("%s %s", s.State, s.URL)
+	}
+
+	return fmt.Sprintf("%s %s (%s)", s.State, s.URL, s.Detail)
+}
+
+// CheckEndpoints issues a /help query to every distinct URL in registries,
+// giving each at most timeout, and reports whether it is up, down,
+// redirecting or not serving RDAP. Redirects are reported rather than
+// followed. Results are sorted by URL.
+func CheckEndpoints(ctx context.Context, client *http.Client, timeout time.Duration, registries ...ServiceRegistry) []EndpointStatus {
+	var (
+		urls     []string
+		seen     = map[string]bool{}
+		statuses []EndpointStatus
+		mutex    sync.Mutex
+		wg       sync.WaitGroup
+	)
+
+	for _, registry := range registries {
+		for _, service := range registry.Services {
+			for _, uri := range service.URIs() {
+				if !seen[uri] {
+					seen[uri] = true
+					urls = append(urls, uri)
+				}
+			}
+		}
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	semaphore := make(chan struct{}, 8)
+
+	for _, uri := range urls {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(uri string) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			status := checkEndpoint(ctx, &noRedirects, timeout, uri)
+
+			mutex.Lock()
+			statuses = append(statuses, status)
+			mutex.Unlock()
+		}(uri)
+	}
+
+	wg.Wait()
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].URL < statuses[j].URL
+	})
+
+	return statuses
+}
+
+func checkEndpoint(ctx context.Context, client *http.Client, timeout time.Duration, uri string) EndpointStatus {
+	status := EndpointStatus{URL: uri}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(uri, "/")+"/help", nil)
+
+	if err != nil {
+		status.State, status.Detail = EndpointInvalid, err.Error()
+		return status
+	}
+
+	req.Header.Set("Accept", protocol.MediaTypeRDAP)
+	resp, err := client.Do(req)
+
+	if err != nil {
+		status.State, status.Detail = EndpointDown, err.Error()
+		return status
+	}
+
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		status.State, status.Detail = EndpointRedirecting, resp.Header.Get("Location")
+		return status
+	case resp.StatusCode != http.StatusOK:
+		status.State, status.Detail = EndpointDown, resp.Status
+		return status
+	}
+
+	var help struct {
+		RDAPConformance []string `json:"rdapConformance"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&help); err != nil {
+		status.State, status.Detail = EndpointInvalid, err.Error()
+		return status
+	}
+
+	if len(help.RDAPConformance) == 0 {
+		status.State, status.Detail = EndpointInvalid, "missing rdapConformance"
+		return status
+	}
+
+	status.State = EndpointUp
+
+	return status
+}
@@ -0,0 +1,119 @@
+package bootstrap
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRefreshServiceRegistry(t *testing.T) {
+	body := `{
+		"version": "1.0",
+		"publication": "2024-01-02T00:00:00Z",
+		"services": [
+			[["com", "net"], ["https://rdap.verisign.com/com/v1/"]],
+			[["dev"], ["https://pubapi.registry.google/rdap/"]]
+		]
+	}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	previous := ServiceRegistry{
+		Version:     "1.0",
+		Publication: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Services: ServicesList{
+			{{"com"}, {"https://rdap.verisign.com/com/v1/"}},
+			{{"net"}, {"https://rdap.verisign.com/net/v1/"}},
+			{{"org"}, {"https://rdap.publicinterestregistry.org/rdap/"}},
+		},
+	}
+
+	tests := []struct {
+		description string
+		previous    ServiceRegistry
+		expected    []string
+	}{
+		{
+			description: "it should report added, removed and changed entries",
+			previous:    previous,
+			expected: []string{
+				"+ dev: https://pubapi.registry.google/rdap/",
+				"~ net: https://rdap.verisign.com/net/v1/ -> https://rdap.verisign.com/com/v1/",
+				"- org: https://rdap.publicinterestregistry.org/rdap/",
+			},
+		},
+		{
+			description: "it should keep a snapshot that is not older than the fetched registry",
+			previous:    ServiceRegistry{Publication: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	for i, test := range tests {
+		_, changes, err := RefreshServiceRegistry(context.Background(), server.Client(), server.URL, test.previous)
+
+		if err != nil {
+			t.Fatalf("At index %d (%s): unexpected error %s", i, test.description, err)
+		}
+
+		var report []string
+
+		for _, change := range changes {
+			report = append(report, change.String())
+		}
+
+		if !reflect.DeepEqual(test.expected, report) {
+			t.Fatalf("At index %d (%s): expected %q, got %q", i, test.description, test.expected, report)
+		}
+	}
+}
+
+func TestCheckEndpoints(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/up/help":
+			w.Write([]byte(`{"rdapConformance": ["rdap_level_0"]}`))
+		case "/moved/help":
+			http.Redirect(w, r, "https://rdap.example.net/help", http.StatusMovedPermanently)
+		case "/html/help":
+			w.Write([]byte(`<html>Not RDAP</html>`))
+		case "/slow/help":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	registry := ServiceRegistry{
+		Services: ServicesList{
+			{{"a"}, {server.URL + "/up/", server.URL + "/moved/"}},
+			{{"b"}, {server.URL + "/html/", server.URL + "/up/"}},
+			{{"c"}, {server.URL + "/slow/", server.URL + "/missing/"}},
+		},
+	}
+
+	statuses := CheckEndpoints(context.Background(), server.Client(), 50*time.Millisecond, registry)
+	expected := map[string]EndpointState{
+		server.URL + "/html/":    EndpointInvalid,
+		server.URL + "/missing/": EndpointDown,
+		server.URL + "/moved/":   EndpointRedirecting,
+		server.URL + "/slow/":    EndpointDown,
+		server.URL + "/up/":      EndpointUp,
+	}
+
+	if len(statuses) != len(expected) {
+		t.Fatalf("expected %d endpoints, got %v", len(expected), statuses)
+	}
+
+	for i, status := range statuses {
+		if expected[status.URL] != status.State {
+			t.Fatalf("At index %d: expected %s to be %s, got %s", i, status.URL, expected[status.URL], status)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package bootstrap
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/garslo/rdap-client/protocol"
+)
+
+// Index answers the Match queries of a registry from lookup tables rather
+// than a scan of every entry: a map of entries for domains, a binary trie of
+// prefixes per address family and a sorted interval table for AS ranges.
+// Each part is built on its first use, so an Index only pays for the
+// lookups it serves, and records the first malformed entry it met so that
+// lookups keep failing with it as a scan of the entries would.
+//
+// An Index holds a copy of the services it was compiled from, so later
+// edits to the registry don't reach it; compile the registry again to see
+// them.
+type Index struct {
+	services ServicesList
+
+	domainsOnce sync.Once
+	domains     map[string][]string
+
+	networksOnce sync.Once
+	ipv4, ipv6   trieNode
+	networksErr  error
+
+	rangesOnce sync.Once
+	ranges     []asRange
+	maxEnd     []uint32
+	rangesErr  error
+}
+
+// Compile returns an Index of the services of s. Malformed entries are
+// reported by the Match methods that use them, not by Compile.
+func (s ServiceRegistry) Compile() *Index {
+	services := make(ServicesList, len(s.Services))
+
+	for n, service := range s.Services {
+		services[n] = Service{append(Values(nil), service.Entries()...), append(Values(nil), service.URIs()...)}
+	}
+
+	return &Index{services: services}
+}
+
+// MatchAS returns the URIs of the smallest range holding asn, as
+// ServiceRegistry.MatchAS does.
+func (i *Index) MatchAS(asn uint32) ([]string, error) {
+	return i.matchAS(asn)
+}
+
+// MatchIPNetwork returns the URIs of the most specific entry covering all of
+// network, as ServiceRegistry.MatchIPNetwork does.
+func (i *Index) MatchIPNetwork(network *net.IPNet) ([]string, error) {
+	return i.matchIPNetwork(network)
+}
+
+// MatchDomain returns the URIs of the longest entry fqdn falls under, as
+// ServiceRegistry.MatchDomain does.
+func (i *Index) MatchDomain(fqdn string) ([]string, error) {
+	fqdn, err := protocol.NormalizeDomain(fqdn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return i.matchDomain(strings.Split(fqdn, ".")), nil
+}
+
+func (i *Index) buildDomains() {
+	i.domains = map[string][]string{}
+
+	for _, service := range i.services {
+		for _, entry := range service.Entries() {
+			if _, ok := i.domains[strings.ToLower(entry)]; !ok {
+				i.domains[strings.ToLower(entry)] = service.URIs()
+			}
+		}
+	}
+}
+
+func (i *Index) matchDomain(labels []string) []string {
+	i.domainsOnce.Do(i.buildDomains)
+
+	for start := range labels {
+		if uris, ok := i.domains[strings.Join(labels[start:], ".")]; ok {
+			return uris
+		}
+	}
+
+	return nil
+}
+
+type trieNode struct {
+	children [2]*trieNode
+	uris     []string
+	set      bool
+}
+
+func (n *trieNode) insert(ip net.IP, ones int, uris []string) {
+	for bit := 0; bit < ones; bit++ {
+		b := ip[bit/8] >> (7 - bit%8) & 1
+
+		if n.children[b] == nil {
+			n.children[b] = &trieNode{}
+		}
+
+		n = n.children[b]
+	}
+
+	if !n.set {
+		n.uris, n.set = uris, true
+	}
+}
+
+// lookup returns the URIs of the longest prefix of ip/ones in the trie.
+func (n *trieNode) lookup(ip net.IP, ones int) []string {
+	var uris []string
+
+	for bit := 0; n != nil; bit++ {
+		if n.set {
+			uris = n.uris
+		}
+
+		if bit == ones {
+			break
+		}
+
+		n = n.children[ip[bit/8]>>(7-bit%8)&1]
+	}
+
+	return uris
+}
+
+func (i *Index) buildNetworks() {
+	for _, service := range i.services {
+		for _, entry := range service.Entries() {
+			_, ipnet, err := net.ParseCIDR(entry)
+
+			if err != nil {
+				i.networksErr = err
+				return
+			}
+
+			ones, bits := ipnet.Mask.Size()
+
+			if bits == 8*net.IPv4len {
+				i.ipv4.insert(ipnet.IP.To4(), ones, service.URIs())
+			} else {
+				i.ipv6.insert(ipnet.IP.To16(), ones, service.URIs())
+			}
+		}
+	}
+}
+
+func (i *Index) matchIPNetwork(network *net.IPNet) ([]string, error) {
+	i.networksOnce.Do(i.buildNetworks)
+
+	if i.networksErr != nil {
+		return nil, i.networksErr
+	}
+
+	ones, bits := network.Mask.Size()
+
+	switch {
+	case bits == 8*net.IPv4len && network.IP.To4() != nil:
+		return i.ipv4.lookup(network.IP.To4(), ones), nil
+	case bits == 8*net.IPv6len && len(network.IP) == net.IPv6len:
+		return i.ipv6.lookup(network.IP, ones), nil
+	}
+
+	return nil, nil
+}
+
+type asRange struct {
+	begin, end uint32
+	order      int
+	uris       []string
+}
+
+// buildRanges sorts the AS ranges by their first number, and records for
+// each the highest last number up to it, so that a lookup can stop walking
+// back as soon as no earlier range reaches the number looked up.
+func (i *Index) buildRanges() {
+	for _, service := range i.services {
+		for _, entry := range service.Entries() {
+			begin, end, err := parseASRange(entry)
+
+			if err != nil {
+				i.rangesErr = err
+				return
+			}
+
+			if end >= begin {
+				i.ranges = append(i.ranges, asRange{begin: begin, end: end, order: len(i.ranges), uris: service.URIs()})
+			}
+		}
+	}
+
+	sort.SliceStable(i.ranges, func(a, b int) bool { return i.ranges[a].begin < i.ranges[b].begin })
+
+	i.maxEnd = make([]uint32, len(i.ranges))
+
+	for n, r := range i.ranges {
+		i.maxEnd[n] = r.end
+
+		if n > 0 && i.maxEnd[n-1] > r.end {
+			i.maxEnd[n] = i.maxEnd[n-1]
+		}
+	}
+}
+
+func (i *Index) matchAS(asn uint32) ([]string, error) {
+	i.rangesOnce.Do(i.buildRanges)
+
+	if i.rangesErr != nil {
+		return nil, i.rangesErr
+	}
+
+	var (
+		uris  []string
+		size  uint32 = math.MaxUint32
+		order        = -1
+	)
+
+	last := sort.Search(len(i.ranges), func(n int) bool { return i.ranges[n].begin > asn }) - 1
+
+	for n := last; n >= 0 && i.maxEnd[n] >= asn; n-- {
+		r := i.ranges[n]
+
+		if r.end < asn {
+			continue
+		}
+
+		if r.end-r.begin < size || order >= 0 && r.end-r.begin == size && r.order < order {
+			size, order, uris = r.end-r.begin, r.order, r.uris
+		}
+	}
+
+	return uris, nil
+}
+
+// parseASRange parses an asn registry entry such as "64512-65534".
+func parseASRange(entry string) (uint32, uint32, error) {
+	first, last, ok := strings.Cut(entry, "-")
+
+	if !ok {
+		last = first
+	}
+
+	begin, err := parseASN(first)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	end, err := parseASN(last)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return begin, end, nil
+}
+
+func parseASN(s string) (uint32, error) {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if n < 0 || n > math.MaxUint32 {
+		return 0, fmt.Errorf("asn %d out of range", n)
+	}
+
+	return uint32(n), nil
+}
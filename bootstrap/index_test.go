@@ -0,0 +1,92 @@
+package bootstrap
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestCompileReportsErrorsLazily(t *testing.T) {
+	index := ServiceRegistry{
+		Services: ServicesList{
+			{{"example", "64496-invalid"}, {"https://example.org/"}},
+		},
+	}.Compile()
+
+	if urls, err := index.MatchDomain("www.example"); err != nil || !reflect.DeepEqual([]string{"https://example.org/"}, urls) {
+		t.Fatalf("expected example.org, got %v, %v", urls, err)
+	}
+
+	_, network, _ := net.ParseCIDR("192.0.2.0/24")
+
+	for i := 0; i < 2; i++ {
+		if _, err := index.MatchIPNetwork(network); err == nil || err.Error() != "invalid CIDR address: example" {
+			t.Fatalf("expected the first malformed prefix, got %v", err)
+		}
+
+		if _, err := index.MatchAS(64496); err == nil || err.Error() != `strconv.ParseInt: parsing "example": invalid syntax` {
+			t.Fatalf("expected the first malformed range, got %v", err)
+		}
+	}
+}
+
+// benchmarkRegistries returns registries of 4096 prefixes and 4096 AS
+// ranges, about the size of the ones IANA publishes.
+func benchmarkRegistries() (networks, asns ServiceRegistry) {
+	for i := 0; i < 4096; i++ {
+		uris := Values{fmt.Sprintf("https://rdap%d.example/", i%5)}
+
+		networks.Services = append(networks.Services, Service{{fmt.Sprintf("10.%d.%d.0/24", i/16, i%16*16)}, uris})
+		asns.Services = append(asns.Services, Service{{fmt.Sprintf("%d-%d", i*64, i*64+63)}, uris})
+	}
+
+	return networks, asns
+}
+
+func BenchmarkMatchIPNetwork(b *testing.B) {
+	networks, _ := benchmarkRegistries()
+	_, network, _ := net.ParseCIDR("10.200.3.4/32")
+
+	for n, registry := range []matcher{networks, networks.Compile()} {
+		b.Run(fmt.Sprintf("compiled=%t", n == 1), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := registry.MatchIPNetwork(network); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkMatchAS(b *testing.B) {
+	_, asns := benchmarkRegistries()
+
+	for n, registry := range []matcher{asns, asns.Compile()} {
+		b.Run(fmt.Sprintf("compiled=%t", n == 1), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := registry.MatchAS(uint32(i % (4096 * 64))); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkMatchDomain(b *testing.B) {
+	var domains ServiceRegistry
+
+	for i := 0; i < 1500; i++ {
+		domains.Services = append(domains.Services, Service{{fmt.Sprintf("tld%d", i)}, {"https://rdap.example/"}})
+	}
+
+	for n, registry := range []matcher{domains, domains.Compile()} {
+		b.Run(fmt.Sprintf("compiled=%t", n == 1), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := registry.MatchDomain("www.example.tld1499"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
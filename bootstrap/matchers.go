@@ -0,0 +1,106 @@
+package bootstrap
+
+import (
+	"math"
+	"net"
+	"strings"
+
+	"github.com/garslo/rdap-client/protocol"
+)
+
+// MatchAS returns the URIs of the smallest range holding asn. Like
+// MatchDomain, it returns no URIs when no range does.
+func (s ServiceRegistry) MatchAS(asn uint32) ([]string, error) {
+	var (
+		uris []string
+		size uint32 = math.MaxUint32
+	)
+
+	for _, service := range s.Services {
+		for _, entry := range service.Entries() {
+			begin, end, err := parseASRange(entry)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if asn >= begin && asn <= end && end-begin < size {
+				size = end - begin
+				uris = service.URIs()
+			}
+		}
+	}
+
+	return uris, nil
+}
+
+// MatchIPNetwork returns the URIs of the most specific entry covering all of
+// network.
+func (s ServiceRegistry) MatchIPNetwork(network *net.IPNet) ([]string, error) {
+	var (
+		uris       []string
+		best       = -1
+		ones, bits = network.Mask.Size()
+	)
+
+	for _, service := range s.Services {
+		for _, entry := range service.Entries() {
+			_, ipnet, err := net.ParseCIDR(entry)
+
+			if err != nil {
+				return nil, err
+			}
+
+			entryOnes, entryBits := ipnet.Mask.Size()
+
+			if entryBits == bits && entryOnes <= ones && entryOnes > best && ipnet.Contains(network.IP) {
+				uris = service.URIs()
+				best = entryOnes
+			}
+		}
+	}
+
+	return uris, nil
+}
+
+func (s ServiceRegistry) MatchDomain(fqdn string) ([]string, error) {
+	var (
+		uris []string
+		size int
+	)
+
+	fqdn, err := protocol.NormalizeDomain(fqdn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fqdnParts := strings.Split(fqdn, ".")
+
+	for _, service := range s.Services {
+		for _, entry := range service.Entries() {
+			entryParts := strings.Split(strings.ToLower(entry), ".")
+
+			if len(entryParts) > len(fqdnParts) || len(entryParts) <= size {
+				continue
+			}
+
+			if labelsEqual(entryParts, fqdnParts[len(fqdnParts)-len(entryParts):]) {
+				uris = service.URIs()
+				size = len(entryParts)
+			}
+		}
+	}
+
+	return uris, nil
+}
+
+func labelsEqual(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
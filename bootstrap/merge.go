@@ -0,0 +1,123 @@
+package bootstrap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ConflictPolicy decides what Merge does with an entry that registries map
+// to different URIs.
+type ConflictPolicy int
+
+const (
+	// ConflictFail keeps the URIs of the first registry and reports every
+	// conflict in a *ConflictError.
+	ConflictFail ConflictPolicy = iota
+	// ConflictKeepFirst silently keeps the URIs of the first registry.
+	ConflictKeepFirst
+	// ConflictKeepLast silently keeps the URIs of the last registry, for
+	// overlays that override part of the registry they are merged onto.
+	ConflictKeepLast
+)
+
+// Conflict is an entry that two registries map to different URIs.
+type Conflict struct {
+	Entry       string
+	URIs        []string
+	Conflicting []string
+}
+
+// ConflictError is the error Merge returns under ConflictFail, along with
+// the merged registry, listing every conflict it met.
+type ConflictError struct {
+	Conflicts []Conflict
+}
+
+func (e *ConflictError) Error() string {
+	conflicts := make([]string, len(e.Conflicts))
+
+	for i, conflict := range e.Conflicts {
+		conflicts[i] = fmt.Sprintf("%s (%s and %s)", conflict.Entry, strings.Join(conflict.URIs, ", "), strings.Join(conflict.Conflicting, ", "))
+	}
+
+	return "bootstrap: conflicting entries " + strings.Join(conflicts, "; ")
+}
+
+// Merge combines registries, such as the one published by IANA and private
+// overlays, failing on conflicting entries as ConflictFail.Merge does.
+func Merge(registries ...ServiceRegistry) (ServiceRegistry, error) {
+	return ConflictFail.Merge(registries...)
+}
+
+// Merge combines registries into one holding every entry once, with
+// entries resolved to the same URIs sharing a service. Domain entries are
+// compared regardless of case. The merged registry was published when the
+// latest of registries was, and its description joins theirs. With
+// ConflictFail, the merged registry is returned along with the error.
+func (p ConflictPolicy) Merge(registries ...ServiceRegistry) (ServiceRegistry, error) {
+	type assignment struct {
+		entry string
+		uris  Values
+	}
+
+	var (
+		merged       = ServiceRegistry{Version: "1.0"}
+		descriptions []string
+		entries      []string
+		assignments  = map[string]*assignment{}
+		conflicts    []Conflict
+	)
+
+	for _, registry := range registries {
+		if registry.Publication.After(merged.Publication) {
+			merged.Publication = registry.Publication
+		}
+
+		if registry.Description != "" {
+			descriptions = append(descriptions, registry.Description)
+		}
+
+		for _, service := range registry.Services {
+			for _, entry := range service.Entries() {
+				key := strings.ToLower(entry)
+				a, ok := assignments[key]
+
+				switch {
+				case !ok:
+					assignments[key] = &assignment{entry: entry, uris: service.URIs()}
+					entries = append(entries, key)
+				case sameURIs(a.uris, service.URIs()):
+				case p == ConflictKeepLast:
+					a.uris = service.URIs()
+				case p == ConflictFail:
+					conflicts = append(conflicts, Conflict{Entry: entry, URIs: a.uris, Conflicting: service.URIs()})
+				}
+			}
+		}
+	}
+
+	merged.Description = strings.Join(descriptions, "; ")
+	services := map[string]int{}
+
+	for _, key := range entries {
+		a := assignments[key]
+		uris := append([]string(nil), a.uris...)
+		sort.Strings(uris)
+		i, ok := services[strings.Join(uris, " ")]
+
+		if !ok {
+			i = len(merged.Services)
+			services[strings.Join(uris, " ")] = i
+			merged.Services = append(merged.Services, Service{nil, append(Values(nil), a.uris...)})
+		}
+
+		merged.Services[i][0] = append(merged.Services[i][0], a.entry)
+	}
+
+	if len(conflicts) > 0 {
+		return merged, &ConflictError{Conflicts: conflicts}
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,90 @@
+package bootstrap
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMerge(t *testing.T) {
+	iana := ServiceRegistry{
+		Version:     "1.0",
+		Publication: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Description: "IANA",
+		Services: ServicesList{
+			{{"com", "net"}, {"https://rdap.verisign.com/com/v1/"}},
+			{{"org"}, {"https://rdap.publicinterestregistry.org/rdap/"}},
+		},
+	}
+	overlay := ServiceRegistry{
+		Publication: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		Services: ServicesList{
+			{{"corp", "ORG"}, {"https://rdap.corp.example/"}},
+			{{"NET"}, {"https://rdap.verisign.com/com/v1/"}},
+		},
+	}
+
+	tests := []struct {
+		description string
+		policy      ConflictPolicy
+		expected    ServicesList
+		conflicts   []Conflict
+	}{
+		{
+			description: "fail",
+			policy:      ConflictFail,
+			expected: ServicesList{
+				{{"com", "net"}, {"https://rdap.verisign.com/com/v1/"}},
+				{{"org"}, {"https://rdap.publicinterestregistry.org/rdap/"}},
+				{{"corp"}, {"https://rdap.corp.example/"}},
+			},
+			conflicts: []Conflict{
+				{Entry: "ORG", URIs: []string{"https://rdap.publicinterestregistry.org/rdap/"}, Conflicting: []string{"https://rdap.corp.example/"}},
+			},
+		},
+		{
+			description: "keep first",
+			policy:      ConflictKeepFirst,
+			expected: ServicesList{
+				{{"com", "net"}, {"https://rdap.verisign.com/com/v1/"}},
+				{{"org"}, {"https://rdap.publicinterestregistry.org/rdap/"}},
+				{{"corp"}, {"https://rdap.corp.example/"}},
+			},
+		},
+		{
+			description: "keep last",
+			policy:      ConflictKeepLast,
+			expected: ServicesList{
+				{{"com", "net"}, {"https://rdap.verisign.com/com/v1/"}},
+				{{"org", "corp"}, {"https://rdap.corp.example/"}},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		merged, err := test.policy.Merge(iana, overlay)
+
+		var conflictErr *ConflictError
+
+		if errors.As(err, &conflictErr) != (test.conflicts != nil) {
+			t.Fatalf("At index %d (%s): unexpected error %v", i, test.description, err)
+		}
+
+		if conflictErr != nil && !reflect.DeepEqual(test.conflicts, conflictErr.Conflicts) {
+			t.Fatalf("At index %d (%s): expected conflicts %v, got %v", i, test.description, test.conflicts, conflictErr.Conflicts)
+		}
+
+		if !reflect.DeepEqual(test.expected, merged.Services) {
+			t.Fatalf("At index %d (%s): expected %v, got %v", i, test.description, test.expected, merged.Services)
+		}
+
+		if !merged.Publication.Equal(overlay.Publication) || merged.Description != "IANA" || merged.Version != "1.0" {
+			t.Fatalf("At index %d (%s): unexpected registry %+v", i, test.description, merged)
+		}
+	}
+
+	if _, err := Merge(iana, iana); err != nil {
+		t.Fatalf("expected no conflict merging a registry with itself, got %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Kind names one of the bootstrap registries.
+type Kind string
+
+const (
+	DNS  Kind = "dns"
+	IPv4 Kind = "ipv4"
+	IPv6 Kind = "ipv6"
+	ASN  Kind = "asn"
+)
+
+// URL returns where IANA publishes the registry of kind k.
+func (k Kind) URL() string {
+	return "https://data.iana.org/rdap/" + string(k) + ".json"
+}
+
+// Registries fetches bootstrap registries on first use and keeps them in
+// memory, so that queries don't fetch a registry each. The zero value
+// fetches from IANA with http.DefaultClient.
+type Registries struct {
+	// Client fetches the registries.
+	Client *http.Client
+	// URLs overrides where a kind is fetched from; kinds not listed are
+	// fetched from IANA.
+	URLs map[Kind]string
+	// TTL is how long a fetched registry is used before it is refreshed.
+	// Zero keeps it for the lifetime of the Registries.
+	TTL time.Duration
+
+	mutex    sync.Mutex
+	loaded   map[Kind]loadedRegistry
+	fetching map[Kind]*registryFetch
+}
+
+type loadedRegistry struct {
+	registry ServiceRegistry
+	index    *Index
+	fetched  time.Time
+	pinned   bool
+
+	// err is the error of the last fetch, failures the number of fetches
+	// failed in a row and retry when the next may start.
+	err      error
+	failures int
+	retry    time.Time
+}
+
+// registryFetch is a fetch in flight, which the lookups of its kind that
+// have no registry to serve yet wait for.
+type registryFetch struct {
+	done   chan struct{}
+	loaded loadedRegistry
+}
+
+// Failed fetches are retried after retryBackoff, doubled with every
+// failure in a row up to maxRetryBackoff.
+var (
+	retryBackoff    = time.Second
+	maxRetryBackoff = 5 * time.Minute
+)
+
+// Set pins the registry used for kind, which is then never fetched.
+func (r *Registries) Set(kind Kind, registry ServiceRegistry) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.loaded == nil {
+		r.loaded = map[Kind]loadedRegistry{}
+	}
+
+	r.loaded[kind] = loadedRegistry{registry: registry, index: registry.Compile(), fetched: time.Now(), pinned: true}
+}
+
+// Get returns the registry for kind, fetching it when it hasn't been fetched
+// yet or is older than TTL. Only one fetch per kind is in flight at a time,
+// and lookups don't wait for it while there is a registry to serve: a
+// registry that fails to refresh keeps being served until a refresh
+// succeeds. Failed fetches are retried with an exponential backoff, and
+// until then a kind that was never fetched fails with the last error.
+func (r *Registries) Get(ctx context.Context, kind Kind) (ServiceRegistry, error) {
+	loaded, err := r.load(ctx, kind)
+
+	return loaded.registry, err
+}
+
+// load returns the registry for kind along with its index, as Get does.
+func (r *Registries) load(ctx context.Context, kind Kind) (loadedRegistry, error) {
+	r.mutex.Lock()
+
+	loaded, ok := r.loaded[kind]
+	usable := ok && loaded.index != nil
+
+	if usable && (loaded.pinned || r.TTL == 0 || time.Since(loaded.fetched) < r.TTL) {
+		r.mutex.Unlock()
+		return loaded, nil
+	}
+
+	if ok && time.Now().Before(loaded.retry) {
+		r.mutex.Unlock()
+		return loaded, loaded.failed(kind)
+	}
+
+	fetch, fetching := r.fetching[kind]
+
+	if !fetching {
+		if r.fetching == nil {
+			r.fetching = map[Kind]*registryFetch{}
+		}
+
+		fetch = &registryFetch{done: make(chan struct{})}
+		r.fetching[kind] = fetch
+	}
+
+	r.mutex.Unlock()
+
+	if fetching {
+		if usable {
+			return loaded, nil
+		}
+
+		select {
+		case <-fetch.done:
+			return fetch.loaded, fetch.loaded.failed(kind)
+		case <-ctx.Done():
+			return loadedRegistry{}, ctx.Err()
+		}
+	}
+
+	fetch.loaded = r.fetch(ctx, kind, loaded)
+	close(fetch.done)
+
+	return fetch.loaded, fetch.loaded.failed(kind)
+}
+
+// fetch refreshes loaded, the registry held for kind, and records the
+// outcome.
+func (r *Registries) fetch(ctx context.Context, kind Kind, loaded loadedRegistry) loadedRegistry {
+	url := r.URLs[kind]
+
+	if url == "" {
+		url = kind.URL()
+	}
+
+	registry, _, err := RefreshServiceRegistry(ctx, r.Client, url, loaded.registry)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.fetching, kind)
+
+	switch {
+	case err == nil:
+		loaded = loadedRegistry{registry: registry, index: registry.Compile(), fetched: time.Now()}
+	case ctx.Err() != nil:
+		// The caller gave up, which says nothing about the server.
+		loaded.err = err
+		return loaded
+	default:
+		backoff := maxRetryBackoff
+
+		if loaded.failures < 16 && retryBackoff<<loaded.failures < maxRetryBackoff {
+			backoff = retryBackoff << loaded.failures
+		}
+
+		loaded.err, loaded.failures, loaded.retry = err, loaded.failures+1, time.Now().Add(backoff)
+	}
+
+	if r.loaded == nil {
+		r.loaded = map[Kind]loadedRegistry{}
+	}
+
+	r.loaded[kind] = loaded
+
+	return loaded
+}
+
+// failed returns the error to report for kind: none while there is a
+// registry to serve, and the error of the last fetch otherwise.
+func (l loadedRegistry) failed(kind Kind) error {
+	if l.index != nil || l.err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("bootstrap %s: %w", kind, l.err)
+}
+
+// Domain returns the base URLs of the servers responsible for fqdn.
+func (r *Registries) Domain(ctx context.Context, fqdn string) ([]string, error) {
+	loaded, err := r.load(ctx, DNS)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return loaded.index.MatchDomain(fqdn)
+}
+
+// IPNetwork returns the base URLs of the servers responsible for network,
+// consulting the IPv4 or IPv6 registry as appropriate.
+func (r *Registries) IPNetwork(ctx context.Context, network *net.IPNet) ([]string, error) {
+	kind := IPv6
+
+	if network.IP.To4() != nil {
+		kind = IPv4
+	}
+
+	loaded, err := r.load(ctx, kind)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return loaded.index.MatchIPNetwork(network)
+}
+
+// Autnum returns the base URLs of the servers responsible for asn.
+func (r *Registries) Autnum(ctx context.Context, asn uint32) ([]string, error) {
+	loaded, err := r.load(ctx, ASN)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return loaded.index.MatchAS(asn)
+}
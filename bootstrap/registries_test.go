@@ -0,0 +1,140 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistries(t *testing.T) {
+	fetches := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+
+		if fetches > 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte(`{"version": "1.0", "publication": "2024-01-0` + string(rune('0'+fetches)) + `T00:00:00Z", "services": [[["com"], ["https://rdap.example.com/` + string(rune('0'+fetches)) + `/"]]]}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	registries := &Registries{
+		Client: server.Client(),
+		URLs:   map[Kind]string{DNS: server.URL},
+		TTL:    10 * time.Millisecond,
+	}
+
+	registries.Set(ASN, ServiceRegistry{Services: ServicesList{{{"1-10"}, {"https://rdap.example.net/"}}}})
+
+	expect := func(description string, uris []string, err error, expected ...string) {
+		t.Helper()
+
+		if err != nil || !reflect.DeepEqual(expected, uris) {
+			t.Fatalf("%s: expected %v, got %v (%v)", description, expected, uris, err)
+		}
+	}
+
+	uris, err := registries.Domain(ctx, "example.com")
+	expect("it should fetch on first use", uris, err, "https://rdap.example.com/1/")
+
+	uris, err = registries.Domain(ctx, "example.com")
+	expect("it should reuse a fresh registry", uris, err, "https://rdap.example.com/1/")
+
+	time.Sleep(20 * time.Millisecond)
+	uris, err = registries.Domain(ctx, "example.com")
+	expect("it should refresh a stale registry", uris, err, "https://rdap.example.com/2/")
+
+	time.Sleep(20 * time.Millisecond)
+	uris, err = registries.Domain(ctx, "example.com")
+	expect("it should keep serving a registry that fails to refresh", uris, err, "https://rdap.example.com/2/")
+
+	uris, err = registries.Domain(ctx, "example.com")
+	expect("it should back off after a failed refresh", uris, err, "https://rdap.example.com/2/")
+
+	uris, err = registries.Autnum(ctx, 5)
+	expect("it should serve pinned registries", uris, err, "https://rdap.example.net/")
+
+	if fetches != 3 {
+		t.Fatalf("expected 3 fetches, got %d", fetches)
+	}
+}
+
+func TestRegistriesFetchOnce(t *testing.T) {
+	var (
+		mutex   sync.Mutex
+		fetches = map[string]int{}
+		release = make(chan struct{})
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		fetches[r.URL.Path]++
+		mutex.Unlock()
+
+		switch r.URL.Path {
+		case "/dns.json":
+			<-release
+			w.Write([]byte(`{"version": "1.0", "services": [[["com"], ["https://rdap.example.com/"]]]}`))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	registries := &Registries{
+		Client: server.Client(),
+		URLs:   map[Kind]string{DNS: server.URL + "/dns.json", ASN: server.URL + "/asn.json"},
+	}
+
+	ctx := context.Background()
+	errs := make(chan error, 3)
+
+	for i := 0; i < 3; i++ {
+		go func() {
+			_, err := registries.Domain(ctx, "example.com")
+			errs <- err
+		}()
+	}
+
+	for started := false; !started; time.Sleep(time.Millisecond) {
+		mutex.Lock()
+		started = fetches["/dns.json"] == 1
+		mutex.Unlock()
+	}
+
+	// The other kinds don't wait behind the dns fetch, and a failed fetch
+	// isn't retried until its backoff has passed.
+	for i := 0; i < 2; i++ {
+		if _, err := registries.Autnum(ctx, 64496); err == nil || err.Error() != "bootstrap asn: unexpected status 503 fetching "+server.URL+"/asn.json" {
+			t.Fatalf("expected the failed fetch to be reported, got %v", err)
+		}
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	if _, err := registries.Domain(canceled, "example.com"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a lookup to stop waiting with its context, got %v", err)
+	}
+
+	close(release)
+
+	for i := 0; i < 3; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if fetches["/dns.json"] != 1 || fetches["/asn.json"] != 1 {
+		t.Fatalf("expected one fetch per kind, got %v", fetches)
+	}
+}
@@ -0,0 +1,184 @@
+// Package bootstrap reads the RFC 7484 bootstrap registries published by
+// IANA and finds the RDAP servers responsible for a domain, address block or
+// autonomous system number.
+//
+// The exported API follows semantic versioning: a minor release may add
+// functions and fields but does not change what the Match methods return
+// for a registry. Changes to matching rules, such as which of two covering
+// entries wins, are breaking, and happen only in a new major version or as
+// bug fixes recorded in the release notes.
+package bootstrap
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ServiceRegistry struct {
+	Version     string       `json:"version"`
+	Publication time.Time    `json:"publication"`
+	Description string       `json:"description,omitempty"`
+	Services    ServicesList `json:"services"`
+}
+
+// MarshalJSON encodes a registry in the RFC 7484 layout, so that it can be
+// published as a bootstrap file. A registry without a version is given
+// "1.0", the only version defined.
+func (s ServiceRegistry) MarshalJSON() ([]byte, error) {
+	type serviceRegistry ServiceRegistry
+
+	if s.Version == "" {
+		s.Version = "1.0"
+	}
+
+	return json.Marshal(serviceRegistry(s))
+}
+
+// Add makes uris the servers for entry, adding it to the service with the
+// same URIs, or to a new service at the end when there is none, after
+// removing it from any other service.
+func (s *ServiceRegistry) Add(entry string, uris ...string) {
+	services := s.Services.without(entry)
+
+	for i, service := range services {
+		if sameURIs(service.URIs(), uris) {
+			services[i][0] = append(service.Entries(), entry)
+			s.Services = services
+
+			return
+		}
+	}
+
+	s.Services = append(services, Service{{entry}, append(Values(nil), uris...)})
+}
+
+// Remove removes entry, along with any service it leaves without entries,
+// and reports whether it was found. Domain entries are compared regardless
+// of case.
+func (s *ServiceRegistry) Remove(entry string) bool {
+	services := s.Services.without(entry)
+
+	if services.entries() == s.Services.entries() {
+		return false
+	}
+
+	s.Services = services
+
+	return true
+}
+
+type ServicesList []Service
+
+// MarshalJSON encodes the services as an array, empty rather than null when
+// there are none.
+func (l ServicesList) MarshalJSON() ([]byte, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal([]Service(l))
+}
+
+// without returns a copy of l without entry.
+func (l ServicesList) without(entry string) ServicesList {
+	services := make(ServicesList, 0, len(l)+1)
+
+	for _, service := range l {
+		var entries Values
+
+		for _, e := range service.Entries() {
+			if !strings.EqualFold(e, entry) {
+				entries = append(entries, e)
+			}
+		}
+
+		if len(entries) > 0 {
+			services = append(services, Service{entries, append(Values(nil), service.URIs()...)})
+		}
+	}
+
+	return services
+}
+
+func (l ServicesList) entries() int {
+	var n int
+
+	for _, service := range l {
+		n += len(service.Entries())
+	}
+
+	return n
+}
+
+func sameURIs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	seen := map[string]bool{}
+
+	for _, uri := range a {
+		seen[uri] = true
+	}
+
+	for _, uri := range b {
+		if !seen[uri] {
+			return false
+		}
+	}
+
+	return true
+}
+
+type Service [2]Values
+
+type Values []string
+
+func (s Service) Entries() []string {
+	return s[0]
+}
+
+func (s Service) URIs() []string {
+	return s[1]
+}
+
+func (s *Service) UnmarshalJSON(b []byte) error {
+	sv := [2]Values(Service{})
+
+	if err := json.Unmarshal(b, &sv); err != nil {
+		return err
+	}
+
+	sort.Sort(sv[1])
+	*s = sv
+
+	return nil
+}
+
+// MarshalJSON encodes a service as its pair of entries and URIs, empty
+// arrays standing in for missing ones.
+func (s Service) MarshalJSON() ([]byte, error) {
+	sv := [2]Values{s[0], s[1]}
+
+	for i := range sv {
+		if sv[i] == nil {
+			sv[i] = Values{}
+		}
+	}
+
+	return json.Marshal(sv)
+}
+
+func (v Values) Len() int {
+	return len(v)
+}
+
+func (v Values) Swap(i, j int) {
+	v[i], v[j] = v[j], v[i]
+}
+
+func (v Values) Less(i, j int) bool {
+	return strings.Split(v[i], ":")[0] == "https"
+}
@@ -0,0 +1,576 @@
+package bootstrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+
+	"testing"
+)
+
+var jsonExample = []byte(`{
+       "version": "1.0",
+       "publication": "2015-04-17T16:00:00Z",
+       "description": "Some text",
+       "services": [
+         [
+           ["entry1", "entry2", "entry3"],
+           [
+             "https://registry.example.com/myrdap/",
+             "http://registry.example.com/myrdap/"
+           ]
+         ],
+         [
+           ["entry4"],
+           [
+             "http://example.org/"
+           ]
+         ]
+       ]
+   }`)
+
+func TestConformity(t *testing.T) {
+	if err := json.Unmarshal(jsonExample, &ServiceRegistry{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// matcher is what ServiceRegistry and Index have in common, so that tests
+// can check the scan and the index agree.
+type matcher interface {
+	MatchAS(asn uint32) ([]string, error)
+	MatchIPNetwork(network *net.IPNet) ([]string, error)
+	MatchDomain(fqdn string) ([]string, error)
+}
+
+func TestMatchSeesEdits(t *testing.T) {
+	var registry ServiceRegistry
+
+	if err := json.Unmarshal(jsonExample, &registry); err != nil {
+		t.Fatal(err)
+	}
+
+	index := registry.Compile()
+
+	registry.Services[1] = Service{{"entry4"}, {"https://new.example/"}}
+	registry.Services[0][0] = Values{"entry1"}
+
+	if urls, _ := registry.MatchDomain("entry4"); !reflect.DeepEqual([]string{"https://new.example/"}, urls) {
+		t.Fatalf("expected the edited service's URIs, got %v", urls)
+	}
+
+	if urls, _ := registry.MatchDomain("entry2"); urls != nil {
+		t.Fatalf("expected the removed entry to match nothing, got %v", urls)
+	}
+
+	if urls, _ := index.MatchDomain("entry4"); !reflect.DeepEqual([]string{"http://example.org/"}, urls) {
+		t.Fatalf("expected the index to keep the compiled URIs, got %v", urls)
+	}
+
+	if urls, _ := index.MatchDomain("entry2"); !reflect.DeepEqual([]string{"https://registry.example.com/myrdap/", "http://registry.example.com/myrdap/"}, urls) {
+		t.Fatalf("expected the index to keep the compiled entries, got %v", urls)
+	}
+}
+
+func TestMatchAS(t *testing.T) {
+	tests := []struct {
+		description   string
+		registry      ServiceRegistry
+		as            uint32
+		expected      []string
+		expectedError error
+	}{
+		{
+			description: "it should match an as number",
+			as:          65411,
+			registry: ServiceRegistry{
+				Services: ServicesList{
+					{
+						{"2045-2045"},
+						{"https://rir3.example.com/myrdap/"},
+					},
+					{
+						{"10000-12000", "300000-400000"},
+						{"http://example.org/"},
+					},
+					{
+						{"64512-65534"},
+						{"http://example.net/rdaprir2/", "https://example.net/rdaprir2/"},
+					},
+				},
+			},
+			expected: []string{"http://example.net/rdaprir2/", "https://example.net/rdaprir2/"},
+		},
+		{
+			description: "it should return no servers for an as number outside every range",
+			as:          4200000000,
+			registry: ServiceRegistry{
+				Services: ServicesList{
+					{
+						{"64512-65534"},
+						{"http://example.net/rdaprir2/"},
+					},
+				},
+			},
+		},
+		{
+			description: "it should not match an as number due to invalid beginning of as range",
+			as:          1,
+			registry: ServiceRegistry{
+				Services: ServicesList{
+					{
+						{"invalid-123"},
+						{},
+					},
+				},
+			},
+			expectedError: fmt.Errorf("strconv.ParseInt: parsing \"invalid\": invalid syntax"),
+		},
+		{
+			description: "it should not match an as number due to invalid end of as range",
+			as:          1,
+			registry: ServiceRegistry{
+				Services: ServicesList{
+					{
+						{"123-invalid"},
+						{},
+					},
+				},
+			},
+			expectedError: fmt.Errorf("strconv.ParseInt: parsing \"invalid\": invalid syntax"),
+		},
+	}
+
+	for i, test := range tests {
+		for n, registry := range []matcher{test.registry, test.registry.Compile()} {
+			urls, err := registry.MatchAS(test.as)
+
+			if test.expectedError != nil && fmt.Sprintf("%v", test.expectedError) != fmt.Sprintf("%v", err) {
+				t.Fatalf("At index %d (%s, indexed %t): expected error %s, got %s", i, test.description, n == 1, test.expectedError, err)
+			}
+
+			if !reflect.DeepEqual(test.expected, urls) {
+				t.Fatalf("At index %d (%s, indexed %t): expected %v, got %v", i, test.description, n == 1, test.expected, urls)
+			}
+		}
+	}
+}
+
+func TestMatchIPNetwork(t *testing.T) {
+	tests := []struct {
+		description   string
+		registry      ServiceRegistry
+		ipnet         string
+		expected      []string
+		expectedError error
+	}{
+		{
+			description: "it should match an ipv6 network",
+			ipnet:       "2001:0200:1000::/48",
+			registry: ServiceRegistry{
+				Services: ServicesList{
+					{
+						{"2001:0200::/23", "2001:db8::/32"},
+						{"https://rir2.example.com/myrdap/"},
+					},
+					{
+						{"2600::/16", "2100:ffff::/32"},
+						{"http://example.org/"},
+					},
+					{
+						{"2001:0200:1000::/36"},
+						{"https://example.net/rdaprir2/", "http://example.net/rdaprir2/"},
+					},
+				},
+			},
+			expected: []string{
+				"https://example.net/rdaprir2/",
+				"http://example.net/rdaprir2/",
+			},
+		},
+		{
+			description: "it should match an ipv4 network",
+			ipnet:       "192.0.2.1/25",
+			registry: ServiceRegistry{
+				Services: ServicesList{
+					{
+						{"1.0.0.0/8", "192.0.0.0/8"},
+						{"https://rir1.example.com/myrdap/"},
+					},
+					{
+						{"28.2.0.0/16", "192.0.2.0/24"},
+						{"http://example.org/"},
+					},
+					{
+						{"28.3.0.0/16"},
+						{"https://example.net/rdaprir2/", "http://example.net/rdaprir2/"},
+					},
+				},
+			},
+			expected: []string{
+				"http://example.org/",
+			},
+		},
+		{
+			description: "it should match a single address to its covering entry",
+			ipnet:       "192.0.2.1/32",
+			registry: ServiceRegistry{
+				Services: ServicesList{
+					{{"192.0.0.0/8"}, {"https://rir1.example.com/myrdap/"}},
+					{{"192.0.2.0/24"}, {"http://example.org/"}},
+				},
+			},
+			expected: []string{"http://example.org/"},
+		},
+		{
+			description: "it should not match entries narrower than the network",
+			ipnet:       "192.0.0.0/16",
+			registry: ServiceRegistry{
+				Services: ServicesList{
+					{{"192.0.0.0/8"}, {"https://rir1.example.com/myrdap/"}},
+					{{"192.0.2.0/24"}, {"http://example.org/"}},
+				},
+			},
+			expected: []string{"https://rir1.example.com/myrdap/"},
+		},
+		{
+			description: "it should prefer the most specific entry whatever its order",
+			ipnet:       "192.0.2.128/25",
+			registry: ServiceRegistry{
+				Services: ServicesList{
+					{{"192.0.2.0/24"}, {"http://example.org/"}},
+					{{"0.0.0.0/0"}, {"https://all.example.com/"}},
+					{{"192.0.0.0/8"}, {"https://rir1.example.com/myrdap/"}},
+				},
+			},
+			expected: []string{"http://example.org/"},
+		},
+		{
+			description: "it should not match entries of the other address family",
+			ipnet:       "::ffff:192.0.2.1/128",
+			registry: ServiceRegistry{
+				Services: ServicesList{
+					{{"192.0.2.0/24"}, {"http://example.org/"}},
+				},
+			},
+		},
+		{
+			description: "it should not match a network outside every entry",
+			ipnet:       "198.51.100.0/24",
+			registry: ServiceRegistry{
+				Services: ServicesList{
+					{{"192.0.2.0/24"}, {"http://example.org/"}},
+				},
+			},
+		},
+		{
+			description: "it should not match an ip network due to invalid cidr",
+			ipnet:       "127.0.0.1/32",
+			registry: ServiceRegistry{
+				Services: ServicesList{
+					{
+						{"invalid"},
+						{},
+					},
+				},
+			},
+			expectedError: fmt.Errorf("invalid CIDR address: invalid"),
+		},
+	}
+
+	for i, test := range tests {
+		_, ipnet, _ := net.ParseCIDR(test.ipnet)
+		for n, registry := range []matcher{test.registry, test.registry.Compile()} {
+			urls, err := registry.MatchIPNetwork(ipnet)
+
+			if test.expectedError != nil && fmt.Sprintf("%v", test.expectedError) != fmt.Sprintf("%v", err) {
+				t.Fatalf("At index %d (%s, indexed %t): expected error %s, got %s", i, test.description, n == 1, test.expectedError, err)
+			}
+
+			if !reflect.DeepEqual(test.expected, urls) {
+				t.Fatalf("At index %d (%s, indexed %t): expected %v, got %v", i, test.description, n == 1, test.expected, urls)
+			}
+		}
+	}
+}
+
+func TestMatchDomain(t *testing.T) {
+	tests := []struct {
+		description   string
+		registry      ServiceRegistry
+		fqdn          string
+		expected      []string
+		expectedError error
+	}{
+		{
+			description: "it should match a fqdn",
+			fqdn:        "a.b.example.com",
+			registry: ServiceRegistry{
+				Services: ServicesList{
+					{
+						{"net", "com"},
+						{"https://registry.example.com/myrdap/"},
+					},
+					{
+						{"org", "mytld"},
+						{"http://example.org/"},
+					},
+					{
+						{"xn--zckzah"},
+						{"https://example.net/rdapxn--zckzah/", "http://example.net/rdapxn--zckzah/"},
+					},
+				},
+			},
+			expected: []string{
+				"https://registry.example.com/myrdap/",
+			},
+		},
+		{
+			description: "it should match an absolute name in upper case",
+			fqdn:        "A.B.EXAMPLE.COM.",
+			registry: ServiceRegistry{
+				Services: ServicesList{
+					{
+						{"net", "com"},
+						{"https://registry.example.com/myrdap/"},
+					},
+				},
+			},
+			expected: []string{
+				"https://registry.example.com/myrdap/",
+			},
+		},
+		{
+			description: "it should match an idn given as a u-label",
+			fqdn:        "テスト",
+			registry: ServiceRegistry{
+				Services: ServicesList{
+					{
+						{"net", "com"},
+						{"https://registry.example.com/myrdap/"},
+					},
+					{
+						{"xn--zckzah"},
+						{"https://example.net/rdapxn--zckzah/", "http://example.net/rdapxn--zckzah/"},
+					},
+				},
+			},
+			expected: []string{
+				"https://example.net/rdapxn--zckzah/",
+				"http://example.net/rdapxn--zckzah/",
+			},
+		},
+		{
+			description: "it should prefer the longest matching entry",
+			fqdn:        "example.co.uk",
+			registry: ServiceRegistry{
+				Services: ServicesList{
+					{
+						{"uk"},
+						{"https://rdap.nominet.uk/uk/"},
+					},
+					{
+						{"co.uk"},
+						{"https://rdap.example.net/co.uk/"},
+					},
+				},
+			},
+			expected: []string{
+				"https://rdap.example.net/co.uk/",
+			},
+		},
+		{
+			description: "it should not match a domain no registry serves",
+			fqdn:        "example.invalid",
+			registry: ServiceRegistry{
+				Services: ServicesList{
+					{
+						{"net", "com"},
+						{"https://registry.example.com/myrdap/"},
+					},
+				},
+			},
+		},
+		{
+			description: "it should match a tld against its own entry",
+			fqdn:        ".dev",
+			registry: ServiceRegistry{
+				Services: ServicesList{
+					{
+						{"dev"},
+						{"https://registry.example.com/myrdap/"},
+					},
+				},
+			},
+			expected: []string{
+				"https://registry.example.com/myrdap/",
+			},
+		},
+	}
+
+	for i, test := range tests {
+		for n, registry := range []matcher{test.registry, test.registry.Compile()} {
+			urls, err := registry.MatchDomain(test.fqdn)
+
+			if test.expectedError != nil && fmt.Sprintf("%v", test.expectedError) != fmt.Sprintf("%v", err) {
+				t.Fatalf("At index %d (%s, indexed %t): expected error %s, got %s", i, test.description, n == 1, test.expectedError, err)
+			}
+
+			if !reflect.DeepEqual(test.expected, urls) {
+				t.Fatalf("At index %d (%s, indexed %t): expected %v, got %v", i, test.description, n == 1, test.expected, urls)
+			}
+		}
+	}
+}
+
+func TestIndexAgreesWithScan(t *testing.T) {
+	asns := ServiceRegistry{
+		Services: ServicesList{
+			{{"0-4294967295"}, {"https://all.example/"}},
+			{{"100-200", "150-160"}, {"https://a.example/"}},
+			{{"140-170", "150-160"}, {"https://b.example/"}},
+			{{"155"}, {"https://c.example/"}},
+			{{"300-250"}, {"https://reversed.example/"}},
+		},
+	}
+
+	for asn := uint32(0); asn < 400; asn++ {
+		expected, _ := asns.MatchAS(asn)
+		urls, _ := asns.Compile().MatchAS(asn)
+
+		if !reflect.DeepEqual(expected, urls) {
+			t.Fatalf("AS%d: expected %v, got %v", asn, expected, urls)
+		}
+	}
+
+	networks := ServiceRegistry{
+		Services: ServicesList{
+			{{"10.0.0.0/8", "2001:db8::/32"}, {"https://a.example/"}},
+			{{"10.1.0.0/16", "2001:db8:1::/48"}, {"https://b.example/"}},
+			{{"10.1.0.0/16", "10.1.2.0/24"}, {"https://c.example/"}},
+			{{"0.0.0.0/0"}, {"https://all.example/"}},
+		},
+	}
+
+	for _, cidr := range []string{"10.1.2.3/32", "10.1.2.0/23", "10.2.0.0/16", "8.0.0.0/6", "192.0.2.0/24", "2001:db8:1::1/128", "2001:db8:2::/48", "2001:db9::/32", "::ffff:10.1.2.3/128"} {
+		_, network, _ := net.ParseCIDR(cidr)
+		expected, _ := networks.MatchIPNetwork(network)
+		urls, _ := networks.Compile().MatchIPNetwork(network)
+
+		if !reflect.DeepEqual(expected, urls) {
+			t.Fatalf("%s: expected %v, got %v", cidr, expected, urls)
+		}
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	var registry ServiceRegistry
+
+	if err := json.Unmarshal(jsonExample, &registry); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(registry)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected bytes.Buffer
+
+	if err := json.Compact(&expected, jsonExample); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected.String() != string(b) {
+		t.Fatalf("expected %s, got %s", expected.String(), b)
+	}
+
+	b, _ = json.Marshal(ServiceRegistry{Services: ServicesList{{{"example"}}}})
+
+	if expected := `{"version":"1.0","publication":"0001-01-01T00:00:00Z","services":[[["example"],[]]]}`; expected != string(b) {
+		t.Fatalf("expected %s, got %s", expected, b)
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	tests := []struct {
+		description string
+		edit        func(*ServiceRegistry) bool
+		expected    ServicesList
+		entry4      []string
+	}{
+		{
+			description: "add to the service with the same URIs",
+			edit: func(s *ServiceRegistry) bool {
+				s.Add("entry5", "http://example.org/")
+				return true
+			},
+			expected: ServicesList{
+				{{"entry1", "entry2", "entry3"}, {"https://registry.example.com/myrdap/", "http://registry.example.com/myrdap/"}},
+				{{"entry4", "entry5"}, {"http://example.org/"}},
+			},
+			entry4: []string{"http://example.org/"},
+		},
+		{
+			description: "add moves an entry to a new service",
+			edit: func(s *ServiceRegistry) bool {
+				s.Add("entry4", "https://new.example/")
+				return true
+			},
+			expected: ServicesList{
+				{{"entry1", "entry2", "entry3"}, {"https://registry.example.com/myrdap/", "http://registry.example.com/myrdap/"}},
+				{{"entry4"}, {"https://new.example/"}},
+			},
+			entry4: []string{"https://new.example/"},
+		},
+		{
+			description: "remove drops emptied services",
+			edit: func(s *ServiceRegistry) bool {
+				return s.Remove("ENTRY4")
+			},
+			expected: ServicesList{
+				{{"entry1", "entry2", "entry3"}, {"https://registry.example.com/myrdap/", "http://registry.example.com/myrdap/"}},
+			},
+		},
+		{
+			description: "remove a missing entry",
+			edit: func(s *ServiceRegistry) bool {
+				return !s.Remove("entry6")
+			},
+			expected: ServicesList{
+				{{"entry1", "entry2", "entry3"}, {"https://registry.example.com/myrdap/", "http://registry.example.com/myrdap/"}},
+				{{"entry4"}, {"http://example.org/"}},
+			},
+			entry4: []string{"http://example.org/"},
+		},
+	}
+
+	for i, test := range tests {
+		var registry ServiceRegistry
+
+		if err := json.Unmarshal(jsonExample, &registry); err != nil {
+			t.Fatal(err)
+		}
+
+		original := registry
+
+		if !test.edit(&registry) {
+			t.Fatalf("At index %d (%s): unexpected result", i, test.description)
+		}
+
+		if !reflect.DeepEqual(test.expected, registry.Services) {
+			t.Fatalf("At index %d (%s): expected %v, got %v", i, test.description, test.expected, registry.Services)
+		}
+
+		if urls, _ := registry.MatchDomain("entry4"); !reflect.DeepEqual(test.entry4, urls) {
+			t.Fatalf("At index %d (%s): expected %v, got %v", i, test.description, test.entry4, urls)
+		}
+
+		if urls, _ := original.MatchDomain("entry4"); !reflect.DeepEqual([]string{"http://example.org/"}, urls) {
+			t.Fatalf("At index %d (%s): the original registry changed, got %v", i, test.description, urls)
+		}
+	}
+}
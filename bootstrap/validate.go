@@ -0,0 +1,208 @@
+package bootstrap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/garslo/rdap-client/protocol"
+)
+
+// Problem is a malformed part of a registry, at Path such as
+// "services[2][0][1]" for the second entry of the third service.
+type Problem struct {
+	Path  string
+	Value string
+	Err   error
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s %q: %v", p.Path, p.Value, p.Err)
+}
+
+// ValidationError is the error Validate and ValidateJSON return, listing every problem it
+// found.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Problems))
+
+	for i, problem := range e.Problems {
+		problems[i] = problem.String()
+	}
+
+	return "bootstrap: invalid registry: " + strings.Join(problems, "; ")
+}
+
+// Validate checks s as a registry of kind against RFC 7484: its version,
+// its publication time, the entries the Match methods of kind parse and
+// the base URLs of every service. It reports every problem found in a
+// *ValidationError, rather than the first malformed entry a lookup meets.
+// Entries of an unknown kind are only checked for duplicates.
+func (s ServiceRegistry) Validate(kind Kind) error {
+	var publication *Problem
+
+	if s.Publication.IsZero() {
+		publication = &Problem{Path: "publication", Err: errMissingPublication}
+	}
+
+	return s.validate(kind, publication)
+}
+
+var errMissingPublication = errors.New("missing publication time")
+
+// ValidateJSON checks the registry document b as Validate does. A
+// publication time that is not an RFC 3339 string, on which decoding b
+// into a ServiceRegistry would fail, is reported as a problem like the
+// others; only b that is not JSON, or whose other members have the wrong
+// types, fails with an error of its own.
+func ValidateJSON(kind Kind, b []byte) error {
+	var document struct {
+		Version     string          `json:"version"`
+		Publication json.RawMessage `json:"publication"`
+		Description string          `json:"description"`
+		Services    ServicesList    `json:"services"`
+	}
+
+	if err := json.Unmarshal(b, &document); err != nil {
+		return err
+	}
+
+	var (
+		s           = ServiceRegistry{Version: document.Version, Description: document.Description, Services: document.Services}
+		publication *Problem
+		value       string
+	)
+
+	switch {
+	case len(document.Publication) == 0 || string(document.Publication) == "null":
+		publication = &Problem{Path: "publication", Err: errMissingPublication}
+	case json.Unmarshal(document.Publication, &value) != nil:
+		publication = &Problem{Path: "publication", Value: string(document.Publication), Err: errors.New("not a string")}
+	default:
+		var err error
+
+		if s.Publication, err = time.Parse(time.RFC3339, value); err != nil {
+			publication = &Problem{Path: "publication", Value: value, Err: errors.New("not an RFC 3339 time")}
+		}
+	}
+
+	return s.validate(kind, publication)
+}
+
+// validate checks s, reporting publication, when set, after the version.
+func (s ServiceRegistry) validate(kind Kind, publication *Problem) error {
+	var (
+		problems []Problem
+		seen     = map[string]string{}
+	)
+
+	add := func(path, value string, err error) {
+		problems = append(problems, Problem{Path: path, Value: value, Err: err})
+	}
+
+	if s.Version != "1.0" {
+		add("version", s.Version, errors.New(`expected "1.0"`))
+	}
+
+	if publication != nil {
+		problems = append(problems, *publication)
+	}
+
+	for i, service := range s.Services {
+		for j, entry := range service.Entries() {
+			path := fmt.Sprintf("services[%d][0][%d]", i, j)
+
+			if err := validateEntry(kind, entry); err != nil {
+				add(path, entry, err)
+			}
+
+			if first, ok := seen[strings.ToLower(entry)]; ok {
+				add(path, entry, fmt.Errorf("duplicate of %s", first))
+			} else {
+				seen[strings.ToLower(entry)] = path
+			}
+		}
+
+		if len(service.URIs()) == 0 {
+			add(fmt.Sprintf("services[%d][1]", i), "", errors.New("no base URLs"))
+		}
+
+		for j, uri := range service.URIs() {
+			if err := validateURI(uri); err != nil {
+				add(fmt.Sprintf("services[%d][1][%d]", i, j), uri, err)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+func validateEntry(kind Kind, entry string) error {
+	switch kind {
+	case DNS:
+		if entry == "" || strings.HasPrefix(entry, ".") || strings.HasSuffix(entry, ".") {
+			return errors.New("not a domain name")
+		}
+
+		_, err := protocol.ToASCII(entry)
+
+		return err
+	case IPv4, IPv6:
+		ip, ipnet, err := net.ParseCIDR(entry)
+
+		if err != nil {
+			return err
+		}
+
+		if _, bits := ipnet.Mask.Size(); (bits == 8*net.IPv4len) != (kind == IPv4) {
+			return fmt.Errorf("not an %s prefix", kind)
+		}
+
+		if !ip.Equal(ipnet.IP) {
+			return fmt.Errorf("not a network address, expected %s", ipnet)
+		}
+	case ASN:
+		begin, end, err := parseASRange(entry)
+
+		if err != nil {
+			return err
+		}
+
+		if end < begin {
+			return errors.New("range ends before it begins")
+		}
+	}
+
+	return nil
+}
+
+// validateURI checks a base URL, which RFC 7484 requires to be an http or
+// https URL ending in "/".
+func validateURI(uri string) error {
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return err
+	}
+
+	if !u.IsAbs() || u.Host == "" || u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("not an absolute http or https URL")
+	}
+
+	if !strings.HasSuffix(u.Path, "/") {
+		return errors.New(`missing trailing "/"`)
+	}
+
+	return nil
+}
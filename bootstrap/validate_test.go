@@ -0,0 +1,151 @@
+package bootstrap
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		description string
+		kind        Kind
+		registry    ServiceRegistry
+		expected    []string
+	}{
+		{
+			description: "valid",
+			kind:        IPv4,
+			registry: ServiceRegistry{
+				Version:     "1.0",
+				Publication: time.Now(),
+				Services:    ServicesList{{{"192.0.2.0/24"}, {"https://rdap.example/"}}},
+			},
+		},
+		{
+			description: "header",
+			kind:        DNS,
+			registry: ServiceRegistry{
+				Version:  "2.0",
+				Services: ServicesList{{{"example"}, {"https://rdap.example/"}}},
+			},
+			expected: []string{
+				`version "2.0": expected "1.0"`,
+				`publication "": missing publication time`,
+			},
+		},
+		{
+			description: "prefixes",
+			kind:        IPv4,
+			registry: ServiceRegistry{
+				Version:     "1.0",
+				Publication: time.Now(),
+				Services: ServicesList{
+					{{"192.0.2.0/24", "192.0.2.1/24", "2001:db8::/32", "invalid"}, {"https://rdap.example/"}},
+					{{"192.0.2.0/24"}, {"rdap.example", "ftp://rdap.example/", "https://rdap.example/v1"}},
+					{{"198.51.100.0/24"}, {}},
+				},
+			},
+			expected: []string{
+				`services[0][0][1] "192.0.2.1/24": not a network address, expected 192.0.2.0/24`,
+				`services[0][0][2] "2001:db8::/32": not an ipv4 prefix`,
+				`services[0][0][3] "invalid": invalid CIDR address: invalid`,
+				`services[1][0][0] "192.0.2.0/24": duplicate of services[0][0][0]`,
+				`services[1][1][0] "rdap.example": not an absolute http or https URL`,
+				`services[1][1][1] "ftp://rdap.example/": not an absolute http or https URL`,
+				`services[1][1][2] "https://rdap.example/v1": missing trailing "/"`,
+				`services[2][1] "": no base URLs`,
+			},
+		},
+		{
+			description: "ranges",
+			kind:        ASN,
+			registry: ServiceRegistry{
+				Version:     "1.0",
+				Publication: time.Now(),
+				Services:    ServicesList{{{"64496-64511", "65000-64000", "1-x"}, {"https://rdap.example/"}}},
+			},
+			expected: []string{
+				`services[0][0][1] "65000-64000": range ends before it begins`,
+				`services[0][0][2] "1-x": strconv.ParseInt: parsing "x": invalid syntax`,
+			},
+		},
+	}
+
+	for i, test := range tests {
+		err := test.registry.Validate(test.kind)
+
+		var problems []string
+		var validationErr *ValidationError
+
+		if errors.As(err, &validationErr) {
+			for _, problem := range validationErr.Problems {
+				problems = append(problems, problem.String())
+			}
+		} else if err != nil {
+			t.Fatalf("At index %d (%s): unexpected error %v", i, test.description, err)
+		}
+
+		if !reflect.DeepEqual(test.expected, problems) {
+			t.Fatalf("At index %d (%s): expected %q, got %q", i, test.description, test.expected, problems)
+		}
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	tests := []struct {
+		description string
+		json        string
+		expected    []string
+		err         bool
+	}{
+		{
+			description: "valid",
+			json:        `{"version": "1.0", "publication": "2024-01-02T03:04:05Z", "services": [[["example"], ["https://rdap.example/"]]]}`,
+		},
+		{
+			description: "missing publication",
+			json:        `{"version": "1.0", "services": []}`,
+			expected:    []string{`publication "": missing publication time`},
+		},
+		{
+			description: "malformed publication, reported with the other problems",
+			json:        `{"version": "1", "publication": "2024-01-02 03:04", "services": [[["example."], ["https://rdap.example/"]]]}`,
+			expected: []string{
+				`version "1": expected "1.0"`,
+				`publication "2024-01-02 03:04": not an RFC 3339 time`,
+				`services[0][0][0] "example.": not a domain name`,
+			},
+		},
+		{
+			description: "publication not a string",
+			json:        `{"version": "1.0", "publication": 1704164645, "services": []}`,
+			expected:    []string{`publication "1704164645": not a string`},
+		},
+		{
+			description: "not a registry",
+			json:        `{"version": "1.0", "services": {}}`,
+			err:         true,
+		},
+	}
+
+	for i, test := range tests {
+		err := ValidateJSON(DNS, []byte(test.json))
+
+		var problems []string
+		var validationErr *ValidationError
+
+		if errors.As(err, &validationErr) {
+			for _, problem := range validationErr.Problems {
+				problems = append(problems, problem.String())
+			}
+		} else if (err != nil) != test.err {
+			t.Fatalf("At index %d (%s): unexpected error %v", i, test.description, err)
+		}
+
+		if !reflect.DeepEqual(test.expected, problems) {
+			t.Fatalf("At index %d (%s): expected %q, got %q", i, test.description, test.expected, problems)
+		}
+	}
+}
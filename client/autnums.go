@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/garslo/rdap-client/protocol"
+)
+
+// AutnumRange is a run of consecutive ASNs answered alike. Autnum is the
+// object returned for Start, or nil when none of the ASNs is registered.
+type AutnumRange struct {
+	Start  uint32
+	End    uint32
+	Autnum *protocol.Autnum
+}
+
+// ParseAutnumRange parses a range of ASNs such as "64512-64520" or
+// "AS64512-AS64520".
+func ParseAutnumRange(s string) (uint32, uint32, error) {
+	first, last, ok := strings.Cut(strings.TrimSpace(s), "-")
+
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid asn range %q", s)
+	}
+
+	start, err := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(first)), "AS"), 10, 32)
+
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid asn range %q: %w", s, err)
+	}
+
+	end, err := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(last)), "AS"), 10, 32)
+
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid asn range %q: %w", s, err)
+	}
+
+	if end < start {
+		return 0, 0, fmt.Errorf("invalid asn range %q: end before start", s)
+	}
+
+	return uint32(start), uint32(end), nil
+}
+
+// AutnumRange looks up every ASN from start to end and consolidates the
+// answers. ASNs covered by the startAutnum and endAutnum of a registry
+// range object are not queried again, and consecutive ASNs registered under
+// the same name to the same entities are reported as one range, as are
+// consecutive unregistered ones. On error, the ranges consolidated so far
+// are returned with it.
+func (c *Client) AutnumRange(ctx context.Context, start, end uint32) ([]AutnumRange, error) {
+	var ranges []AutnumRange
+
+	for asn := uint64(start); asn <= uint64(end); {
+		autnum, err := c.Autnum(ctx, uint32(asn))
+
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return ranges, err
+		}
+
+		last := asn
+
+		if autnum != nil && autnum.StartAutnum <= uint32(asn) && uint64(autnum.EndAutnum) > asn {
+			last = uint64(autnum.EndAutnum)
+
+			if last > uint64(end) {
+				last = uint64(end)
+			}
+		}
+
+		if n := len(ranges); n > 0 && sameHolder(ranges[n-1].Autnum, autnum) {
+			ranges[n-1].End = uint32(last)
+		} else {
+			ranges = append(ranges, AutnumRange{Start: uint32(asn), End: uint32(last), Autnum: autnum})
+		}
+
+		asn = last + 1
+	}
+
+	return ranges, nil
+}
+
+func sameHolder(a, b *protocol.Autnum) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	if a.Name != b.Name || len(a.Entities) != len(b.Entities) {
+		return false
+	}
+
+	for i := range a.Entities {
+		if a.Entities[i].Handle != b.Entities[i].Handle {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/garslo/rdap-client/bootstrap"
+)
+
+func TestParseAutnumRange(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{query: "64512-64520", expected: "64512 64520 <nil>"},
+		{query: "AS64512 - as64520", expected: "64512 64520 <nil>"},
+		{query: "64520-64512", expected: `0 0 invalid asn range "64520-64512": end before start`},
+		{query: "64512", expected: `0 0 invalid asn range "64512"`},
+	}
+
+	for i, test := range tests {
+		start, end, err := ParseAutnumRange(test.query)
+
+		if result := fmt.Sprint(start, end, err); result != test.expected {
+			t.Fatalf("At index %d (%s): expected %q, got %q", i, test.query, test.expected, result)
+		}
+	}
+}
+
+func TestAutnumRange(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		switch r.URL.Path {
+		case "/autnum/64512", "/autnum/64513":
+			w.Write([]byte(`{"objectClassName": "autnum", "name": "EXAMPLE", "entities": [{"objectClassName": "entity", "handle": "ORG-1"}]}`))
+		case "/autnum/64514":
+			w.Write([]byte(`{"objectClassName": "autnum", "name": "BLOCK", "startAutnum": 64514, "endAutnum": 64517}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{Host: server.URL, Bootstrap: &bootstrap.Registries{}}
+	ranges, err := client.AutnumRange(context.Background(), 64512, 64520)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report []string
+
+	for _, r := range ranges {
+		name := "-"
+
+		if r.Autnum != nil {
+			name = r.Autnum.Name
+		}
+
+		report = append(report, fmt.Sprintf("%d-%d %s", r.Start, r.End, name))
+	}
+
+	expected := []string{"64512-64513 EXAMPLE", "64514-64517 BLOCK", "64518-64520 -"}
+
+	if fmt.Sprint(expected) != fmt.Sprint(report) {
+		t.Fatalf("expected %q, got %q", expected, report)
+	}
+
+	if requests != 6 {
+		t.Fatalf("expected ASNs covered by a range object not to be queried, got %d requests", requests)
+	}
+}
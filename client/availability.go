@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+type Availability struct {
+	Domain    string `json:"domain"`
+	Available bool   `json:"available"`
+	Server    string `json:"server,omitempty"`
+}
+
+// Available looks domain up on its authoritative server and treats a 404 as
+// the name being available. Servers listed in the bootstrap entry are tried
+// in order until one answers; any answer other than 200 or 404 is an error,
+// since it says nothing about registration.
+func (c *Client) Available(ctx context.Context, domain string) (Availability, error) {
+	name, servers, err := c.domainServers(ctx, domain)
+
+	if err != nil {
+		return Availability{Domain: domain}, err
+	}
+
+	return c.available(ctx, servers, domain, name)
+}
+
+func (c *Client) available(ctx context.Context, servers []string, domain, name string) (Availability, error) {
+	var err error
+
+	availability := Availability{Domain: domain}
+	availability.Server, err = c.query(ctx, servers, "domain/"+name, nil)
+
+	if errors.Is(err, ErrNotFound) {
+		availability.Available = true
+		return availability, nil
+	}
+
+	return availability, err
+}
+
+type BulkOptions struct {
+	// Interval is the minimum time between two queries to the same server.
+	Interval time.Duration
+	// MaxRetries bounds how often a name is retried after a 429.
+	MaxRetries int
+	// Backoff is the first wait after a 429 without Retry-After; it doubles
+	// with every further retry. Retries never start before the server's
+	// cooldown is over.
+	Backoff time.Duration
+}
+
+type AvailabilityResult struct {
+	Availability
+	Err error
+}
+
+// AvailableBulk checks every name in domains, grouping them by the host of
+// their target server so that each host is queried sequentially at most
+// once per Interval while different hosts are queried in parallel. Results are
+// streamed as they arrive, in no particular order, and the channel is closed
+// once every name has been reported or ctx is done.
+func (c *Client) AvailableBulk(ctx context.Context, domains []string, opts BulkOptions) <-chan AvailabilityResult {
+	type query struct {
+		domain  string
+		name    string
+		servers []string
+	}
+
+	var (
+		results = make(chan AvailabilityResult)
+		groups  = map[string][]query{}
+		failed  []AvailabilityResult
+		wg      sync.WaitGroup
+	)
+
+	for _, domain := range domains {
+		name, servers, err := c.domainServers(ctx, domain)
+
+		if err != nil {
+			failed = append(failed, AvailabilityResult{Availability: Availability{Domain: domain}, Err: err})
+			continue
+		}
+
+		host := serverHost(servers[0])
+		groups[host] = append(groups[host], query{domain: domain, name: name, servers: servers})
+	}
+
+	send := func(result AvailabilityResult) bool {
+		select {
+		case results <- result:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for _, queries := range groups {
+		wg.Add(1)
+
+		go func(queries []query) {
+			defer wg.Done()
+
+			var last time.Time
+
+			for _, q := range queries {
+				var (
+					availability Availability
+					err          error
+				)
+
+				for attempt := 0; ; attempt++ {
+					if wait := opts.Interval - time.Since(last); !last.IsZero() && wait > 0 && !sleep(ctx, wait) {
+						return
+					}
+
+					last = time.Now()
+					availability, err = c.available(ctx, q.servers, q.domain, q.name)
+
+					var statusErr *StatusError
+
+					if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests || attempt >= opts.MaxRetries {
+						break
+					}
+
+					wait := statusErr.RetryAfter
+
+					if wait == 0 {
+						wait = opts.Backoff << uint(attempt)
+					}
+
+					if until, ok := c.cooldowns().Until(serverHost(statusErr.Server)); ok && time.Until(until) > wait {
+						wait = time.Until(until)
+					}
+
+					if !sleep(ctx, wait) {
+						return
+					}
+				}
+
+				if !send(AvailabilityResult{Availability: availability, Err: err}) {
+					return
+				}
+			}
+		}(queries)
+	}
+
+	go func() {
+		for _, result := range failed {
+			if !send(result) {
+				break
+			}
+		}
+
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func serverHost(server string) string {
+	u, err := url.Parse(server)
+
+	if err != nil {
+		return ""
+	}
+
+	return u.Hostname()
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garslo/rdap-client/bootstrap"
+)
+
+func TestAvailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rdap/domain/example.com":
+			w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+		case "/rdap/domain/broken.com":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	registry := bootstrap.ServiceRegistry{
+		Services: bootstrap.ServicesList{
+			{{"com"}, {server.URL + "/rdap/"}},
+		},
+	}
+
+	tests := []struct {
+		description   string
+		domain        string
+		expected      Availability
+		expectedError error
+	}{
+		{
+			description: "it should report a registered domain as unavailable",
+			domain:      "example.com",
+			expected:    Availability{Domain: "example.com", Server: server.URL + "/rdap/"},
+		},
+		{
+			description: "it should report a 404 as available",
+			domain:      "unregistered.com.",
+			expected:    Availability{Domain: "unregistered.com.", Available: true, Server: server.URL + "/rdap/"},
+		},
+		{
+			description:   "it should not interpret other statuses",
+			domain:        "broken.com",
+			expected:      Availability{Domain: "broken.com", Server: server.URL + "/rdap/"},
+			expectedError: &StatusError{Server: server.URL + "/rdap/", StatusCode: http.StatusInternalServerError},
+		},
+		{
+			description:   "it should not check domains without a server",
+			domain:        "example.org",
+			expected:      Availability{Domain: "example.org"},
+			expectedError: ErrNoServer,
+		},
+	}
+
+	registries := &bootstrap.Registries{}
+	registries.Set(bootstrap.DNS, registry)
+	client := &Client{HTTP: server.Client(), Bootstrap: registries}
+
+	for i, test := range tests {
+		availability, err := client.Available(context.Background(), test.domain)
+
+		if fmt.Sprintf("%v", test.expectedError) != fmt.Sprintf("%v", err) {
+			t.Fatalf("At index %d (%s): expected error %v, got %v", i, test.description, test.expectedError, err)
+		}
+
+		if availability != test.expected {
+			t.Fatalf("At index %d (%s): expected %+v, got %+v", i, test.description, test.expected, availability)
+		}
+	}
+}
+
+func TestAvailableBulk(t *testing.T) {
+	var (
+		mutex    sync.Mutex
+		limited  = map[string]bool{}
+		requests = map[string][]time.Time{}
+	)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if r.URL.Path == "/domain/busy.com" && !limited[r.URL.Path] {
+			limited[r.URL.Path] = true
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		if r.URL.Path == "/domain/taken.com" {
+			w.Write([]byte(`{"objectClassName": "domain"}`))
+			return
+		}
+
+		http.NotFound(w, r)
+	}
+
+	comServer := httptest.NewServer(http.HandlerFunc(handler))
+	defer comServer.Close()
+
+	netServer := httptest.NewServer(http.HandlerFunc(handler))
+	defer netServer.Close()
+
+	registry := bootstrap.ServiceRegistry{
+		Services: bootstrap.ServicesList{
+			{{"com"}, {comServer.URL + "/"}},
+			{{"net"}, {netServer.URL + "/"}},
+			// A host serving several TLDs under different paths is
+			// still paced as one server.
+			{{"org"}, {comServer.URL + "/org/"}},
+		},
+	}
+
+	opts := BulkOptions{Interval: 20 * time.Millisecond, MaxRetries: 1, Backoff: time.Millisecond}
+	domains := []string{"taken.com", "free.com", "busy.com", "free.net", "free.org", "free.biz"}
+
+	var report []string
+
+	registries := &bootstrap.Registries{}
+	registries.Set(bootstrap.DNS, registry)
+	// Requests are timed as the client sends them, so that server side
+	// scheduling doesn't shorten the gaps.
+	send := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		mutex.Lock()
+		requests[req.URL.Host] = append(requests[req.URL.Host], time.Now())
+		mutex.Unlock()
+
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	client := &Client{HTTP: &http.Client{Transport: send}, Bootstrap: registries}
+
+	for result := range client.AvailableBulk(context.Background(), domains, opts) {
+		report = append(report, fmt.Sprintf("%s %t %v", result.Domain, result.Available, result.Err))
+	}
+
+	sort.Strings(report)
+
+	expected := []string{
+		"busy.com true <nil>",
+		"free.biz false no rdap server found",
+		"free.com true <nil>",
+		"free.net true <nil>",
+		"free.org true <nil>",
+		"taken.com false <nil>",
+	}
+
+	if fmt.Sprint(expected) != fmt.Sprint(report) {
+		t.Fatalf("expected %q, got %q", expected, report)
+	}
+
+	for host, times := range requests {
+		for i := 1; i < len(times); i++ {
+			// The gap is measured a little after the client paced each
+			// request.
+			if gap := times[i].Sub(times[i-1]); gap < opts.Interval-5*time.Millisecond {
+				t.Fatalf("expected requests to %s to be %s apart, got %s", host, opts.Interval, gap)
+			}
+		}
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("expected both servers to be queried, got %v", requests)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,392 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CachingTransport is an http.RoundTripper that keeps successful GET
+// responses on disk. Entries younger than TTL are answered without touching
+// the network; older ones are revalidated with If-None-Match and
+// If-Modified-Since, and refreshed in place when the server answers 304.
+//
+// Entries are keyed by URL and Accept-Language, and an entry is only reused
+// for a request that agrees with it on every header its response names in
+// Vary. Requests carrying Authorization bypass the cache altogether, so that
+// one user's answers are never served to another.
+type CachingTransport struct {
+	Dir       string
+	TTL       time.Duration
+	Transport http.RoundTripper
+	// OnError, when set, is passed the errors storing entries, such as a
+	// full disk. They never fail the request, which is answered as if it
+	// hadn't been cached.
+	OnError func(error)
+}
+
+type storedResponse struct {
+	URL        string      `json:"url"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	Stored     time.Time   `json:"stored"`
+
+	// Vary holds the request headers the response varies on, as sent with
+	// the request that fetched it.
+	Vary http.Header `json:"vary,omitempty"`
+}
+
+func (t *CachingTransport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+
+	return http.DefaultTransport
+}
+
+func (t *CachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" || req.Header.Get("Authorization") != "" {
+		return t.transport().RoundTrip(req)
+	}
+
+	path := filepath.Join(t.Dir, requestKey(req))
+	entry, _ := readStoredResponse(path)
+
+	if entry != nil && !entry.matches(req) {
+		entry = nil
+	}
+
+	if entry != nil && time.Since(entry.Stored) < t.TTL {
+		return entry.response(req), nil
+	}
+
+	outgoing := req
+
+	if entry != nil {
+		outgoing = req.Clone(req.Context())
+
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			outgoing.Header.Set("If-None-Match", etag)
+		}
+
+		if modified := entry.Header.Get("Last-Modified"); modified != "" {
+			outgoing.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	resp, err := t.transport().RoundTrip(outgoing)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		resp.Body.Close()
+
+		for _, name := range []string{"ETag", "Last-Modified", "Cache-Control", "Expires"} {
+			if value := resp.Header.Get(name); value != "" {
+				entry.Header.Set(name, value)
+			}
+		}
+
+		entry.Stored = time.Now()
+
+		if err := writeStoredResponse(path, entry); err != nil {
+			report(t.OnError, err)
+		}
+
+		return entry.response(req), nil
+	case resp.StatusCode == http.StatusOK && !varyAll(resp.Header):
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if err != nil {
+			return nil, err
+		}
+
+		entry = &storedResponse{
+			URL:        req.URL.String(),
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       body,
+			Stored:     time.Now(),
+			Vary:       varyHeaders(req, resp.Header),
+		}
+
+		if err := writeStoredResponse(path, entry); err != nil {
+			report(t.OnError, err)
+		}
+
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return resp, nil
+}
+
+// CacheKey derives the file name a URL is cached under. Scheme and host are
+// case-insensitive, default ports and fragments are dropped and query
+// parameters are sorted, so equivalent queries share one entry.
+func CacheKey(u *url.URL) string {
+	return cacheKey(u, "")
+}
+
+// requestKey is the key of req: that of its URL, or of its URL and its
+// Accept-Language when it sets one, so that answers in one language don't
+// shadow those in another.
+func requestKey(req *http.Request) string {
+	return cacheKey(req.URL, req.Header.Get("Accept-Language"))
+}
+
+func cacheKey(u *url.URL, language string) string {
+	canonical := *u
+	canonical.Scheme = strings.ToLower(u.Scheme)
+	canonical.Host = strings.ToLower(u.Host)
+	canonical.Fragment = ""
+	canonical.RawQuery = u.Query().Encode()
+
+	if port := canonical.Port(); (canonical.Scheme == "https" && port == "443") || (canonical.Scheme == "http" && port == "80") {
+		canonical.Host = canonical.Hostname()
+	}
+
+	key := canonical.String()
+
+	if language != "" {
+		key += "\nAccept-Language: " + language
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:]) + ".json"
+}
+
+// varyHeaders returns the headers of req named by the Vary header of a
+// response to it.
+func varyHeaders(req *http.Request, header http.Header) http.Header {
+	var vary http.Header
+
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				if vary == nil {
+					vary = http.Header{}
+				}
+
+				vary[http.CanonicalHeaderKey(name)] = req.Header.Values(name)
+			}
+		}
+	}
+
+	return vary
+}
+
+// varyAll reports whether a response varies on something other than
+// request headers, with "Vary: *", and thus can't be reused.
+func varyAll(header http.Header) bool {
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if strings.TrimSpace(name) == "*" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matches reports whether req sends the headers e varies on as the request
+// that fetched e did.
+func (e *storedResponse) matches(req *http.Request) bool {
+	for name, values := range e.Vary {
+		if strings.Join(req.Header.Values(name), ", ") != strings.Join(values, ", ") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e *storedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+func readStoredResponse(path string) (*storedResponse, error) {
+	b, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &storedResponse{}
+
+	if err := json.Unmarshal(b, entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func writeStoredResponse(path string, entry *storedResponse) error {
+	b, err := json.Marshal(entry)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-")
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+type CacheEntryInfo struct {
+	Key        string
+	URL        string
+	StatusCode int
+	Size       int64
+	Stored     time.Time
+}
+
+func (i CacheEntryInfo) Age() time.Duration {
+	return time.Since(i.Stored)
+}
+
+type CacheStats struct {
+	Entries int
+	Size    int64
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// Entries lists the responses stored under t.Dir. Files that are not cache
+// entries are ignored.
+func (t *CachingTransport) Entries() ([]CacheEntryInfo, error) {
+	files, err := os.ReadDir(t.Dir)
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []CacheEntryInfo
+
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		path := filepath.Join(t.Dir, file.Name())
+		entry, err := readStoredResponse(path)
+
+		if err != nil {
+			continue
+		}
+
+		info, err := file.Info()
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, CacheEntryInfo{
+			Key:        file.Name(),
+			URL:        entry.URL,
+			StatusCode: entry.StatusCode,
+			Size:       info.Size(),
+			Stored:     entry.Stored,
+		})
+	}
+
+	return entries, nil
+}
+
+func (t *CachingTransport) Stats() (CacheStats, error) {
+	var stats CacheStats
+	entries, err := t.Entries()
+
+	if err != nil {
+		return stats, err
+	}
+
+	for _, entry := range entries {
+		stats.Entries++
+		stats.Size += entry.Size
+
+		if stats.Oldest.IsZero() || entry.Stored.Before(stats.Oldest) {
+			stats.Oldest = entry.Stored
+		}
+
+		if entry.Stored.After(stats.Newest) {
+			stats.Newest = entry.Stored
+		}
+	}
+
+	return stats, nil
+}
+
+// Purge evicts every entry for which match returns true, or every entry when
+// match is nil, and returns how many were removed.
+func (t *CachingTransport) Purge(match func(CacheEntryInfo) bool) (int, error) {
+	entries, err := t.Entries()
+
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+
+	for _, entry := range entries {
+		if match != nil && !match(entry) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(t.Dir, entry.Key)); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+
+		removed++
+	}
+
+	return removed, nil
+}
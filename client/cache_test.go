@@ -0,0 +1,289 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCachingTransport(t *testing.T) {
+	var (
+		requests    int
+		conditional int
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"objectClassName": "domain"}`))
+	}))
+	defer server.Close()
+
+	dir, err := os.MkdirTemp("", "rdap-cache")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	transport := &CachingTransport{Dir: dir, TTL: time.Hour}
+	client := &http.Client{Transport: transport}
+
+	get := func() string {
+		resp, err := client.Get(server.URL + "/domain/example.com")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(body)
+	}
+
+	tests := []struct {
+		description         string
+		ttl                 time.Duration
+		expectedRequests    int
+		expectedConditional int
+	}{
+		{
+			description:      "it should fetch and store an uncached response",
+			ttl:              time.Hour,
+			expectedRequests: 1,
+		},
+		{
+			description:      "it should answer a fresh entry from disk",
+			ttl:              time.Hour,
+			expectedRequests: 1,
+		},
+		{
+			description:         "it should revalidate a stale entry",
+			ttl:                 0,
+			expectedRequests:    2,
+			expectedConditional: 1,
+		},
+	}
+
+	for i, test := range tests {
+		transport.TTL = test.ttl
+
+		if body := get(); body != `{"objectClassName": "domain"}` {
+			t.Fatalf("At index %d (%s): unexpected body %q", i, test.description, body)
+		}
+
+		if requests != test.expectedRequests || conditional != test.expectedConditional {
+			t.Fatalf("At index %d (%s): expected %d requests (%d conditional), got %d (%d)", i, test.description, test.expectedRequests, test.expectedConditional, requests, conditional)
+		}
+	}
+
+	u, _ := url.Parse(server.URL + "/domain/example.com")
+
+	if _, err := os.Stat(filepath.Join(dir, CacheKey(u))); err != nil {
+		t.Fatalf("expected cache entry on disk: %s", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not an entry"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := transport.Entries()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 || entries[0].URL != u.String() || entries[0].StatusCode != http.StatusOK {
+		t.Fatalf("expected a single entry for %s, got %+v", u, entries)
+	}
+
+	stats, err := transport.Stats()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Entries != 1 || stats.Size != entries[0].Size || !stats.Oldest.Equal(entries[0].Stored) {
+		t.Fatalf("unexpected stats %+v for entries %+v", stats, entries)
+	}
+
+	removed, err := transport.Purge(func(entry CacheEntryInfo) bool {
+		return entry.Age() > time.Hour
+	})
+
+	if err != nil || removed != 0 {
+		t.Fatalf("expected nothing to be purged, got %d (%v)", removed, err)
+	}
+
+	removed, err = transport.Purge(nil)
+
+	if err != nil || removed != 1 {
+		t.Fatalf("expected one entry to be purged, got %d (%v)", removed, err)
+	}
+}
+
+func TestCachingTransportWriteError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"objectClassName": "domain"}`))
+	}))
+	defer server.Close()
+
+	// A directory below a regular file can't be created.
+	file := filepath.Join(t.TempDir(), "file")
+
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var errs []error
+
+	transport := &CachingTransport{Dir: filepath.Join(file, "cache"), TTL: time.Hour, OnError: func(err error) { errs = append(errs, err) }}
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL + "/domain/example.com")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK || string(body) != `{"objectClassName": "domain"}` {
+		t.Fatalf("expected the response despite the failed write, got %d %q", resp.StatusCode, body)
+	}
+
+	if len(errs) != 1 {
+		t.Fatalf("expected the failed write to be reported, got %v", errs)
+	}
+}
+
+func TestCachingTransportVary(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Vary", "Accept-Language, X-Tenant")
+		w.Write([]byte(r.Header.Get("Accept-Language") + "/" + r.Header.Get("X-Tenant") + "/" + r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	dir, err := os.MkdirTemp("", "rdap-cache")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	client := &http.Client{Transport: &CachingTransport{Dir: dir, TTL: time.Hour}}
+
+	tests := []struct {
+		description      string
+		header           http.Header
+		expectedBody     string
+		expectedRequests int
+	}{
+		{
+			description:      "it should fetch an answer in english",
+			header:           http.Header{"Accept-Language": {"en"}},
+			expectedBody:     "en//",
+			expectedRequests: 1,
+		},
+		{
+			description:      "it should not answer in english a request for japanese",
+			header:           http.Header{"Accept-Language": {"ja"}},
+			expectedBody:     "ja//",
+			expectedRequests: 2,
+		},
+		{
+			description:      "it should keep the english answer",
+			header:           http.Header{"Accept-Language": {"en"}},
+			expectedBody:     "en//",
+			expectedRequests: 2,
+		},
+		{
+			description:      "it should refetch when another header the response varies on differs",
+			header:           http.Header{"Accept-Language": {"en"}, "X-Tenant": {"b"}},
+			expectedBody:     "en/b/",
+			expectedRequests: 3,
+		},
+		{
+			description:      "it should not cache authenticated requests",
+			header:           http.Header{"Authorization": {"Bearer alice"}},
+			expectedBody:     "//Bearer alice",
+			expectedRequests: 4,
+		},
+		{
+			description:      "it should not serve one user's answer to another",
+			header:           http.Header{"Authorization": {"Bearer bob"}},
+			expectedBody:     "//Bearer bob",
+			expectedRequests: 5,
+		},
+	}
+
+	for i, test := range tests {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/domain/example.com", nil)
+		req.Header = test.header
+
+		resp, err := client.Do(req)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(body) != test.expectedBody || requests != test.expectedRequests {
+			t.Fatalf("At index %d (%s): expected %q after %d requests, got %q after %d", i, test.description, test.expectedBody, test.expectedRequests, body, requests)
+		}
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	tests := []struct {
+		description string
+		a, b        string
+	}{
+		{
+			description: "it should ignore host case and default ports",
+			a:           "https://RDAP.Example.com:443/domain/example.com",
+			b:           "https://rdap.example.com/domain/example.com",
+		},
+		{
+			description: "it should ignore query parameter order and fragments",
+			a:           "https://rdap.example.com/domains?name=ex*&count=1#top",
+			b:           "https://rdap.example.com/domains?count=1&name=ex*",
+		},
+	}
+
+	for i, test := range tests {
+		a, _ := url.Parse(test.a)
+		b, _ := url.Parse(test.b)
+
+		if CacheKey(a) != CacheKey(b) {
+			t.Fatalf("At index %d (%s): expected %s and %s to share a key", i, test.description, test.a, test.b)
+		}
+	}
+}
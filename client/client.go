@@ -0,0 +1,640 @@
+// Package client queries RDAP servers over HTTP. It locates the responsible
+// server through the bootstrap registries, falls back to the other servers a
+// registry lists when one is unreachable, and decodes the answers into the
+// objects of package protocol.
+//
+// Exported identifiers follow semantic versioning. Within a major version,
+// Options and the other option structs only gain fields, and the zero value
+// of a new field keeps the earlier behavior. The errors the package returns
+// stay comparable with errors.Is and errors.As, but their messages may
+// change in any release.
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/garslo/rdap-client/bootstrap"
+	"github.com/garslo/rdap-client/protocol"
+)
+
+var (
+	ErrNoServer = errors.New("no rdap server found")
+	ErrNotFound = errors.New("rdap object not found")
+)
+
+// StatusError reports a response status that the caller could not
+// interpret. RetryAfter is set from the Retry-After header when present, and
+// Response from the body when it is an RDAP error object.
+type StatusError struct {
+	Server     string
+	StatusCode int
+	RetryAfter time.Duration
+	Response   *protocol.Error
+}
+
+func (e *StatusError) Error() string {
+	if e.Response != nil {
+		return fmt.Sprintf("%s from %s", e.Response.Error(), e.Server)
+	}
+
+	return fmt.Sprintf("unexpected status %d from %s", e.StatusCode, e.Server)
+}
+
+// Is makes a 404 match ErrNotFound.
+func (e *StatusError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// Unwrap returns Response, so that errors.As finds the *protocol.Error.
+func (e *StatusError) Unwrap() error {
+	if e.Response == nil {
+		return nil
+	}
+
+	return e.Response
+}
+
+// ContentTypeError reports a successful response that isn't JSON, such as
+// the HTML error page of a misconfigured proxy. Snippet holds the start of
+// the body.
+type ContentTypeError struct {
+	Server      string
+	ContentType string
+	Snippet     string
+}
+
+func (e *ContentTypeError) Error() string {
+	return fmt.Sprintf("unexpected content type %q from %s: %s", e.ContentType, e.Server, e.Snippet)
+}
+
+// Client looks RDAP objects up on their authoritative servers. The zero
+// value is ready to use: it sends requests with http.DefaultClient and
+// bootstraps from IANA.
+type Client struct {
+	// HTTP sends the queries and, unless Bootstrap is set, fetches the
+	// bootstrap registries.
+	HTTP *http.Client
+	// Bootstrap locates the servers responsible for a query.
+	Bootstrap *bootstrap.Registries
+	// Host, when set, is the base URL every query is sent to instead of
+	// the servers Bootstrap lists.
+	Host string
+	// Limiter paces queries, and the bootstrap fetches of the default
+	// Bootstrap, per server host. Each Client keeps its own when nil,
+	// pacing the RIR servers of DefaultHostLimits and no other host.
+	Limiter *RateLimiter
+	// NoLimiter drops the default Limiter, for callers that pace their
+	// queries themselves. It has no effect when Limiter is set.
+	NoLimiter bool
+	// Cooldowns tracks the servers that answered 429. Each Client keeps its
+	// own when nil.
+	Cooldowns *Cooldowns
+	// UserAgent replaces DefaultUserAgent in the queries and in the
+	// bootstrap fetches of the default Bootstrap.
+	UserAgent string
+	// Language, such as "en" or "ja, en;q=0.5", is sent as Accept-Language
+	// to servers that localize their answers. Pair it with
+	// protocol.FilterNotices and protocol.FilterEntities to show one
+	// language of answers that come in several.
+	Language string
+	// Lenient decodes responses with protocol.UnmarshalLenient instead of
+	// rejecting the ones that deviate from RFC 9083.
+	Lenient bool
+	// Policy, when set, restricts the hosts that queries, their redirects
+	// and the bootstrap fetches of the default Bootstrap may go to. A
+	// server it denies is skipped as if it were unreachable; when every
+	// server is denied the query fails with ErrHostDenied. A Bootstrap set
+	// by the caller needs a PolicyTransport of its own.
+	Policy *HostPolicy
+	// ExpandEntities follows up the object queries with an entity query,
+	// on the server that answered, for every entity that comes with a
+	// handle but no contact details, and inlines the answers. Entities are
+	// cached by server and handle for the lifetime of the Client. Entities
+	// whose follow-up fails are left as the server sent them.
+	ExpandEntities bool
+	// WhoisFallback makes Query fall back to a port 43 whois lookup, for
+	// the registries that have yet to deploy RDAP, as described there.
+	WhoisFallback bool
+	// WhoisHost is the "host:port" of the whois server Whois asks first;
+	// IANAWhois when empty.
+	WhoisHost string
+
+	entityMutex sync.Mutex
+	entities    map[string]protocol.Entity
+
+	once             sync.Once
+	defaultBootstrap *bootstrap.Registries
+	defaultCooldowns *Cooldowns
+	defaultLimiter   *RateLimiter
+}
+
+func (c *Client) httpClient() *http.Client {
+	httpClient := c.HTTP
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	if c.Policy == nil {
+		return httpClient
+	}
+
+	restricted := *httpClient
+	restricted.Transport = PolicyTransport{Policy: c.Policy, Transport: httpClient.Transport}
+
+	return &restricted
+}
+
+func (c *Client) registries() *bootstrap.Registries {
+	if c.Bootstrap != nil {
+		return c.Bootstrap
+	}
+
+	c.once.Do(c.initDefaults)
+
+	return c.defaultBootstrap
+}
+
+func (c *Client) cooldowns() *Cooldowns {
+	if c.Cooldowns != nil {
+		return c.Cooldowns
+	}
+
+	c.once.Do(c.initDefaults)
+
+	return c.defaultCooldowns
+}
+
+// limiter returns the RateLimiter queries wait for, or nil for none.
+func (c *Client) limiter() *RateLimiter {
+	if c.Limiter != nil {
+		return c.Limiter
+	}
+
+	c.once.Do(c.initDefaults)
+
+	return c.defaultLimiter
+}
+
+func (c *Client) userAgent() string {
+	if c.UserAgent == "" {
+		return DefaultUserAgent
+	}
+
+	return c.UserAgent
+}
+
+func (c *Client) initDefaults() {
+	if !c.NoLimiter {
+		c.defaultLimiter = &RateLimiter{Default: Unlimited}
+	}
+
+	httpClient := *c.httpClient()
+	httpClient.Transport = UserAgentTransport{UserAgent: c.userAgent(), Transport: httpClient.Transport}
+
+	limiter := c.Limiter
+
+	if limiter == nil {
+		limiter = c.defaultLimiter
+	}
+
+	if limiter != nil {
+		httpClient.Transport = &RateLimitTransport{Limiter: limiter, Transport: httpClient.Transport}
+	}
+
+	c.defaultBootstrap = &bootstrap.Registries{Client: &httpClient}
+	c.defaultCooldowns = &Cooldowns{}
+}
+
+// Domain looks fqdn up on the servers the dns registry lists for it, or the
+// ip registries for a reverse zone such as 2.0.192.in-addr.arpa.
+func (c *Client) Domain(ctx context.Context, fqdn string) (*protocol.Domain, error) {
+	var domain protocol.Domain
+
+	name, servers, err := c.domainServers(ctx, fqdn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	server, err := c.query(ctx, servers, "domain/"+name, &domain)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.expandEntities(ctx, server, domain.Entities)
+
+	return &domain, nil
+}
+
+// Nameserver looks the nameserver fqdn up on the servers the dns registry
+// lists for its parent domain.
+func (c *Client) Nameserver(ctx context.Context, fqdn string) (*protocol.Nameserver, error) {
+	var nameserver protocol.Nameserver
+
+	name, servers, err := c.domainServers(ctx, fqdn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	server, err := c.query(ctx, servers, "nameserver/"+name, &nameserver)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.expandEntities(ctx, server, nameserver.Entities)
+
+	return &nameserver, nil
+}
+
+// IP looks up the most specific network containing ip.
+func (c *Client) IP(ctx context.Context, ip net.IP) (*protocol.IPNetwork, error) {
+	bits := 8 * net.IPv6len
+
+	if v4 := ip.To4(); v4 != nil {
+		ip, bits = v4, 8*net.IPv4len
+	}
+
+	return c.ipNetwork(ctx, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, "ip/"+ip.String())
+}
+
+// IPNetwork looks up the network registered for the CIDR block network.
+func (c *Client) IPNetwork(ctx context.Context, network *net.IPNet) (*protocol.IPNetwork, error) {
+	if v4 := network.IP.To4(); v4 != nil && len(network.Mask) == net.IPv4len {
+		network = &net.IPNet{IP: v4, Mask: network.Mask}
+	}
+
+	return c.ipNetwork(ctx, network, "ip/"+network.String())
+}
+
+func (c *Client) ipNetwork(ctx context.Context, network *net.IPNet, path string) (*protocol.IPNetwork, error) {
+	var ipNetwork protocol.IPNetwork
+
+	servers, err := c.servers(func(r *bootstrap.Registries) ([]string, error) {
+		return r.IPNetwork(ctx, network)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	server, err := c.query(ctx, servers, path, &ipNetwork)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.expandEntities(ctx, server, ipNetwork.Entities)
+
+	return &ipNetwork, nil
+}
+
+// Autnum looks up the autonomous system asn.
+func (c *Client) Autnum(ctx context.Context, asn uint32) (*protocol.Autnum, error) {
+	var autnum protocol.Autnum
+
+	servers, err := c.servers(func(r *bootstrap.Registries) ([]string, error) {
+		return r.Autnum(ctx, asn)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	server, err := c.query(ctx, servers, "autnum/"+strconv.FormatUint(uint64(asn), 10), &autnum)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.expandEntities(ctx, server, autnum.Entities)
+
+	return &autnum, nil
+}
+
+// Entity looks handle up on the server at base. Entities have no bootstrap
+// registry of their own, so base is usually taken from a link or from the
+// server that returned the object referencing the entity.
+func (c *Client) Entity(ctx context.Context, base, handle string) (*protocol.Entity, error) {
+	var entity protocol.Entity
+
+	server, err := c.query(ctx, []string{base}, "entity/"+handle, &entity)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.expandEntities(ctx, server, entity.Entities)
+
+	return &entity, nil
+}
+
+// expandConcurrency bounds the entity follow-ups of ExpandEntities in
+// flight for one object; Limiter still paces them per host.
+const expandConcurrency = 4
+
+// expandEntities inlines the handle-only entities among entities, looked up
+// on base, when ExpandEntities is set. The entities looked up are not
+// expanded in turn, so entities referencing each other can't loop.
+func (c *Client) expandEntities(ctx context.Context, base string, entities []protocol.Entity) {
+	if !c.ExpandEntities || base == "" {
+		return
+	}
+
+	protocol.ExpandEntities(entities, expandConcurrency, func(handle string) (protocol.Entity, error) {
+		key := strings.TrimSuffix(base, "/") + "/entity/" + handle
+
+		c.entityMutex.Lock()
+		entity, ok := c.entities[key]
+		c.entityMutex.Unlock()
+
+		if ok {
+			return entity, nil
+		}
+
+		if _, err := c.query(ctx, []string{base}, "entity/"+handle, &entity); err != nil {
+			return entity, err
+		}
+
+		c.entityMutex.Lock()
+		defer c.entityMutex.Unlock()
+
+		if c.entities == nil {
+			c.entities = map[string]protocol.Entity{}
+		}
+
+		c.entities[key] = entity
+
+		return entity, nil
+	})
+}
+
+// domainServers returns the name fqdn is queried as and the servers to
+// query: IANA for a top-level domain written with a leading dot, as in
+// ".dev", and the servers the bootstrap registries list otherwise.
+func (c *Client) domainServers(ctx context.Context, fqdn string) (string, []string, error) {
+	name, err := protocol.NormalizeDomain(fqdn)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	servers, err := c.servers(func(r *bootstrap.Registries) ([]string, error) {
+		if isTLD(name) {
+			return []string{IANABaseURL}, nil
+		}
+
+		if !protocol.IsReverseName(name) {
+			return r.Domain(ctx, name)
+		}
+
+		// Reverse zones are served by the registry of the network they
+		// cover, which the dns registry doesn't list.
+		network, err := protocol.ParseReverseName(name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return r.IPNetwork(ctx, network)
+	})
+
+	// A top-level domain is written ".dev" to route it to IANA, but its
+	// object is at domain/dev.
+	return strings.TrimPrefix(name, "."), servers, err
+}
+
+// Top-level domain objects are published by IANA rather than by the
+// registries the dns bootstrap file points at.
+const IANABaseURL = "https://rdap.iana.org/"
+
+// isTLD reports whether name denotes a top-level domain itself, written with
+// a leading dot as in ".dev".
+func isTLD(name string) bool {
+	return len(name) > 1 && name[0] == '.' && !strings.Contains(name[1:], ".")
+}
+
+// servers returns Host when it is set and the servers lookup finds in the
+// bootstrap registries otherwise.
+func (c *Client) servers(lookup func(*bootstrap.Registries) ([]string, error)) ([]string, error) {
+	if c.Host != "" {
+		return []string{c.Host}, nil
+	}
+
+	servers, err := lookup(c.registries())
+
+	if err == nil && len(servers) == 0 {
+		err = ErrNoServer
+	}
+
+	return servers, err
+}
+
+// newRequest builds the request for path, or for server itself when path is
+// empty, with the headers every query carries.
+func (c *Client) newRequest(ctx context.Context, server, path string) (*http.Request, error) {
+	uri := server
+
+	if path != "" {
+		uri = strings.TrimSuffix(server, "/") + "/" + path
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", protocol.MediaTypeRDAP)
+	req.Header.Set("User-Agent", c.userAgent())
+
+	if c.Language != "" {
+		req.Header.Set("Accept-Language", c.Language)
+	}
+
+	return req, nil
+}
+
+// query requests path, or the servers themselves when path is empty, from
+// each of servers in turn until one answers and decodes a successful answer
+// into v, unless v is nil. A streamDecoder v reads the body as it arrives
+// instead. It returns the server that answered.
+//
+// Any status besides 200 is reported as a *StatusError, except for a 404
+// without an RDAP error object, reported as ErrNotFound; either matches
+// ErrNotFound. A body that isn't JSON is reported as a *ContentTypeError.
+// Only transport errors and servers cooling down after a 429 move on to the
+// next server.
+func (c *Client) query(ctx context.Context, servers []string, path string, v interface{}) (string, error) {
+	var lastErr error = ErrNoServer
+
+	for _, server := range servers {
+		req, err := c.newRequest(ctx, server, path)
+
+		if err != nil {
+			return "", err
+		}
+
+		if err := c.cooldowns().check(ctx, req.URL.Hostname()); err != nil {
+			if errors.Is(err, ErrRateLimited) {
+				lastErr = err
+				continue
+			}
+
+			return "", err
+		}
+
+		if limiter := c.limiter(); limiter != nil {
+			if err := limiter.Wait(ctx, req.URL.Hostname()); err != nil {
+				return "", err
+			}
+		}
+
+		resp, err := c.httpClient().Do(req)
+
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if stream, ok := v.(streamDecoder); ok && resp.StatusCode == http.StatusOK {
+			return server, c.decodeStream(server, resp, stream)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusNotFound:
+			if response := parseError(body); response != nil {
+				return server, &StatusError{Server: server, StatusCode: resp.StatusCode, Response: response}
+			}
+
+			return server, ErrNotFound
+		case resp.StatusCode != http.StatusOK:
+			statusErr := &StatusError{
+				Server:     server,
+				StatusCode: resp.StatusCode,
+				RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+				Response:   parseError(body),
+			}
+
+			if resp.StatusCode == http.StatusTooManyRequests {
+				cooldown := statusErr.RetryAfter
+
+				if retryAfter := strings.TrimSpace(resp.Header.Get("Retry-After")); cooldown == 0 && retryAfter != "0" {
+					cooldown = -1
+				}
+
+				c.cooldowns().Record(req.URL.Hostname(), cooldown)
+			}
+
+			return server, statusErr
+		case err != nil:
+			lastErr = err
+			continue
+		case v == nil:
+			return server, nil
+		case !isJSON(resp.Header.Get("Content-Type"), body):
+			return server, &ContentTypeError{Server: server, ContentType: resp.Header.Get("Content-Type"), Snippet: snippet(body)}
+		case c.Lenient:
+			return server, protocol.UnmarshalLenient(body, v)
+		}
+
+		return server, json.Unmarshal(body, v)
+	}
+
+	return "", lastErr
+}
+
+// streamDecoder is a query result decoded straight from the response body,
+// for answers too large to buffer.
+type streamDecoder interface {
+	decodeStream(io.Reader) error
+}
+
+func (c *Client) decodeStream(server string, resp *http.Response, stream streamDecoder) error {
+	defer resp.Body.Close()
+
+	body := bufio.NewReader(resp.Body)
+	start, _ := body.Peek(512)
+
+	if !isJSON(resp.Header.Get("Content-Type"), start) {
+		return &ContentTypeError{Server: server, ContentType: resp.Header.Get("Content-Type"), Snippet: snippet(start)}
+	}
+
+	return stream.decodeStream(body)
+}
+
+// parseError decodes body as an RDAP error object, returning nil when it
+// isn't one.
+func parseError(body []byte) *protocol.Error {
+	var response protocol.Error
+
+	if err := json.Unmarshal(body, &response); err != nil || response.ErrorCode == 0 {
+		return nil
+	}
+
+	return &response
+}
+
+// isJSON reports whether a response of contentType may be decoded. Besides
+// application/rdap+json and application/json, it tolerates the servers that
+// label a JSON object as text/plain or leave the type out.
+func isJSON(contentType string, body []byte) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch mediaType {
+	case protocol.MediaTypeRDAP, "application/json":
+		return true
+	case "", "text/plain", "application/octet-stream":
+		return bytes.HasPrefix(bytes.TrimSpace(body), []byte("{"))
+	}
+
+	return false
+}
+
+// snippet returns the start of body on a single line, for error messages.
+func snippet(body []byte) string {
+	const size = 120
+
+	s := strings.Join(strings.Fields(string(body)), " ")
+
+	if len(s) > size {
+		s = strings.ToValidUTF8(s[:size], "") + "..."
+	}
+
+	return s
+}
+
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
@@ -0,0 +1,288 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/garslo/rdap-client/bootstrap"
+	"github.com/garslo/rdap-client/protocol"
+)
+
+func TestClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rdap/domain/xn--zckzah.jp":
+			w.Write([]byte(`{"objectClassName": "domain", "ldhName": "xn--zckzah.jp"}`))
+		case "/rdap/nameserver/ns1.example.com":
+			w.Write([]byte(`{"objectClassName": "nameserver", "ldhName": "ns1.example.com"}`))
+		case "/rdap/ip/192.0.2.1", "/rdap/ip/2001:db8::/32":
+			w.Write([]byte(`{"objectClassName": "ip network", "handle": "NET-1"}`))
+		case "/rdap/autnum/64500":
+			w.Write([]byte(`{"objectClassName": "autnum", "startAutnum": "64500"}`))
+		case "/rdap/entity/ABC-1":
+			w.Write([]byte(`{"objectClassName": "entity", "handle": "ABC-1"}`))
+		case "/rdap/entity/GONE":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errorCode": 404, "title": "Not Found", "description": ["No such entity."]}`))
+		case "/rdap/entity/PROXY":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html>\n  <body>Gateway login required</body>\n</html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	unreachable := "http://127.0.0.1:1/rdap/"
+	uris := []string{unreachable, server.URL + "/rdap/"}
+
+	registries := &bootstrap.Registries{}
+	registries.Set(bootstrap.DNS, bootstrap.ServiceRegistry{Services: bootstrap.ServicesList{{{"jp", "com"}, uris}}})
+	registries.Set(bootstrap.IPv4, bootstrap.ServiceRegistry{Services: bootstrap.ServicesList{{{"192.0.2.0/24"}, uris}}})
+	registries.Set(bootstrap.IPv6, bootstrap.ServiceRegistry{Services: bootstrap.ServicesList{{{"2001:db8::/32"}, uris}}})
+	registries.Set(bootstrap.ASN, bootstrap.ServiceRegistry{Services: bootstrap.ServicesList{{{"64496-64511"}, uris}}})
+
+	client := &Client{Bootstrap: registries, Lenient: true}
+	ctx := context.Background()
+	_, network, _ := net.ParseCIDR("2001:db8::/32")
+
+	tests := []struct {
+		description string
+		query       func() (string, error)
+		expected    string
+	}{
+		{
+			description: "it should convert u-labels and skip unreachable servers",
+			query: func() (string, error) {
+				domain, err := client.Domain(ctx, "テスト.jp.")
+
+				if err != nil {
+					return "", err
+				}
+
+				return domain.LDHName, nil
+			},
+			expected: "xn--zckzah.jp",
+		},
+		{
+			description: "it should look nameservers up through the dns registry",
+			query: func() (string, error) {
+				nameserver, err := client.Nameserver(ctx, "ns1.example.com")
+
+				if err != nil {
+					return "", err
+				}
+
+				return nameserver.LDHName, nil
+			},
+			expected: "ns1.example.com",
+		},
+		{
+			description: "it should look addresses up through the ipv4 registry",
+			query: func() (string, error) {
+				network, err := client.IP(ctx, net.ParseIP("192.0.2.1"))
+
+				if err != nil {
+					return "", err
+				}
+
+				return network.Handle, nil
+			},
+			expected: "NET-1",
+		},
+		{
+			description: "it should look blocks up through the ipv6 registry",
+			query: func() (string, error) {
+				network, err := client.IPNetwork(ctx, network)
+
+				if err != nil {
+					return "", err
+				}
+
+				return network.Handle, nil
+			},
+			expected: "NET-1",
+		},
+		{
+			description: "it should decode leniently when asked to",
+			query: func() (string, error) {
+				autnum, err := client.Autnum(ctx, 64500)
+
+				if err != nil {
+					return "", err
+				}
+
+				return fmt.Sprint(autnum.StartAutnum), nil
+			},
+			expected: "64500",
+		},
+		{
+			description: "it should look entities up on the given server",
+			query: func() (string, error) {
+				entity, err := client.Entity(ctx, server.URL+"/rdap/", "ABC-1")
+
+				if err != nil {
+					return "", err
+				}
+
+				return entity.Handle, nil
+			},
+			expected: "ABC-1",
+		},
+	}
+
+	for i, test := range tests {
+		result, err := test.query()
+
+		if err != nil {
+			t.Fatalf("At index %d (%s): unexpected error %s", i, test.description, err)
+		}
+
+		if result != test.expected {
+			t.Fatalf("At index %d (%s): expected %q, got %q", i, test.description, test.expected, result)
+		}
+	}
+
+	if _, err := client.Autnum(ctx, 64501); err != ErrNotFound {
+		t.Fatalf("expected a 404 to be reported as ErrNotFound, got %v", err)
+	}
+
+	if _, err := client.Domain(ctx, "example.org"); err != ErrNoServer {
+		t.Fatalf("expected names without a server to be reported as ErrNoServer, got %v", err)
+	}
+
+	var rdapErr *protocol.Error
+
+	if _, err := client.Entity(ctx, server.URL+"/rdap/", "GONE"); !errors.Is(err, ErrNotFound) || !errors.As(err, &rdapErr) || rdapErr.Title != "Not Found" {
+		t.Fatalf("expected an error object to be reported as a *protocol.Error, got %v", err)
+	}
+
+	var contentTypeErr *ContentTypeError
+
+	if _, err := client.Entity(ctx, server.URL+"/rdap/", "PROXY"); !errors.As(err, &contentTypeErr) || contentTypeErr.Snippet != "<html> <body>Gateway login required</body> </html>" {
+		t.Fatalf("expected an html page to be reported as a *ContentTypeError, got %v", err)
+	}
+
+	direct := &Client{Host: server.URL + "/rdap/", Bootstrap: &bootstrap.Registries{}, Lenient: true}
+
+	if autnum, err := direct.Autnum(ctx, 64500); err != nil || autnum.ObjectClassName != "autnum" {
+		t.Fatalf("expected Host to bypass bootstrap, got %+v (%v)", autnum, err)
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	var languages []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		languages = append(languages, r.Header.Get("Accept-Language"))
+		w.Write([]byte(`{"objectClassName": "domain"}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	(&Client{Host: server.URL, Bootstrap: &bootstrap.Registries{}}).Domain(ctx, "example.com")
+	(&Client{Host: server.URL, Bootstrap: &bootstrap.Registries{}, Language: "ja"}).Domain(ctx, "example.com")
+
+	if fmt.Sprint(languages) != "[ ja]" {
+		t.Fatalf("expected Accept-Language only when Language is set, got %q", languages)
+	}
+}
+
+func TestTopLevelDomain(t *testing.T) {
+	var paths []string
+
+	iana := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "dev"}`))
+	}))
+	defer iana.Close()
+
+	registries := &bootstrap.Registries{}
+	registries.Set(bootstrap.DNS, bootstrap.ServiceRegistry{})
+
+	// Queries to IANA are sent to the fake server instead.
+	toIANA := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host != "rdap.iana.org" {
+			return nil, fmt.Errorf("unexpected host %s", req.URL.Host)
+		}
+
+		req.URL.Scheme, req.URL.Host = "http", iana.Listener.Addr().String()
+
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	c := &Client{HTTP: &http.Client{Transport: toIANA}, Bootstrap: registries}
+	domain, err := c.Domain(context.Background(), ".DEV")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if domain.LDHName != "dev" || fmt.Sprint(paths) != "[/domain/dev]" {
+		t.Fatalf("expected the dev object from domain/dev, got %q from %q", domain.LDHName, paths)
+	}
+}
+
+func TestExpandEntities(t *testing.T) {
+	var (
+		mutex    sync.Mutex
+		requests = map[string]int{}
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests[r.URL.Path]++
+		mutex.Unlock()
+
+		switch r.URL.Path {
+		case "/domain/example.com":
+			w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com", "entities": [
+				{"objectClassName": "entity", "handle": "REG", "roles": ["registrar"]},
+				{"objectClassName": "entity", "handle": "GONE", "roles": ["technical"]}
+			]}`))
+		case "/nameserver/ns1.example.com":
+			w.Write([]byte(`{"objectClassName": "nameserver", "ldhName": "ns1.example.com", "entities": [{"objectClassName": "entity", "handle": "REG"}]}`))
+		case "/entity/REG":
+			w.Write([]byte(`{"objectClassName": "entity", "handle": "REG", "vcardArray": ["vcard", [["fn", {}, "text", "Registrar"]]], "entities": [{"objectClassName": "entity", "handle": "REG"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := &Client{Host: server.URL, Bootstrap: &bootstrap.Registries{}, ExpandEntities: true}
+	domain, err := c.Domain(context.Background(), "example.com")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if registrar := domain.Entities[0]; registrar.VCardArray == nil || fmt.Sprint(registrar.Roles) != "[registrar]" {
+		t.Fatalf("expected the registrar to be expanded with its roles kept, got %+v", registrar)
+	}
+
+	if gone := domain.Entities[1]; gone.VCardArray != nil || gone.Handle != "GONE" {
+		t.Fatalf("expected the failed entity to be left as sent, got %+v", gone)
+	}
+
+	nameserver, err := c.Nameserver(context.Background(), "ns1.example.com")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nameserver.Entities[0].VCardArray == nil {
+		t.Fatalf("expected the cached registrar to be inlined, got %+v", nameserver.Entities[0])
+	}
+
+	if requests["/entity/REG"] != 1 || requests["/entity/GONE"] != 1 {
+		t.Fatalf("expected each entity to be queried once, got %v", requests)
+	}
+}
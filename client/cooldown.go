@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrRateLimited = errors.New("rate limited")
+
+// DefaultCooldown is how long a host that answered 429 without Retry-After
+// is left alone.
+const DefaultCooldown = time.Minute
+
+// Cooldowns remembers the hosts that answered 429 and until when they asked
+// to be left alone, so that later queries don't pile on and extend the ban.
+// Sharing one Cooldowns between clients extends this to all of them.
+type Cooldowns struct {
+	// Default replaces DefaultCooldown when set.
+	Default time.Duration
+	// Wait makes queries to a cooling host wait the cooldown out instead of
+	// failing with ErrRateLimited.
+	Wait bool
+
+	mutex sync.Mutex
+	until map[string]time.Time
+}
+
+// Record starts a cooldown of d for host, or of Default when d is negative.
+// It never shortens a cooldown already in progress.
+func (c *Cooldowns) Record(host string, d time.Duration) {
+	if d < 0 {
+		d = c.Default
+
+		if d == 0 {
+			d = DefaultCooldown
+		}
+	}
+
+	if d == 0 {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.until == nil {
+		c.until = map[string]time.Time{}
+	}
+
+	host = strings.ToLower(host)
+
+	if until := time.Now().Add(d); until.After(c.until[host]) {
+		c.until[host] = until
+	}
+}
+
+// Until reports when the cooldown of host ends, if one is in progress.
+func (c *Cooldowns) Until(host string) (time.Time, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	until, ok := c.until[strings.ToLower(host)]
+
+	if ok && !time.Now().Before(until) {
+		delete(c.until, strings.ToLower(host))
+		return time.Time{}, false
+	}
+
+	return until, ok
+}
+
+// check returns nil when host may be queried, waiting for its cooldown to
+// end first if c.Wait is set.
+func (c *Cooldowns) check(ctx context.Context, host string) error {
+	until, ok := c.Until(host)
+
+	if !ok {
+		return nil
+	}
+
+	if c.Wait {
+		if !sleep(ctx, time.Until(until)) {
+			return ctx.Err()
+		}
+
+		return nil
+	}
+
+	return &CooldownError{Host: host, Until: until}
+}
+
+// CooldownError reports a query skipped because Host is cooling down until
+// Until. It matches ErrRateLimited.
+type CooldownError struct {
+	Host  string
+	Until time.Time
+}
+
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("%v: %s is cooling down until %s", ErrRateLimited, e.Host, e.Until.UTC().Format(time.RFC3339))
+}
+
+func (e *CooldownError) Is(target error) bool {
+	return target == ErrRateLimited
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/garslo/rdap-client/bootstrap"
+)
+
+func TestCooldowns(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		switch r.URL.Path {
+		case "/domain/busy.com":
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/domain/brief.com":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte(`{"objectClassName": "domain"}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := &Client{Host: server.URL, Bootstrap: &bootstrap.Registries{}}
+
+	var statusErr *StatusError
+
+	if _, err := client.Domain(ctx, "busy.com"); !errors.As(err, &statusErr) || statusErr.RetryAfter != time.Minute {
+		t.Fatalf("expected the 429 to be reported, got %v", err)
+	}
+
+	if _, err := client.Domain(ctx, "example.com"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected later queries to fail fast, got %v", err)
+	}
+
+	if requests != 1 {
+		t.Fatalf("expected a cooling server not to be queried, got %d requests", requests)
+	}
+
+	if until, ok := client.cooldowns().Until("127.0.0.1"); !ok || time.Until(until) < 59*time.Second {
+		t.Fatalf("expected a cooldown from Retry-After, got %s (%t)", until, ok)
+	}
+
+	shared := &Cooldowns{Default: 20 * time.Millisecond, Wait: true}
+	waiting := &Client{Host: server.URL, Bootstrap: &bootstrap.Registries{}, Cooldowns: shared}
+	other := &Client{Host: server.URL, Bootstrap: &bootstrap.Registries{}, Cooldowns: shared}
+
+	waiting.Domain(ctx, "brief.com")
+	start := time.Now()
+
+	if _, err := other.Domain(ctx, "example.com"); err != nil {
+		t.Fatalf("expected the query to wait the cooldown out, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Fatalf("expected a shared cooldown of the default length, waited %s", elapsed)
+	}
+}
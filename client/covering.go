@@ -0,0 +1,70 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sort"
+
+	"github.com/garslo/rdap-client/protocol"
+)
+
+// CoveringNetwork is an RDAP network and the addresses it was reported for.
+// Network is nil and Err set when the lookup for the first address failed.
+type CoveringNetwork struct {
+	Network *protocol.IPNetwork
+	IPs     []net.IP
+	Err     error
+}
+
+// CoveringNetworks looks up the networks containing ips with one query per
+// network rather than per address: addresses are sorted, and those between
+// the start and end addresses of a network already found are attributed to
+// it without a query of their own. An address inside a more specific
+// network registered below one already found is therefore attributed to the
+// enclosing one, the price of the saved queries in log enrichment jobs.
+// Duplicates are dropped. It stops early, with the networks found so far,
+// when ctx is done.
+func (c *Client) CoveringNetworks(ctx context.Context, ips []net.IP) []CoveringNetwork {
+	sorted := make([]net.IP, 0, len(ips))
+
+	for _, ip := range ips {
+		if ip = ip.To16(); ip != nil {
+			sorted = append(sorted, ip)
+		}
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+
+	var networks []CoveringNetwork
+
+	for i := 0; i < len(sorted) && ctx.Err() == nil; {
+		network, err := c.IP(ctx, sorted[i])
+		covering := CoveringNetwork{Network: network, IPs: []net.IP{displayIP(sorted[i])}, Err: err}
+
+		for i++; i < len(sorted); i++ {
+			if sorted[i].Equal(sorted[i-1]) {
+				continue
+			}
+
+			if network == nil || !network.Contains(sorted[i]) {
+				break
+			}
+
+			covering.IPs = append(covering.IPs, displayIP(sorted[i]))
+		}
+
+		networks = append(networks, covering)
+	}
+
+	return networks
+}
+
+// displayIP returns IPv4 addresses in their 4-byte form.
+func displayIP(ip net.IP) net.IP {
+	if v4 := ip.To4(); v4 != nil {
+		return v4
+	}
+
+	return ip
+}
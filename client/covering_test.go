@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/garslo/rdap-client/bootstrap"
+)
+
+func TestCoveringNetworks(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		switch r.URL.Path {
+		case "/ip/192.0.2.1":
+			w.Write([]byte(`{"objectClassName": "ip network", "handle": "NET-1", "startAddress": "192.0.2.0", "endAddress": "192.0.2.255"}`))
+		case "/ip/2001:db8::1":
+			w.Write([]byte(`{"objectClassName": "ip network", "handle": "NET-6", "startAddress": "2001:db8::", "endAddress": "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var ips []net.IP
+
+	for _, s := range []string{"2001:db8::1", "192.0.2.200", "198.51.100.1", "192.0.2.1", "2001:db8:1::1", "192.0.2.200"} {
+		ips = append(ips, net.ParseIP(s))
+	}
+
+	client := &Client{Host: server.URL, Bootstrap: &bootstrap.Registries{}}
+
+	var report []string
+
+	for _, network := range client.CoveringNetworks(context.Background(), ips) {
+		handle := fmt.Sprint(network.Err)
+
+		if network.Network != nil {
+			handle = network.Network.Handle
+		}
+
+		report = append(report, fmt.Sprintf("%s %v", handle, network.IPs))
+	}
+
+	expected := []string{
+		"NET-1 [192.0.2.1 192.0.2.200]",
+		"rdap object not found [198.51.100.1]",
+		"NET-6 [2001:db8::1 2001:db8:1::1]",
+	}
+
+	if fmt.Sprint(expected) != fmt.Sprint(report) {
+		t.Fatalf("expected %q, got %q", expected, report)
+	}
+
+	if requests != 3 {
+		t.Fatalf("expected one query per network, got %d", requests)
+	}
+}
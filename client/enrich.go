@@ -0,0 +1,301 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/garslo/rdap-client/protocol"
+)
+
+// EnrichFields are the columns Enrich can append:
+//
+//	handle      handle of the network containing the address
+//	network     its start and end addresses, as "start - end"
+//	name        its name
+//	country     its country code
+//	holder      full name of its registrant
+//	abuse_email email addresses of its abuse contacts
+//	asn         first origin ASN it lists, from ARIN's originas0 extension
+//	asn_name    name of that autonomous system
+//	error       why the other columns are empty, if they are
+var EnrichFields = []string{"handle", "network", "name", "country", "holder", "abuse_email", "asn", "asn_name", "error"}
+
+// originAutnumsMember is where ARIN lists the ASNs originating a network.
+const originAutnumsMember = "arin_originas0_originautnums"
+
+type EnrichOptions struct {
+	// Column is the header of the column holding the addresses to look up.
+	Column string
+	// Fields are the columns to append, from EnrichFields.
+	Fields []string
+}
+
+// Enrich copies the CSV read from r to w, one row at a time, appending the
+// opts.Fields columns about the address in opts.Column. Addresses inside a
+// network already seen are not queried again, nor are addresses whose
+// lookup failed, and the queries go through c, so they share its HTTP
+// caching and Limiter. Rows whose address is invalid or not found get
+// empty columns, and the reason in the error column when it is asked for;
+// only malformed CSV and ctx ending stop the copy.
+func (c *Client) Enrich(ctx context.Context, r io.Reader, w io.Writer, opts EnrichOptions) error {
+	for _, field := range opts.Fields {
+		if !isEnrichField(field) {
+			return fmt.Errorf("unknown field %q", field)
+		}
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	writer := csv.NewWriter(w)
+
+	header, err := reader.Read()
+
+	if err == io.EOF {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	column := -1
+
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), opts.Column) {
+			column = i
+			break
+		}
+	}
+
+	if column < 0 {
+		return fmt.Errorf("no column %q", opts.Column)
+	}
+
+	if err := writer.Write(append(header, opts.Fields...)); err != nil {
+		return err
+	}
+
+	enricher := enricher{client: c, failures: map[string]error{}, asnNames: map[uint32]string{}}
+
+	for {
+		record, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		var values map[string]string
+
+		if column < len(record) {
+			values = enricher.lookup(ctx, strings.TrimSpace(record[column]))
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		for _, field := range opts.Fields {
+			record = append(record, values[field])
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func isEnrichField(field string) bool {
+	for _, f := range EnrichFields {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// enricher remembers the networks, failed addresses and autonomous system
+// names Enrich has already looked up.
+type enricher struct {
+	client *Client
+	// ranges are the address ranges of the networks seen, sorted and
+	// disjoint, so that finding the network of an address is a binary
+	// search. Where networks overlap, the range goes to the one seen first.
+	ranges   []networkRange
+	failures map[string]error
+	asnNames map[uint32]string
+}
+
+type networkRange struct {
+	start, end net.IP
+	network    *protocol.IPNetwork
+}
+
+func (e *enricher) lookup(ctx context.Context, address string) map[string]string {
+	ip := net.ParseIP(address)
+
+	if ip == nil {
+		return map[string]string{"error": fmt.Sprintf("invalid address %q", address)}
+	}
+
+	if err, ok := e.failures[ip.String()]; ok {
+		return map[string]string{"error": err.Error()}
+	}
+
+	network := e.cached(ip)
+
+	if network == nil {
+		var err error
+
+		if network, err = e.client.IP(ctx, ip); err != nil {
+			if ctx.Err() == nil {
+				e.failures[ip.String()] = err
+			}
+
+			return map[string]string{"error": err.Error()}
+		}
+
+		e.add(network)
+	}
+
+	values := map[string]string{
+		"handle":  network.Handle,
+		"name":    network.Name,
+		"country": network.Country,
+	}
+
+	if network.StartAddress != "" || network.EndAddress != "" {
+		values["network"] = network.StartAddress + " - " + network.EndAddress
+	}
+
+	if registrants := protocol.EntitiesByRole(network.Entities, "registrant"); len(registrants) > 0 {
+		values["holder"] = strings.Join(registrants[0].VCard("fn"), "; ")
+	}
+
+	var emails []string
+
+	for _, abuse := range protocol.EntitiesByRole(network.Entities, "abuse") {
+		emails = append(emails, abuse.VCard("email")...)
+	}
+
+	values["abuse_email"] = strings.Join(emails, "; ")
+
+	if asn, ok := originAutnum(network); ok {
+		values["asn"] = strconv.FormatUint(uint64(asn), 10)
+		values["asn_name"] = e.asnName(ctx, asn)
+	}
+
+	return values
+}
+
+func (e *enricher) cached(ip net.IP) *protocol.IPNetwork {
+	ip = ip.To16()
+	i := sort.Search(len(e.ranges), func(i int) bool { return bytes.Compare(e.ranges[i].end, ip) >= 0 })
+
+	if i < len(e.ranges) && bytes.Compare(e.ranges[i].start, ip) <= 0 {
+		return e.ranges[i].network
+	}
+
+	return nil
+}
+
+// add gives network the parts of its address range no network seen before
+// covers.
+func (e *enricher) add(network *protocol.IPNetwork) {
+	start, end := net.ParseIP(network.StartAddress).To16(), net.ParseIP(network.EndAddress).To16()
+
+	if start == nil || end == nil || bytes.Compare(start, end) > 0 {
+		return
+	}
+
+	i := sort.Search(len(e.ranges), func(i int) bool { return bytes.Compare(e.ranges[i].end, start) >= 0 })
+	ranges := append([]networkRange{}, e.ranges[:i]...)
+
+	for ; start != nil && i < len(e.ranges) && bytes.Compare(e.ranges[i].start, end) <= 0; i++ {
+		if bytes.Compare(start, e.ranges[i].start) < 0 {
+			ranges = append(ranges, networkRange{start, previousIP(e.ranges[i].start), network})
+		}
+
+		ranges = append(ranges, e.ranges[i])
+		start = nextIP(e.ranges[i].end)
+	}
+
+	if start != nil && bytes.Compare(start, end) <= 0 {
+		ranges = append(ranges, networkRange{start, end, network})
+	}
+
+	e.ranges = append(ranges, e.ranges[i:]...)
+}
+
+func (e *enricher) asnName(ctx context.Context, asn uint32) string {
+	if name, ok := e.asnNames[asn]; ok {
+		return name
+	}
+
+	autnum, err := e.client.Autnum(ctx, asn)
+
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return ""
+	}
+
+	if autnum != nil {
+		e.asnNames[asn] = autnum.Name
+	} else {
+		e.asnNames[asn] = ""
+	}
+
+	return e.asnNames[asn]
+}
+
+// nextIP returns the address after ip, or nil after the last one.
+func nextIP(ip net.IP) net.IP {
+	next := append(net.IP{}, ip...)
+
+	for i := len(next) - 1; i >= 0; i-- {
+		if next[i]++; next[i] != 0 {
+			return next
+		}
+	}
+
+	return nil
+}
+
+// previousIP returns the address before ip, which is not the first one.
+func previousIP(ip net.IP) net.IP {
+	previous := append(net.IP{}, ip...)
+
+	for i := len(previous) - 1; i >= 0; i-- {
+		if previous[i]--; previous[i] != 0xff {
+			break
+		}
+	}
+
+	return previous
+}
+
+func originAutnum(network *protocol.IPNetwork) (uint32, bool) {
+	var asns []uint32
+
+	if err := json.Unmarshal(network.Unknown[originAutnumsMember], &asns); err != nil || len(asns) == 0 {
+		return 0, false
+	}
+
+	return asns[0], true
+}
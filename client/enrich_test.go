@@ -0,0 +1,96 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/garslo/rdap-client/bootstrap"
+)
+
+func TestEnrich(t *testing.T) {
+	requests := map[string]int{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+
+		switch r.URL.Path {
+		case "/ip/192.0.2.1":
+			w.Write([]byte(`{
+				"objectClassName": "ip network",
+				"handle": "NET-1",
+				"startAddress": "192.0.2.0",
+				"endAddress": "192.0.2.255",
+				"country": "US",
+				"arin_originas0_originautnums": [64500],
+				"entities": [{
+					"objectClassName": "entity",
+					"roles": ["registrant"],
+					"vcardArray": ["vcard", [["fn", {}, "text", "Example Networks"]]],
+					"entities": [{
+						"objectClassName": "entity",
+						"roles": ["abuse"],
+						"vcardArray": ["vcard", [["email", {}, "text", "abuse@example.net"]]]
+					}]
+				}]
+			}`))
+		case "/ip/203.0.113.5":
+			w.Write([]byte(`{"objectClassName": "ip network", "handle": "NET-INNER", "startAddress": "203.0.113.0", "endAddress": "203.0.113.127"}`))
+		case "/ip/203.0.113.200":
+			w.Write([]byte(`{"objectClassName": "ip network", "handle": "NET-OUTER", "startAddress": "203.0.112.0", "endAddress": "203.0.115.255"}`))
+		case "/autnum/64500":
+			w.Write([]byte(`{"objectClassName": "autnum", "name": "EXAMPLE-AS"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	input := "time,ip\n" +
+		"10:00,192.0.2.1\n" +
+		"10:01,192.0.2.77\n" +
+		"10:02,not-an-ip\n" +
+		"10:03,198.51.100.1\n" +
+		"10:04,198.51.100.1\n" +
+		"10:05,203.0.113.5\n" +
+		"10:06,203.0.113.200\n" +
+		"10:07,203.0.113.6\n" +
+		"10:08,203.0.112.1\n" +
+		"10:09,203.0.115.255\n"
+
+	var output bytes.Buffer
+
+	client := &Client{Host: server.URL, Bootstrap: &bootstrap.Registries{}}
+	opts := EnrichOptions{Column: "IP", Fields: []string{"handle", "holder", "asn_name", "country", "abuse_email", "error"}}
+
+	if err := client.Enrich(context.Background(), strings.NewReader(input), &output, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "time,ip,handle,holder,asn_name,country,abuse_email,error\n" +
+		"10:00,192.0.2.1,NET-1,Example Networks,EXAMPLE-AS,US,abuse@example.net,\n" +
+		"10:01,192.0.2.77,NET-1,Example Networks,EXAMPLE-AS,US,abuse@example.net,\n" +
+		"10:02,not-an-ip,,,,,,\"invalid address \"\"not-an-ip\"\"\"\n" +
+		"10:03,198.51.100.1,,,,,,rdap object not found\n" +
+		"10:04,198.51.100.1,,,,,,rdap object not found\n" +
+		"10:05,203.0.113.5,NET-INNER,,,,,\n" +
+		"10:06,203.0.113.200,NET-OUTER,,,,,\n" +
+		"10:07,203.0.113.6,NET-INNER,,,,,\n" +
+		"10:08,203.0.112.1,NET-OUTER,,,,,\n" +
+		"10:09,203.0.115.255,NET-OUTER,,,,,\n"
+
+	if output.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, output.String())
+	}
+
+	if requests["/ip/192.0.2.1"] != 1 || requests["/ip/192.0.2.77"] != 0 || requests["/autnum/64500"] != 1 || requests["/ip/198.51.100.1"] != 1 || len(requests) != 5 {
+		t.Fatalf("expected covered addresses, failed addresses and known ASNs not to be queried again, got %v", requests)
+	}
+
+	if err := client.Enrich(context.Background(), strings.NewReader(input), &output, EnrichOptions{Column: "ip", Fields: []string{"owner"}}); err == nil || err.Error() != `unknown field "owner"` {
+		t.Fatalf("expected unknown fields to be rejected, got %v", err)
+	}
+}
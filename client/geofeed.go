@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/garslo/rdap-client/protocol"
+)
+
+var ErrNoGeofeed = errors.New("no geofeed link")
+
+// GeofeedEntry is one line of an RFC 8805 geofeed: the location of the
+// addresses in Prefix. Region is an ISO 3166-2 code such as "US-CA".
+type GeofeedEntry struct {
+	Prefix  *net.IPNet
+	Country string
+	Region  string
+	City    string
+}
+
+// Geofeed fetches and parses the geofeed network links to. As RFC 9632
+// section 5 requires, entries for prefixes outside network are dropped.
+func (c *Client) Geofeed(ctx context.Context, network *protocol.IPNetwork) ([]GeofeedEntry, error) {
+	links := protocol.GeofeedLinks(network.Links)
+
+	if len(links) == 0 {
+		return nil, ErrNoGeofeed
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, links[0].Href, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", c.userAgent())
+	resp, err := c.httpClient().Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{Server: links[0].Href, StatusCode: resp.StatusCode}
+	}
+
+	entries, err := ParseGeofeed(resp.Body)
+
+	if err != nil {
+		return nil, fmt.Errorf("geofeed %s: %w", links[0].Href, err)
+	}
+
+	var covered []GeofeedEntry
+
+	for _, entry := range entries {
+		if network.ContainsNetwork(entry.Prefix) {
+			covered = append(covered, entry)
+		}
+	}
+
+	return covered, nil
+}
+
+// ParseGeofeed reads the entries of an RFC 8805 geofeed, skipping comments
+// and blank lines.
+func ParseGeofeed(r io.Reader) ([]GeofeedEntry, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var entries []GeofeedEntry
+
+	for {
+		record, err := reader.Read()
+
+		if err == io.EOF {
+			return entries, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		_, prefix, err := net.ParseCIDR(strings.TrimSpace(record[0]))
+
+		if err != nil {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		entry := GeofeedEntry{Prefix: prefix}
+		fields := []*string{&entry.Country, &entry.Region, &entry.City}
+
+		for i, field := range fields {
+			if i+1 < len(record) {
+				*field = strings.TrimSpace(record[i+1])
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/garslo/rdap-client/protocol"
+)
+
+func TestGeofeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", protocol.MediaTypeGeofeed)
+		w.Write([]byte("# prefix,country,region,city\n" +
+			"192.0.2.0/25,US,US-CA,San Francisco,\n" +
+			"\n" +
+			"192.0.2.128/25,DE\n" +
+			"198.51.100.0/24,FR,FR-75,Paris\n"))
+	}))
+	defer server.Close()
+
+	network := &protocol.IPNetwork{
+		StartAddress: "192.0.2.0",
+		EndAddress:   "192.0.2.255",
+		Links: []protocol.Link{
+			{Rel: "self", Href: "https://rdap.example.net/ip/192.0.2.0/24", Type: protocol.MediaTypeRDAP},
+			{Rel: "geo", Href: server.URL + "/geofeed.csv", Type: protocol.MediaTypeGeofeed},
+		},
+	}
+
+	entries, err := (&Client{}).Geofeed(context.Background(), network)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report []string
+
+	for _, entry := range entries {
+		report = append(report, fmt.Sprintf("%s %s %s %s", entry.Prefix, entry.Country, entry.Region, entry.City))
+	}
+
+	expected := []string{"192.0.2.0/25 US US-CA San Francisco", "192.0.2.128/25 DE  "}
+
+	if fmt.Sprintf("%q", expected) != fmt.Sprintf("%q", report) {
+		t.Fatalf("expected %q, got %q", expected, report)
+	}
+
+	if _, err := (&Client{}).Geofeed(context.Background(), &protocol.IPNetwork{}); err != ErrNoGeofeed {
+		t.Fatalf("expected networks without a geofeed link to report ErrNoGeofeed, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/garslo/rdap-client/bootstrap"
+	"github.com/garslo/rdap-client/protocol"
+)
+
+// History fetches the prior versions of the object target names from the
+// /history/ endpoint of the history_version_0 extension, located through
+// bootstrap like target itself. Servers without the extension answer
+// ErrNotFound.
+func (c *Client) History(ctx context.Context, target Target) (*protocol.History, error) {
+	servers, path, err := c.targetServers(ctx, target)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var history protocol.History
+
+	if _, err := c.query(ctx, servers, "history/"+path, &history); err != nil {
+		return nil, err
+	}
+
+	return &history, nil
+}
+
+// targetServers returns the servers responsible for target and its path
+// below them. Entities have no bootstrap registry, so like Query, it sends
+// them to target.Base whether or not Host is set.
+func (c *Client) targetServers(ctx context.Context, target Target) ([]string, string, error) {
+	target = ipTarget(target)
+
+	switch target.Type {
+	case TargetDomain, TargetNameserver:
+		name, servers, err := c.domainServers(ctx, target.Value)
+		return servers, target.Type + "/" + name, err
+	case TargetIP:
+		network, err := parseNetwork(target.Value)
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		servers, err := c.servers(func(r *bootstrap.Registries) ([]string, error) {
+			return r.IPNetwork(ctx, network)
+		})
+
+		path := "ip/" + network.String()
+
+		if net.ParseIP(target.Value) != nil {
+			path = "ip/" + network.IP.String()
+		}
+
+		return servers, path, err
+	case TargetAutnum:
+		asn, err := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(target.Value), "AS"), 10, 32)
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		servers, err := c.servers(func(r *bootstrap.Registries) ([]string, error) {
+			return r.Autnum(ctx, uint32(asn))
+		})
+
+		return servers, "autnum/" + strconv.FormatUint(asn, 10), err
+	case TargetEntity:
+		return []string{target.Base}, "entity/" + target.Value, nil
+	}
+
+	return nil, "", fmt.Errorf("unknown target type %q", target.Type)
+}
+
+// parseNetwork parses an address as the network holding only it, or a CIDR
+// block.
+func parseNetwork(value string) (*net.IPNet, error) {
+	if ip := net.ParseIP(value); ip != nil {
+		bits := 8 * net.IPv6len
+
+		if v4 := ip.To4(); v4 != nil {
+			ip, bits = v4, 8*net.IPv4len
+		}
+
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+
+	_, network, err := net.ParseCIDR(value)
+
+	return network, err
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/garslo/rdap-client/bootstrap"
+)
+
+func TestHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/history/ip/192.0.2.0/24" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte(`{
+			"rdapConformance": ["history_version_0"],
+			"records": [
+				{"applicableFrom": "2021-03-01T00:00:00Z", "content": {"objectClassName": "ip network", "name": "EXAMPLE-NET-2"}},
+				{"applicableFrom": "2019-01-01T00:00:00Z", "applicableUntil": "2020-01-01T00:00:00Z", "content": {"objectClassName": "ip network", "name": "EXAMPLE-NET"}},
+				{"applicableFrom": "2020-01-01T00:00:00Z", "applicableUntil": "2021-03-01T00:00:00Z", "content": {"objectClassName": "ip network", "name": "EXAMPLE-NET", "events": [{"eventAction": "last update of RDAP database", "eventDate": "2020-01-01T00:00:00Z"}]}}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	registries := &bootstrap.Registries{}
+	registries.Set(bootstrap.IPv4, bootstrap.ServiceRegistry{Services: bootstrap.ServicesList{{{"192.0.2.0/24"}, {server.URL}}}})
+
+	client := &Client{Bootstrap: registries}
+	history, err := client.History(context.Background(), ParseTarget("192.0.2.0/24"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timeline, err := history.Timeline()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := fmt.Sprint(timeline); result != `[{2021-03-01 00:00:00 +0000 UTC [~ /name: "EXAMPLE-NET" -> "EXAMPLE-NET-2"]}]` {
+		t.Fatalf("expected the rename only, got %s", result)
+	}
+
+	if _, err := client.History(context.Background(), ParseTarget("192.0.2.1")); err != ErrNotFound {
+		t.Fatalf("expected servers without the extension to answer ErrNotFound, got %v", err)
+	}
+}
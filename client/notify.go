@@ -0,0 +1,142 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/garslo/rdap-client/protocol"
+)
+
+// WebhookPayload is the JSON body WebhookNotify posts for a change. Old and
+// New are the normalized responses.
+type WebhookPayload struct {
+	Time        time.Time           `json:"time"`
+	Differences []WebhookDifference `json:"differences"`
+	Old         json.RawMessage     `json:"old"`
+	New         json.RawMessage     `json:"new"`
+}
+
+// WebhookDifference is a protocol.Difference as a WebhookPayload carries
+// it, with lower-case JSON member names.
+type WebhookDifference struct {
+	Path string            `json:"path"`
+	Kind protocol.DiffKind `json:"kind"`
+	Old  string            `json:"old,omitempty"`
+	New  string            `json:"new,omitempty"`
+}
+
+// NotifyTimeout bounds each delivery of WebhookNotify and each run of
+// CommandNotify, since Watch waits for them before its next fetch.
+const NotifyTimeout = 30 * time.Second
+
+// WebhookNotify returns a notify function for Watch that posts every change
+// to url as a WebhookPayload, with client or http.DefaultClient when nil.
+// The first response and fetch errors are not changes and are not posted.
+// Delivery failures, including statuses other than 2xx and deliveries
+// taking longer than NotifyTimeout, are passed to onError when it is set.
+func WebhookNotify(url string, client *http.Client, onError func(error)) func(context.Context, WatchEvent) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return func(ctx context.Context, event WatchEvent) {
+		if event.Err != nil || event.Old == nil {
+			return
+		}
+
+		differences, err := protocol.Diff(event.Old, event.New)
+
+		if err != nil {
+			report(onError, err)
+			return
+		}
+
+		payload := WebhookPayload{Time: event.Time, Differences: []WebhookDifference{}, Old: event.Old, New: event.New}
+
+		for _, d := range differences {
+			payload.Differences = append(payload.Differences, WebhookDifference{Path: d.Path, Kind: d.Kind, Old: d.Old, New: d.New})
+		}
+
+		b, err := json.Marshal(payload)
+
+		if err != nil {
+			report(onError, err)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, NotifyTimeout)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+
+		if err != nil {
+			report(onError, err)
+			return
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+
+		if err != nil {
+			report(onError, err)
+			return
+		}
+
+		resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			report(onError, &StatusError{Server: url, StatusCode: resp.StatusCode})
+		}
+	}
+}
+
+// CommandNotify returns a notify function for Watch that runs name with
+// args for every change, with the differences on its standard input, one
+// per line as rendered by protocol.Difference.String. Like WebhookNotify, it
+// skips the first response and fetch errors, and passes failures to
+// onError when it is set. The command is killed once ctx is done or it has
+// run for NotifyTimeout.
+func CommandNotify(name string, args []string, onError func(error)) func(context.Context, WatchEvent) {
+	return func(ctx context.Context, event WatchEvent) {
+		if event.Err != nil || event.Old == nil {
+			return
+		}
+
+		differences, err := protocol.Diff(event.Old, event.New)
+
+		if err != nil {
+			report(onError, err)
+			return
+		}
+
+		var lines []string
+
+		for _, d := range differences {
+			lines = append(lines, d.String()+"\n")
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, NotifyTimeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Stdin = strings.NewReader(strings.Join(lines, ""))
+		// Children left holding the output open don't keep it waiting.
+		cmd.WaitDelay = time.Second
+
+		if output, err := cmd.CombinedOutput(); err != nil {
+			report(onError, fmt.Errorf("%s: %w: %s", name, err, bytes.TrimSpace(output)))
+		}
+	}
+}
+
+func report(onError func(error), err error) {
+	if onError != nil {
+		onError(err)
+	}
+}
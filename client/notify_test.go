@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotify(t *testing.T) {
+	var payloads []WebhookPayload
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("unexpected payload: %s", err)
+		}
+
+		payloads = append(payloads, payload)
+
+		if len(payloads) > 1 {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	var errs []error
+
+	notify := WebhookNotify(server.URL, nil, func(err error) { errs = append(errs, err) })
+	old, new := []byte(`{"ldhName":"example.com"}`), []byte(`{"ldhName":"example.com","status":["active"]}`)
+
+	notify(context.Background(), WatchEvent{Time: time.Now(), New: old})
+	notify(context.Background(), WatchEvent{Time: time.Now(), Err: ErrNoServer})
+	notify(context.Background(), WatchEvent{Time: time.Now(), Old: old, New: new})
+	notify(context.Background(), WatchEvent{Time: time.Now(), Old: new, New: old})
+
+	if len(payloads) != 2 {
+		t.Fatalf("expected only changes to be posted, got %d payloads", len(payloads))
+	}
+
+	if d := payloads[0].Differences; len(d) != 1 || d[0].Path != "/status" || d[0].Kind != "added" {
+		t.Fatalf("expected the added status in the payload, got %+v", d)
+	}
+
+	if len(errs) != 1 || errs[0].Error() != "unexpected status 403 from "+server.URL {
+		t.Fatalf("expected the rejected delivery to be reported, got %v", errs)
+	}
+}
+
+func TestCommandNotify(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "diff")
+	notify := CommandNotify("sh", []string{"-c", "cat > " + output}, func(err error) { t.Fatal(err) })
+
+	notify(context.Background(), WatchEvent{Old: []byte(`{"port43":"whois.example.net"}`), New: []byte(`{"port43":"whois.example.org"}`)})
+
+	b, err := os.ReadFile(output)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "~ /port43: \"whois.example.net\" -> \"whois.example.org\"\n"; string(b) != expected {
+		t.Fatalf("expected %q, got %q", expected, b)
+	}
+}
+
+func TestNotifyCancel(t *testing.T) {
+	var errs []error
+
+	release := make(chan struct{})
+
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer hung.Close()
+	defer close(release)
+
+	notifiers := []func(context.Context, WatchEvent){
+		WebhookNotify(hung.URL, nil, func(err error) { errs = append(errs, err) }),
+		CommandNotify("sleep", []string{"10"}, func(err error) { errs = append(errs, err) }),
+	}
+
+	for i, notify := range notifiers {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		start := time.Now()
+
+		notify(ctx, WatchEvent{Old: []byte(`{"port43":"whois.example.net"}`), New: []byte(`{"port43":"whois.example.org"}`)})
+		cancel()
+
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("At index %d: expected the notifier to stop with ctx, took %s", i, elapsed)
+		}
+
+		if len(errs) != i+1 {
+			t.Fatalf("At index %d: expected the interrupted delivery to be reported, got %v", i, errs)
+		}
+	}
+}
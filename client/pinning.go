@@ -0,0 +1,67 @@
+package client
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrPinMismatch = errors.New("certificate matches no pinned key")
+
+// SPKIPin returns the pin of the public key of cert, as "sha256/" followed
+// by the base64 SHA-256 digest of its SubjectPublicKeyInfo, the form
+// "openssl x509 -pubkey | openssl pkey -pubin -outform der | openssl dgst
+// -sha256 -binary | base64" prints after the prefix.
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// ParsePin checks that pin is the base64 of a SHA-256 digest, with or
+// without the "sha256/" prefix, and returns it with the prefix.
+func ParsePin(pin string) (string, error) {
+	digest := strings.TrimPrefix(pin, "sha256/")
+	b, err := base64.StdEncoding.DecodeString(digest)
+
+	if err != nil || len(b) != sha256.Size {
+		return "", fmt.Errorf("invalid pin %q: expected the base64 of a sha256 digest", pin)
+	}
+
+	return "sha256/" + digest, nil
+}
+
+// verifyPins returns a tls.Config.VerifyConnection function rejecting
+// connections to the hosts of pins, keyed by lower-case host name, unless a
+// certificate of a verified chain carries one of the host's pins. Pinning
+// the key of an intermediate or root certificate thus survives the renewal
+// of the server's own certificate. Connections without a verified chain,
+// such as with InsecureSkipVerify, fail. Hosts without pins are left alone.
+func verifyPins(pins map[string][]string) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		expected, ok := pins[strings.ToLower(state.ServerName)]
+
+		if !ok {
+			return nil
+		}
+
+		// Only the verified chains count: a server may present any
+		// certificate it likes besides those, including a pinned one.
+		for _, chain := range state.VerifiedChains {
+			for _, cert := range chain {
+				pin := SPKIPin(cert)
+
+				for _, e := range expected {
+					if e == pin {
+						return nil
+					}
+				}
+			}
+		}
+
+		return fmt.Errorf("%s: %w", state.ServerName, ErrPinMismatch)
+	}
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPins(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	pin := SPKIPin(server.Certificate())
+	other, _ := ParsePin("47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=")
+
+	tests := []struct {
+		description   string
+		pins          map[string][]string
+		expectedError error
+	}{
+		{
+			description: "it should accept a matching pin",
+			pins:        map[string][]string{"example.com": {other, pin}},
+		},
+		{
+			description:   "it should reject a certificate matching no pin",
+			pins:          map[string][]string{"example.com": {other}},
+			expectedError: ErrPinMismatch,
+		},
+		{
+			description: "it should leave other hosts alone",
+			pins:        map[string][]string{"rdap.example.com": {other}},
+		},
+	}
+
+	for i, test := range tests {
+		transport := NewTransport(TransportOptions{
+			Pins: test.pins,
+			// The test certificate is issued for example.com.
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+			},
+		})
+		transport.TLSClientConfig.RootCAs = server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+		resp, err := (&http.Client{Transport: transport}).Get("https://example.com/")
+
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		if !errors.Is(err, test.expectedError) || (err != nil) != (test.expectedError != nil) {
+			t.Fatalf("At index %d (%s): expected error %v, got %v", i, test.description, test.expectedError, err)
+		}
+	}
+
+	if _, err := ParsePin("sha256/short"); err == nil {
+		t.Fatal("expected an error for a malformed pin")
+	}
+}
+
+// newCertificate issues a certificate for name, signed by parent or self
+// signed when parent is nil.
+func newCertificate(t *testing.T, name string, isCA bool, parent *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	signer, signerKey := template, interface{}(key)
+
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestPinsIgnoreUnverifiedCertificates(t *testing.T) {
+	ca := newCertificate(t, "mitm", true, nil)
+	leaf := newCertificate(t, "example.com", false, &ca)
+	pinned := newCertificate(t, "example.com", false, nil)
+
+	// A server holding a trusted leaf appends the pinned certificate to the
+	// chain it presents.
+	leaf.Certificate = append(leaf.Certificate, pinned.Certificate[0])
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{leaf}}
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Leaf)
+
+	tests := []struct {
+		description   string
+		pin           string
+		expectedError error
+	}{
+		{
+			description:   "it should ignore presented certificates outside the verified chain",
+			pin:           SPKIPin(pinned.Leaf),
+			expectedError: ErrPinMismatch,
+		},
+		{
+			description: "it should accept the pin of the root of the verified chain",
+			pin:         SPKIPin(ca.Leaf),
+		},
+	}
+
+	for i, test := range tests {
+		transport := NewTransport(TransportOptions{
+			Pins: map[string][]string{"example.com": {test.pin}},
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+			},
+		})
+		transport.TLSClientConfig.RootCAs = roots
+
+		resp, err := (&http.Client{Transport: transport}).Get("https://example.com/")
+
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		if !errors.Is(err, test.expectedError) || (err != nil) != (test.expectedError != nil) {
+			t.Fatalf("At index %d (%s): expected error %v, got %v", i, test.description, test.expectedError, err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// QueryPlan is what Query would do for Target, as found by Plan.
+type QueryPlan struct {
+	Target   Target           `json:"target"`
+	Requests []PlannedRequest `json:"requests"`
+}
+
+// PlannedRequest is one request of a QueryPlan, to Server at URL with
+// Header. Skip, when set, tells why the server would be passed over instead.
+type PlannedRequest struct {
+	Server string      `json:"server"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+	Skip   string      `json:"skip,omitempty"`
+}
+
+// Plan matches target against the bootstrap registries and returns the
+// requests Query would send, in the order the servers would be tried,
+// without sending any. It is meant for debugging routing and for audit
+// trails, and fetches nothing but the bootstrap registries it needs.
+// Headers added by the HTTP client's transport, such as credentials,
+// aren't included.
+func (c *Client) Plan(ctx context.Context, target Target) (*QueryPlan, error) {
+	servers, path, err := c.targetServers(ctx, target)
+
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &QueryPlan{Target: target}
+
+	for _, server := range servers {
+		req, err := c.newRequest(ctx, server, path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		request := PlannedRequest{Server: server, URL: req.URL.String(), Header: req.Header}
+
+		if !c.Policy.Allowed(req.URL.Hostname()) {
+			request.Skip = ErrHostDenied.Error()
+		} else if _, ok := c.cooldowns().Until(req.URL.Hostname()); ok && !c.cooldowns().Wait {
+			request.Skip = c.cooldowns().check(ctx, req.URL.Hostname()).Error()
+		}
+
+		plan.Requests = append(plan.Requests, request)
+	}
+
+	return plan, nil
+}
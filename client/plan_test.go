@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/garslo/rdap-client/bootstrap"
+)
+
+func TestPlan(t *testing.T) {
+	var queries int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries++
+	}))
+	defer server.Close()
+
+	registries := &bootstrap.Registries{}
+	registries.Set(bootstrap.IPv6, bootstrap.ServiceRegistry{
+		Services: bootstrap.ServicesList{
+			{{"2001:db8::/32"}, {"https://rdap.example.net/", server.URL + "/rdap"}},
+		},
+	})
+	registries.Set(bootstrap.IPv4, bootstrap.ServiceRegistry{})
+
+	c := &Client{Bootstrap: registries, Language: "en", Policy: &HostPolicy{Deny: []string{"rdap.example.net"}}, Cooldowns: &Cooldowns{}}
+	c.Cooldowns.Record("127.0.0.1", time.Minute)
+
+	plan, err := c.Plan(context.Background(), Target{Type: TargetIP, Value: "2001:DB8::1"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := http.Header{"Accept": {"application/rdap+json"}, "Accept-Language": {"en"}, "User-Agent": {DefaultUserAgent}}
+
+	expected := []PlannedRequest{
+		{Server: "https://rdap.example.net/", URL: "https://rdap.example.net/ip/2001:db8::1", Header: header, Skip: ErrHostDenied.Error()},
+		{Server: server.URL + "/rdap", URL: server.URL + "/rdap/ip/2001:db8::1", Header: header},
+	}
+
+	if plan.Requests[1].Skip == "" {
+		t.Fatal("expected the cooling down server to be skipped")
+	}
+
+	plan.Requests[1].Skip = ""
+
+	if !reflect.DeepEqual(expected, plan.Requests) {
+		t.Fatalf("expected %+v, got %+v", expected, plan.Requests)
+	}
+
+	if queries != 0 {
+		t.Fatalf("expected no query, got %d", queries)
+	}
+
+	if _, err := c.Plan(context.Background(), Target{Type: TargetIP, Value: "192.0.2.1"}); err != ErrNoServer {
+		t.Fatalf("expected ErrNoServer, got %v", err)
+	}
+
+	// Entities go to their base, as with Query, even when Host is set.
+	c.Host = "https://rdap.example.com/"
+	plan, err = c.Plan(context.Background(), Target{Type: TargetEntity, Value: "ABC", Base: "https://rdap.example.org/"})
+
+	if err != nil || len(plan.Requests) != 1 || plan.Requests[0].URL != "https://rdap.example.org/entity/ABC" {
+		t.Fatalf("expected a single request to the base of the entity, got %+v (%v)", plan, err)
+	}
+}
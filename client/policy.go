@@ -0,0 +1,75 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var ErrHostDenied = errors.New("host denied by policy")
+
+// HostPolicy restricts the hosts a Client may contact. Its patterns are host
+// names or addresses, compared regardless of case, or "*." followed by a
+// domain to cover every host below that domain.
+type HostPolicy struct {
+	// Allow, when not empty, lists the only hosts that may be contacted.
+	Allow []string
+	// Deny lists hosts that may not be contacted, allowed or not.
+	Deny []string
+}
+
+// Allowed reports whether p lets host be contacted. A nil policy allows
+// every host.
+func (p *HostPolicy) Allowed(host string) bool {
+	if p == nil {
+		return true
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	if matchHost(p.Deny, host) {
+		return false
+	}
+
+	return len(p.Allow) == 0 || matchHost(p.Allow, host)
+}
+
+func matchHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSuffix(pattern, "."))
+
+		if pattern == host || strings.HasPrefix(pattern, "*.") && strings.HasSuffix(host, pattern[1:]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PolicyTransport fails the requests to hosts Policy denies with
+// ErrHostDenied instead of sending them. As an http.Client sends every
+// redirect through its transport, redirects to denied hosts fail too.
+type PolicyTransport struct {
+	Policy *HostPolicy
+	// Transport defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+func (t PolicyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	if !t.Policy.Allowed(req.URL.Hostname()) {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+
+		return nil, fmt.Errorf("%s: %w", req.URL.Hostname(), ErrHostDenied)
+	}
+
+	return transport.RoundTrip(req)
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/garslo/rdap-client/bootstrap"
+)
+
+func TestHostPolicy(t *testing.T) {
+	policy := &HostPolicy{Allow: []string{"*.example.com", "rdap.example.net"}, Deny: []string{"bad.example.com"}}
+
+	for host, expected := range map[string]bool{
+		"rdap.example.com":  true,
+		"RDAP.Example.COM.": true,
+		"example.com":       false,
+		"bad.example.com":   false,
+		"rdap.example.net":  true,
+		"www.example.net":   false,
+	} {
+		if policy.Allowed(host) != expected {
+			t.Fatalf("%s: expected allowed %t", host, expected)
+		}
+	}
+
+	if !(*HostPolicy)(nil).Allowed("example.org") {
+		t.Fatal("expected a nil policy to allow every host")
+	}
+}
+
+func TestClientPolicy(t *testing.T) {
+	var requests int
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	}))
+	defer target.Close()
+
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, strings.Replace(target.URL, "127.0.0.1", "localhost", 1)+r.URL.Path, http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	tests := []struct {
+		description      string
+		host             string
+		policy           *HostPolicy
+		expectedError    error
+		expectedRequests int
+	}{
+		{
+			description:      "it should query allowed hosts",
+			host:             target.URL,
+			policy:           &HostPolicy{Allow: []string{"127.0.0.1"}},
+			expectedRequests: 1,
+		},
+		{
+			description:   "it should not contact denied hosts",
+			host:          target.URL,
+			policy:        &HostPolicy{Deny: []string{"127.0.0.1"}},
+			expectedError: ErrHostDenied,
+		},
+		{
+			description:   "it should not follow redirects to denied hosts",
+			host:          redirect.URL,
+			policy:        &HostPolicy{Allow: []string{"127.0.0.1"}},
+			expectedError: ErrHostDenied,
+		},
+	}
+
+	for i, test := range tests {
+		requests = 0
+		c := &Client{Host: test.host, Bootstrap: &bootstrap.Registries{}, Policy: test.policy}
+
+		_, err := c.Domain(context.Background(), "example.com")
+
+		if !errors.Is(err, test.expectedError) || (err != nil) != (test.expectedError != nil) {
+			t.Fatalf("At index %d (%s): expected error %v, got %v", i, test.description, test.expectedError, err)
+		}
+
+		if requests != test.expectedRequests {
+			t.Fatalf("At index %d (%s): expected %d requests, got %d", i, test.description, test.expectedRequests, requests)
+		}
+	}
+}
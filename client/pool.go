@@ -0,0 +1,214 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/garslo/rdap-client/protocol"
+)
+
+const (
+	TargetDomain     = "domain"
+	TargetNameserver = "nameserver"
+	TargetIP         = "ip"
+	TargetAutnum     = "autnum"
+	TargetEntity     = "entity"
+)
+
+// Target is one query of QueryAll: Value is looked up as an object of Type.
+// Base is the server entity targets are looked up on.
+type Target struct {
+	Type  string
+	Value string
+	Base  string
+}
+
+// ParseTarget guesses the type of a query given on a command line or in a
+// file: addresses and CIDR blocks are ip targets, numbers with or without
+// an AS prefix are autnum targets and anything else is a domain.
+func ParseTarget(s string) Target {
+	s = strings.TrimSpace(s)
+
+	if net.ParseIP(s) != nil {
+		return Target{Type: TargetIP, Value: s}
+	}
+
+	if _, _, err := net.ParseCIDR(s); err == nil {
+		return Target{Type: TargetIP, Value: s}
+	}
+
+	if asn := strings.TrimPrefix(strings.ToUpper(s), "AS"); asn != "" {
+		if _, err := strconv.ParseUint(asn, 10, 32); err == nil {
+			return Target{Type: TargetAutnum, Value: asn}
+		}
+	}
+
+	return ipTarget(Target{Type: TargetDomain, Value: s})
+}
+
+// ipTarget turns a domain target naming a reverse DNS zone, such as
+// 2.0.192.in-addr.arpa, into the ip target of the network it covers, as the
+// dns registry lists no servers for those zones.
+func ipTarget(target Target) Target {
+	if target.Type != TargetDomain || !protocol.IsReverseName(target.Value) {
+		return target
+	}
+
+	network, err := protocol.ParseReverseName(target.Value)
+
+	if err != nil {
+		return target
+	}
+
+	return Target{Type: TargetIP, Value: network.String()}
+}
+
+// Result is the outcome of the target at Index in the targets passed to
+// QueryAll. Object is the *protocol.Domain, *protocol.Nameserver,
+// *protocol.IPNetwork, *protocol.Autnum or *protocol.Entity found, or the
+// *WhoisRecord of the whois fallback.
+type Result struct {
+	Index  int
+	Target Target
+	Object interface{}
+	Err    error
+}
+
+type PoolOptions struct {
+	// Concurrency bounds the queries in flight; it defaults to 8.
+	Concurrency int
+}
+
+// QueryAll looks every target up with at most opts.Concurrency queries in
+// flight, streaming results as they complete, in no particular order. A
+// target listed more than once is only queried once. Queries go through c,
+// so they share its HTTP caching, bootstrap registries and Limiter. The
+// channel is closed once every target has been reported or ctx is done.
+func (c *Client) QueryAll(ctx context.Context, targets []Target, opts PoolOptions) <-chan Result {
+	var (
+		results     = make(chan Result)
+		concurrency = opts.Concurrency
+		indexes     = map[Target][]int{}
+		unique      []Target
+		wg          sync.WaitGroup
+	)
+
+	if concurrency <= 0 {
+		concurrency = 8
+	}
+
+	for i, target := range targets {
+		if _, ok := indexes[target]; !ok {
+			unique = append(unique, target)
+		}
+
+		indexes[target] = append(indexes[target], i)
+	}
+
+	semaphore := make(chan struct{}, concurrency)
+
+	go func() {
+		defer close(results)
+
+	loop:
+		for _, target := range unique {
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				break loop
+			}
+
+			wg.Add(1)
+
+			go func(target Target) {
+				defer func() {
+					<-semaphore
+					wg.Done()
+				}()
+
+				object, err := c.Query(ctx, target)
+
+				for _, i := range indexes[target] {
+					select {
+					case results <- Result{Index: i, Target: target, Object: object, Err: err}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}(target)
+		}
+
+		wg.Wait()
+	}()
+
+	return results
+}
+
+// Query looks a single target up, returning an object as described for
+// Result, or a nil object with the error. With WhoisFallback set, domain,
+// ip and autnum targets without an RDAP server or object are looked up
+// with Whois; when that fails too, its error is added to the RDAP one.
+func (c *Client) Query(ctx context.Context, target Target) (interface{}, error) {
+	var (
+		object interface{}
+		err    error
+	)
+
+	target = ipTarget(target)
+
+	switch target.Type {
+	case TargetDomain:
+		object, err = c.Domain(ctx, target.Value)
+	case TargetNameserver:
+		object, err = c.Nameserver(ctx, target.Value)
+	case TargetIP:
+		object, err = c.queryIP(ctx, target.Value)
+	case TargetAutnum:
+		var asn uint64
+
+		if asn, err = strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(target.Value), "AS"), 10, 32); err == nil {
+			object, err = c.Autnum(ctx, uint32(asn))
+		}
+	case TargetEntity:
+		object, err = c.Entity(ctx, target.Base, target.Value)
+	default:
+		err = fmt.Errorf("unknown target type %q", target.Type)
+	}
+
+	if err != nil && c.WhoisFallback && (errors.Is(err, ErrNoServer) || errors.Is(err, ErrNotFound)) {
+		if query, ok := whoisQuery(target); ok {
+			record, whoisErr := c.Whois(ctx, query)
+
+			if whoisErr == nil {
+				return record, nil
+			}
+
+			err = fmt.Errorf("%w (whois fallback: %v)", err, whoisErr)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
+
+func (c *Client) queryIP(ctx context.Context, value string) (interface{}, error) {
+	if ip := net.ParseIP(value); ip != nil {
+		return c.IP(ctx, ip)
+	}
+
+	_, network, err := net.ParseCIDR(value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.IPNetwork(ctx, network)
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garslo/rdap-client/bootstrap"
+	"github.com/garslo/rdap-client/protocol"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected Target
+	}{
+		{query: "192.0.2.1", expected: Target{Type: TargetIP, Value: "192.0.2.1"}},
+		{query: "2001:db8::/32", expected: Target{Type: TargetIP, Value: "2001:db8::/32"}},
+		{query: "AS64500", expected: Target{Type: TargetAutnum, Value: "64500"}},
+		{query: "64500", expected: Target{Type: TargetAutnum, Value: "64500"}},
+		{query: " example.com ", expected: Target{Type: TargetDomain, Value: "example.com"}},
+		{query: "as", expected: Target{Type: TargetDomain, Value: "as"}},
+		{query: "2.0.192.IN-ADDR.ARPA.", expected: Target{Type: TargetIP, Value: "192.0.2.0/24"}},
+		{query: "8.b.d.0.1.0.0.2.ip6.arpa", expected: Target{Type: TargetIP, Value: "2001:db8::/32"}},
+		{query: "x.in-addr.arpa", expected: Target{Type: TargetDomain, Value: "x.in-addr.arpa"}},
+	}
+
+	for i, test := range tests {
+		if target := ParseTarget(test.query); target != test.expected {
+			t.Fatalf("At index %d (%s): expected %+v, got %+v", i, test.query, test.expected, target)
+		}
+	}
+}
+
+func TestQueryAll(t *testing.T) {
+	var (
+		mutex    sync.Mutex
+		inFlight int
+		peak     int
+		requests = map[string]int{}
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		inFlight++
+		requests[r.URL.Path]++
+
+		if inFlight > peak {
+			peak = inFlight
+		}
+
+		mutex.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mutex.Lock()
+		inFlight--
+		mutex.Unlock()
+
+		switch r.URL.Path {
+		case "/domain/example.com":
+			w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+		case "/autnum/64500":
+			w.Write([]byte(`{"objectClassName": "autnum", "handle": "AS64500"}`))
+		case "/ip/192.0.2.1":
+			w.Write([]byte(`{"objectClassName": "ip network", "handle": "NET-1"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{Host: server.URL, Bootstrap: &bootstrap.Registries{}}
+	targets := []Target{
+		ParseTarget("example.com"),
+		ParseTarget("AS64500"),
+		ParseTarget("192.0.2.1"),
+		ParseTarget("example.com"),
+		ParseTarget("missing.com"),
+		{Type: "help"},
+	}
+
+	var report []string
+
+	for result := range client.QueryAll(context.Background(), targets, PoolOptions{Concurrency: 2}) {
+		var name string
+
+		switch object := result.Object.(type) {
+		case *protocol.Domain:
+			name = object.LDHName
+		case *protocol.Autnum:
+			name = object.Handle
+		case *protocol.IPNetwork:
+			name = object.Handle
+		}
+
+		report = append(report, fmt.Sprintf("%d %s %v", result.Index, name, result.Err))
+	}
+
+	sort.Strings(report)
+
+	expected := []string{
+		"0 example.com <nil>",
+		"1 AS64500 <nil>",
+		"2 NET-1 <nil>",
+		"3 example.com <nil>",
+		"4  rdap object not found",
+		`5  unknown target type "help"`,
+	}
+
+	if fmt.Sprint(expected) != fmt.Sprint(report) {
+		t.Fatalf("expected %q, got %q", expected, report)
+	}
+
+	if requests["/domain/example.com"] != 1 {
+		t.Fatalf("expected duplicate targets to be queried once, got %v", requests)
+	}
+
+	if peak > 2 {
+		t.Fatalf("expected at most 2 queries in flight, got %d", peak)
+	}
+}
+
+func TestReverseNames(t *testing.T) {
+	var paths []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+
+		if strings.HasPrefix(r.URL.Path, "/ip/") {
+			w.Write([]byte(`{"objectClassName": "ip network", "handle": "NET-192-0-2-0-1"}`))
+		} else {
+			w.Write([]byte(`{"objectClassName": "domain", "ldhName": "2.0.192.in-addr.arpa"}`))
+		}
+	}))
+	defer server.Close()
+
+	registries := &bootstrap.Registries{}
+	registries.Set(bootstrap.DNS, bootstrap.ServiceRegistry{})
+	registries.Set(bootstrap.IPv4, bootstrap.ServiceRegistry{
+		Services: bootstrap.ServicesList{{{"192.0.0.0/8"}, {server.URL + "/"}}},
+	})
+
+	c := &Client{Bootstrap: registries}
+	ctx := context.Background()
+
+	for _, target := range []Target{ParseTarget("2.0.192.in-addr.arpa"), {Type: TargetDomain, Value: "2.0.192.in-addr.arpa."}} {
+		object, err := c.Query(ctx, target)
+
+		if _, ok := object.(*protocol.IPNetwork); err != nil || !ok {
+			t.Fatalf("%+v: expected an ip network, got %T (%v)", target, object, err)
+		}
+	}
+
+	if _, err := c.Domain(ctx, "2.0.192.in-addr.arpa"); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := c.Plan(ctx, Target{Type: TargetDomain, Value: "2.0.192.in-addr.arpa"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"/ip/192.0.2.0/24", "/ip/192.0.2.0/24", "/domain/2.0.192.in-addr.arpa"}
+
+	if fmt.Sprint(expected) != fmt.Sprint(paths) || plan.Requests[0].URL != server.URL+"/ip/192.0.2.0/24" {
+		t.Fatalf("expected %q and a planned ip query, got %q and %+v", expected, paths, plan.Requests)
+	}
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/garslo/rdap-client/protocol"
+)
+
+// Proxy is an RDAP server answering every query with the response of the
+// authoritative server, located through bootstrap like any other query of
+// Client. Serving it lets a team or a toolchain share one egress point,
+// with the HTTP caching, Limiter and Cooldowns of Client applied to all of
+// them:
+//
+//	http.ListenAndServe(":8080", &client.Proxy{Client: c})
+//
+// Mount it below a path prefix with http.StripPrefix. Entity lookups are
+// answered with 501 Not Implemented unless Client.Host is set, since
+// entities have no bootstrap registry.
+type Proxy struct {
+	Client *Client
+}
+
+type rawObject interface {
+	Raw() json.RawMessage
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	ctx := r.Context()
+	segment, value, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+
+	var (
+		object interface{}
+		err    error
+	)
+
+	switch segment {
+	case TargetDomain, TargetNameserver, TargetIP, TargetAutnum:
+		object, err = p.Client.Query(ctx, Target{Type: segment, Value: value})
+	case TargetEntity:
+		if p.Client.Host == "" {
+			writeError(w, http.StatusNotImplemented, "Not Implemented")
+			return
+		}
+
+		object, err = p.Client.Entity(ctx, p.Client.Host, value)
+	case "domains":
+		object, err = p.Client.SearchDomains(ctx, r.URL.Query().Get("name"), SearchOptions{})
+	case "nameservers":
+		object, err = p.Client.SearchNameservers(ctx, r.URL.Query().Get("name"), SearchOptions{})
+	case "help":
+		writeObject(w, http.StatusOK, map[string]interface{}{
+			"rdapConformance": []string{"rdap_level_0"},
+			"notices": []protocol.Notice{{
+				Title:       "About",
+				Description: []string{"This server forwards RDAP queries to the authoritative servers."},
+			}},
+		})
+
+		return
+	default:
+		writeError(w, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	var statusErr *StatusError
+
+	switch {
+	case errors.As(err, &statusErr) && statusErr.Response != nil:
+		writeObject(w, statusErr.StatusCode, statusErr.Response)
+	case errors.As(err, &statusErr):
+		writeError(w, statusErr.StatusCode, http.StatusText(statusErr.StatusCode))
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrNoServer):
+		writeError(w, http.StatusNotFound, "Not Found")
+	case errors.Is(err, ErrRateLimited):
+		writeError(w, http.StatusTooManyRequests, "Too Many Requests")
+	case err != nil:
+		writeError(w, http.StatusBadGateway, "Bad Gateway", err.Error())
+	default:
+		raw, ok := object.(rawObject)
+
+		if !ok {
+			// A whois fallback answer is no RDAP object.
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+
+		w.Header().Set("Content-Type", protocol.MediaTypeRDAP)
+		w.Write(raw.Raw())
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, title string, description ...string) {
+	writeObject(w, status, &protocol.Error{
+		RDAPConformance: []string{"rdap_level_0"},
+		ErrorCode:       status,
+		Title:           title,
+		Description:     description,
+	})
+}
+
+func writeObject(w http.ResponseWriter, status int, v interface{}) {
+	b, _ := json.Marshal(v)
+
+	w.Header().Set("Content-Type", protocol.MediaTypeRDAP)
+	w.WriteHeader(status)
+	w.Write(b)
+}
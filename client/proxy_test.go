@@ -0,0 +1,87 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/garslo/rdap-client/bootstrap"
+)
+
+func TestProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/domain/example.com":
+			w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com", "x_custom": true}`))
+		case "/domain/gone.com":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errorCode": 404, "title": "Gone", "description": ["Deleted."]}`))
+		case "/entity/ABC-1":
+			w.Write([]byte(`{"objectClassName": "entity", "handle": "ABC-1"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer upstream.Close()
+
+	proxy := httptest.NewServer(&Proxy{Client: &Client{Host: upstream.URL, Bootstrap: &bootstrap.Registries{}}})
+	defer proxy.Close()
+
+	tests := []struct {
+		description    string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			description:    "it should forward the upstream object untouched",
+			path:           "/domain/example.com",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"objectClassName": "domain", "ldhName": "example.com", "x_custom": true}`,
+		},
+		{
+			description:    "it should forward upstream error objects",
+			path:           "/domain/gone.com",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"errorCode":404,"title":"Gone","description":["Deleted."]}`,
+		},
+		{
+			description:    "it should look entities up on the fixed host",
+			path:           "/entity/ABC-1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"objectClassName": "entity", "handle": "ABC-1"}`,
+		},
+		{
+			description:    "it should report upstream failures with error objects",
+			path:           "/autnum/64500",
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"rdapConformance":["rdap_level_0"],"errorCode":500,"title":"Internal Server Error"}`,
+		},
+		{
+			description:    "it should reject unknown paths",
+			path:           "/registrar/1",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"rdapConformance":["rdap_level_0"],"errorCode":400,"title":"Bad Request"}`,
+		},
+	}
+
+	for i, test := range tests {
+		resp, err := http.Get(proxy.URL + test.path)
+
+		if err != nil {
+			t.Fatalf("At index %d (%s): unexpected error %s", i, test.description, err)
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != test.expectedStatus || string(body) != test.expectedBody {
+			t.Fatalf("At index %d (%s): expected %d %s, got %d %s", i, test.description, test.expectedStatus, test.expectedBody, resp.StatusCode, body)
+		}
+
+		if contentType := resp.Header.Get("Content-Type"); contentType != "application/rdap+json" {
+			t.Fatalf("At index %d (%s): expected an rdap content type, got %q", i, test.description, contentType)
+		}
+	}
+}
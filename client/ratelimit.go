@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: Rate requests per second on average, with up to
+// Burst requests sent back to back after a quiet period.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+var (
+	// DefaultLimit applies to hosts without a limit of their own.
+	DefaultLimit = Limit{Rate: 5, Burst: 10}
+	// Unlimited never blocks. As the Default of a RateLimiter, it leaves
+	// the hosts without a limit of their own alone.
+	Unlimited = Limit{Rate: -1}
+	// DefaultHostLimits are conservative limits for the RIR servers known
+	// to block clients that query them in bulk.
+	DefaultHostLimits = map[string]Limit{
+		"rdap.afrinic.net": {Rate: 1, Burst: 3},
+		"rdap.apnic.net":   {Rate: 2, Burst: 5},
+		"rdap.arin.net":    {Rate: 2, Burst: 5},
+		"rdap.db.ripe.net": {Rate: 4, Burst: 8},
+		"rdap.lacnic.net":  {Rate: 0.2, Burst: 2},
+	}
+)
+
+// RateLimiter paces requests with one token bucket per host name. The zero
+// value uses DefaultHostLimits and DefaultLimit.
+type RateLimiter struct {
+	// Default replaces DefaultLimit when its Rate is set.
+	Default Limit
+	// Hosts takes precedence over DefaultHostLimits.
+	Hosts map[string]Limit
+
+	mutex   sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	limit  Limit
+	tokens float64
+	last   time.Time
+}
+
+func (l *RateLimiter) limit(host string) Limit {
+	if limit, ok := l.Hosts[host]; ok {
+		return limit
+	}
+
+	if limit, ok := DefaultHostLimits[host]; ok {
+		return limit
+	}
+
+	if l.Default.Rate != 0 {
+		return l.Default
+	}
+
+	return DefaultLimit
+}
+
+// Wait blocks until a request to host may be sent, or returns ctx.Err() if
+// ctx is done first. A limit with a Rate of zero or less never blocks.
+func (l *RateLimiter) Wait(ctx context.Context, host string) error {
+	host = strings.ToLower(host)
+
+	l.mutex.Lock()
+
+	if l.buckets == nil {
+		l.buckets = map[string]*bucket{}
+	}
+
+	b, ok := l.buckets[host]
+
+	if !ok {
+		limit := l.limit(host)
+		b = &bucket{limit: limit, tokens: float64(limit.Burst), last: time.Now()}
+		l.buckets[host] = b
+	}
+
+	if b.limit.Rate <= 0 {
+		l.mutex.Unlock()
+		return nil
+	}
+
+	now := time.Now()
+	b.tokens = math.Min(float64(b.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate)
+	b.last = now
+	b.tokens--
+	wait := time.Duration(-b.tokens / b.limit.Rate * float64(time.Second))
+
+	l.mutex.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	if !sleep(ctx, wait) {
+		l.mutex.Lock()
+		b.tokens++
+		l.mutex.Unlock()
+
+		return ctx.Err()
+	}
+
+	return nil
+}
+
+// RateLimitTransport is an http.RoundTripper that waits for Limiter before
+// passing each request on to Transport, so that everything sent through it,
+// bootstrap fetches included, is paced per host.
+type RateLimitTransport struct {
+	Limiter   *RateLimiter
+	Transport http.RoundTripper
+}
+
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.Limiter.Wait(req.Context(), req.URL.Hostname()); err != nil {
+		return nil, err
+	}
+
+	transport := t.Transport
+
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return transport.RoundTrip(req)
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := &RateLimiter{
+		Hosts: map[string]Limit{
+			"rdap.example.com": {Rate: 50, Burst: 2},
+			"rdap.example.net": {},
+		},
+	}
+
+	ctx := context.Background()
+	start := time.Now()
+
+	for i := 0; i < 4; i++ {
+		if err := limiter.Wait(ctx, "RDAP.example.com"); err != nil {
+			t.Fatal(err)
+		}
+
+		if elapsed := time.Since(start); i < 2 && elapsed > 10*time.Millisecond {
+			t.Fatalf("expected the first %d requests to be sent at once, waited %s", i+1, elapsed)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("expected 2 requests beyond the burst to take at least 40ms, took %s", elapsed)
+	}
+
+	start = time.Now()
+
+	for i := 0; i < 100; i++ {
+		limiter.Wait(ctx, "rdap.example.net")
+	}
+
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Fatalf("expected a zero limit not to block, took %s", elapsed)
+	}
+
+	if limit := limiter.limit("rdap.lacnic.net"); limit != DefaultHostLimits["rdap.lacnic.net"] {
+		t.Fatalf("expected the default limit for lacnic, got %+v", limit)
+	}
+
+	cancelled, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+
+	limiter.Hosts["rdap.example.org"] = Limit{Rate: 0.01, Burst: 0}
+
+	if err := limiter.Wait(cancelled, "rdap.example.org"); err != context.DeadlineExceeded {
+		t.Fatalf("expected the wait to end with the context, got %v", err)
+	}
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &RateLimitTransport{
+			Limiter: &RateLimiter{Default: Limit{Rate: 100, Burst: 1}},
+		},
+	}
+
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp.Body.Close()
+	}
+
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Fatalf("expected requests to be paced at 100/s, took %s", elapsed)
+	}
+}
+
+func TestDefaultLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rdap+json")
+		w.Write([]byte(`{"objectClassName": "autnum", "handle": "AS64496"}`))
+	}))
+	defer server.Close()
+
+	// Every request goes to server, whatever the host it is meant for.
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req.URL.Scheme, req.URL.Host = "http", server.Listener.Addr().String()
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	tests := []struct {
+		description   string
+		noLimiter     bool
+		expectedError error
+	}{
+		{
+			description:   "it should pace queries to an rir beyond its burst",
+			expectedError: context.DeadlineExceeded,
+		},
+		{
+			description: "it should not pace queries without the default limiter",
+			noLimiter:   true,
+		},
+	}
+
+	for i, test := range tests {
+		c := &Client{
+			HTTP:      &http.Client{Transport: transport},
+			Host:      "https://rdap.lacnic.net/rdap/",
+			NoLimiter: test.noLimiter,
+		}
+
+		var err error
+
+		for n := 0; n <= DefaultHostLimits["rdap.lacnic.net"].Burst && err == nil; n++ {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			_, err = c.Autnum(ctx, 64496)
+			cancel()
+		}
+
+		if !errors.Is(err, test.expectedError) || (err != nil) != (test.expectedError != nil) {
+			t.Fatalf("At index %d (%s): expected error %v, got %v", i, test.description, test.expectedError, err)
+		}
+
+		if _, limited := c.registries().Client.Transport.(*RateLimitTransport); limited == test.noLimiter {
+			t.Fatalf("At index %d (%s): expected bootstrap fetches to be paced %t, got %t", i, test.description, !test.noLimiter, limited)
+		}
+	}
+}
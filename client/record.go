@@ -0,0 +1,72 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"time"
+)
+
+// RecordingTransport saves every response it passes through to Dir, along
+// with its URL, status, headers and the time it was received, in the format
+// ReplayTransport reads back.
+type RecordingTransport struct {
+	Dir       string
+	Transport http.RoundTripper
+}
+
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	resp, err := transport.RoundTrip(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := &storedResponse{
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+		Stored:     time.Now(),
+	}
+
+	if err := writeStoredResponse(filepath.Join(t.Dir, CacheKey(req.URL)), recorded); err != nil {
+		return nil, err
+	}
+
+	return recorded.response(req), nil
+}
+
+// ReplayTransport answers requests from responses saved by
+// RecordingTransport without touching the network.
+type ReplayTransport struct {
+	Dir string
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	recorded, err := readStoredResponse(filepath.Join(t.Dir, CacheKey(req.URL)))
+
+	if err != nil {
+		return nil, fmt.Errorf("no recorded response for %s", req.URL)
+	}
+
+	return recorded.response(req), nil
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rdap+json")
+
+		if r.URL.Path != "/domain/example.com" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+
+		fmt.Fprintf(w, `{"path": %q}`, r.URL.Path)
+	}))
+
+	dir, err := os.MkdirTemp("", "rdap-record")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	paths := []string{"/domain/example.com", "/domain/missing.com"}
+	recorder := &http.Client{Transport: &RecordingTransport{Dir: dir}}
+
+	for _, path := range paths {
+		resp, err := recorder.Get(server.URL + path)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp.Body.Close()
+	}
+
+	server.Close()
+
+	tests := []struct {
+		description  string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			description:  "it should replay a recorded response",
+			path:         "/domain/example.com",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"path": "/domain/example.com"}`,
+		},
+		{
+			description:  "it should replay recorded error responses",
+			path:         "/domain/missing.com",
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"path": "/domain/missing.com"}`,
+		},
+	}
+
+	replayer := &http.Client{Transport: &ReplayTransport{Dir: dir}}
+
+	for i, test := range tests {
+		resp, err := replayer.Get(server.URL + test.path)
+
+		if err != nil {
+			t.Fatalf("At index %d (%s): unexpected error %s", i, test.description, err)
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != test.expectedCode || string(body) != test.expectedBody || resp.Header.Get("Content-Type") != "application/rdap+json" {
+			t.Fatalf("At index %d (%s): expected %d %s, got %d %s", i, test.description, test.expectedCode, test.expectedBody, resp.StatusCode, body)
+		}
+	}
+
+	if _, err := replayer.Get(server.URL + "/domain/other.com"); err == nil {
+		t.Fatalf("expected an error for a query that was never recorded")
+	}
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/garslo/rdap-client/protocol"
+)
+
+// RelatedObject is an RDAP object reached through a rel="related" link, such
+// as the registrar's record of a domain, labeled with the link followed, the
+// server that answered and how many links away from the first response it
+// is. Object is nil and Err set when the fetch failed.
+type RelatedObject struct {
+	Link   protocol.Link
+	Server string
+	Depth  int
+	Object json.RawMessage
+	Err    error
+}
+
+// FollowRelated fetches the objects the related links among links point at,
+// then the objects their own related links point at, down to depth links
+// away; a depth below one follows one level. Only https links are
+// followed, so a thick record is never fetched in clear text, and each URL
+// is fetched once, however many links lead to it, so link cycles end. The
+// self links among links count as fetched already.
+//
+// Fetches go through the Policy, Limiter and Cooldowns of the client like
+// any query. Objects are returned nearest first, in link order, and the
+// walk stops early, with the objects fetched so far, when ctx is done.
+func (c *Client) FollowRelated(ctx context.Context, links []protocol.Link, depth int) []RelatedObject {
+	if depth < 1 {
+		depth = 1
+	}
+
+	var (
+		related []RelatedObject
+		visited = map[string]bool{}
+	)
+
+	for _, link := range links {
+		if u, err := url.Parse(link.Href); err == nil && strings.EqualFold(link.Rel, "self") {
+			visited[u.String()] = true
+		}
+	}
+
+	for level := 1; level <= depth && len(links) > 0; level++ {
+		var next []protocol.Link
+
+		for _, link := range protocol.RelatedLinks(links) {
+			if ctx.Err() != nil {
+				return related
+			}
+
+			u, err := url.Parse(link.Href)
+
+			if err != nil || u.Scheme != "https" || visited[u.String()] {
+				continue
+			}
+
+			visited[u.String()] = true
+
+			var (
+				object json.RawMessage
+				linked struct {
+					Links []protocol.Link `json:"links"`
+				}
+			)
+
+			server, err := c.query(ctx, []string{u.String()}, "", &object)
+
+			if err == nil {
+				err = json.Unmarshal(object, &linked)
+			}
+
+			if err != nil {
+				related = append(related, RelatedObject{Link: link, Server: server, Depth: level, Err: err})
+				continue
+			}
+
+			related = append(related, RelatedObject{Link: link, Server: server, Depth: level, Object: object})
+			next = append(next, linked.Links...)
+		}
+
+		links = next
+	}
+
+	return related
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/garslo/rdap-client/protocol"
+)
+
+func TestFollowRelated(t *testing.T) {
+	var registry, registrar *httptest.Server
+
+	requests := map[string]int{}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests[r.Host+r.URL.Path]++
+
+		switch r.URL.Path {
+		case "/registry/domain/example.com":
+			fmt.Fprintf(w, `{"objectClassName": "domain", "ldhName": "example.com", "links": [{"rel": "related", "type": "application/rdap+json", "href": %q}]}`, registrar.URL+"/registrar/domain/example.com")
+		case "/registrar/domain/example.com":
+			fmt.Fprintf(w, `{"objectClassName": "domain", "ldhName": "example.com", "links": [
+				{"rel": "related", "href": %q},
+				{"rel": "related", "href": %q},
+				{"rel": "related", "href": "http://insecure.example/domain/example.com"}
+			]}`, registry.URL+"/registry/domain/example.com", registrar.URL+"/registrar/entity/GONE")
+		default:
+			http.NotFound(w, r)
+		}
+	}
+
+	registry = httptest.NewTLSServer(http.HandlerFunc(handler))
+	defer registry.Close()
+
+	registrar = httptest.NewTLSServer(http.HandlerFunc(handler))
+	defer registrar.Close()
+
+	client := &Client{HTTP: registry.Client()}
+	links := []protocol.Link{
+		{Rel: "self", Href: registry.URL + "/registry/domain/example.com"},
+		{Rel: "related", Href: registrar.URL + "/registrar/domain/example.com"},
+	}
+
+	tests := []struct {
+		description string
+		depth       int
+		expected    []string
+	}{
+		{
+			description: "one level",
+			expected: []string{
+				"1 " + registrar.URL + "/registrar/domain/example.com <nil>",
+			},
+		},
+		{
+			description: "not back to the first object, nor over plain http",
+			depth:       3,
+			expected: []string{
+				"1 " + registrar.URL + "/registrar/domain/example.com <nil>",
+				"2 " + registrar.URL + "/registrar/entity/GONE rdap object not found",
+			},
+		},
+	}
+
+	for i, test := range tests {
+		var report []string
+
+		for _, object := range client.FollowRelated(context.Background(), links, test.depth) {
+			if object.Server != object.Link.Href || (object.Err == nil) == (object.Object == nil) {
+				t.Fatalf("At index %d (%s): unexpected object %+v", i, test.description, object)
+			}
+
+			report = append(report, fmt.Sprintf("%d %s %v", object.Depth, object.Server, object.Err))
+		}
+
+		if fmt.Sprint(test.expected) != fmt.Sprint(report) {
+			t.Fatalf("At index %d (%s): expected %q, got %q", i, test.description, test.expected, report)
+		}
+	}
+
+	if n := requests[registry.Listener.Addr().String()+"/registry/domain/example.com"]; n != 0 {
+		t.Fatalf("expected the first object not to be fetched again, got %d requests", n)
+	}
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Categories of ErrorReport.
+const (
+	CategoryNotFound     = "not_found"
+	CategoryNoServer     = "no_server"
+	CategoryRateLimited  = "rate_limited"
+	CategoryServerError  = "server_error"
+	CategoryClientError  = "client_error"
+	CategoryBadResponse  = "bad_response"
+	CategoryDenied       = "denied"
+	CategoryTLS          = "tls"
+	CategoryTimeout      = "timeout"
+	CategoryCanceled     = "canceled"
+	CategoryNetwork      = "network"
+	CategoryInvalidInput = "invalid_input"
+	CategoryOther        = "other"
+)
+
+// ErrorReport describes a failed query for orchestration systems to act on
+// without parsing error messages. Code is the HTTP status of the answer, or
+// the error code of the RDAP error object in it, when a server answered.
+// RetryAfter is in seconds.
+type ErrorReport struct {
+	Code       int    `json:"code,omitempty"`
+	Category   string `json:"category"`
+	Message    string `json:"message"`
+	Type       string `json:"type,omitempty"`
+	Target     string `json:"target,omitempty"`
+	Server     string `json:"server,omitempty"`
+	Retryable  bool   `json:"retryable"`
+	RetryAfter int    `json:"retry_after,omitempty"`
+}
+
+// NewErrorReport classifies err, returned by a query of target. Rate
+// limits, timeouts, network failures and 5xx answers are retryable.
+func NewErrorReport(err error, target Target) ErrorReport {
+	report := ErrorReport{Message: err.Error(), Type: target.Type, Target: target.Value}
+
+	var (
+		statusErr      *StatusError
+		contentTypeErr *ContentTypeError
+		cooldownErr    *CooldownError
+		urlErr         *url.Error
+		netErr         net.Error
+		syntaxErr      *json.SyntaxError
+		typeErr        *json.UnmarshalTypeError
+		certErr        x509.UnknownAuthorityError
+		hostnameErr    x509.HostnameError
+		invalidErr     x509.CertificateInvalidError
+		parseErr       *net.ParseError
+		numErr         *strconv.NumError
+	)
+
+	if errors.As(err, &urlErr) {
+		if u, err := url.Parse(urlErr.URL); err == nil {
+			report.Server = u.Scheme + "://" + u.Host
+		}
+	}
+
+	switch {
+	case errors.As(err, &statusErr):
+		report.Code, report.Server = statusErr.StatusCode, statusErr.Server
+		report.RetryAfter = seconds(statusErr.RetryAfter)
+
+		if statusErr.Response != nil && statusErr.Response.ErrorCode != 0 {
+			report.Code = statusErr.Response.ErrorCode
+		}
+
+		switch {
+		case statusErr.StatusCode == 404:
+			report.Category = CategoryNotFound
+		case statusErr.StatusCode == 429:
+			report.Category, report.Retryable = CategoryRateLimited, true
+		case statusErr.StatusCode >= 500:
+			report.Category, report.Retryable = CategoryServerError, true
+		default:
+			report.Category = CategoryClientError
+		}
+	case errors.As(err, &cooldownErr):
+		report.Category, report.Retryable = CategoryRateLimited, true
+		report.RetryAfter = seconds(time.Until(cooldownErr.Until))
+	case errors.Is(err, ErrRateLimited):
+		report.Category, report.Retryable = CategoryRateLimited, true
+	case errors.Is(err, ErrNotFound):
+		report.Category = CategoryNotFound
+	case errors.Is(err, ErrNoServer):
+		report.Category = CategoryNoServer
+	case errors.Is(err, ErrHostDenied):
+		report.Category = CategoryDenied
+	case errors.Is(err, ErrPinMismatch), errors.As(err, &certErr), errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		report.Category = CategoryTLS
+	case errors.As(err, &contentTypeErr):
+		report.Category, report.Server = CategoryBadResponse, contentTypeErr.Server
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		report.Category = CategoryBadResponse
+	case errors.Is(err, context.Canceled):
+		report.Category = CategoryCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		report.Category, report.Retryable = CategoryTimeout, true
+	case errors.As(err, &parseErr), errors.As(err, &numErr):
+		report.Category = CategoryInvalidInput
+	case urlErr != nil, errors.As(err, &netErr):
+		report.Category, report.Retryable = CategoryNetwork, true
+	default:
+		report.Category = CategoryOther
+	}
+
+	return report
+}
+
+// seconds rounds d up to whole seconds, as Retry-After counts them.
+func seconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+
+	return int((d + time.Second - 1) / time.Second)
+}
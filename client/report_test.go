@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/garslo/rdap-client/protocol"
+)
+
+func TestNewErrorReport(t *testing.T) {
+	target := Target{Type: TargetDomain, Value: "example.com"}
+
+	tests := []struct {
+		description string
+		err         error
+		expected    ErrorReport
+	}{
+		{
+			description: "it should report a retryable server error",
+			err:         &StatusError{Server: "https://rdap.example.com/", StatusCode: 503, RetryAfter: 30 * time.Second},
+			expected:    ErrorReport{Code: 503, Category: CategoryServerError, Server: "https://rdap.example.com/", Retryable: true, RetryAfter: 30},
+		},
+		{
+			description: "it should prefer the code of an rdap error object",
+			err:         &StatusError{Server: "https://rdap.example.com/", StatusCode: 404, Response: &protocol.Error{ErrorCode: 404, Title: "Not Found"}},
+			expected:    ErrorReport{Code: 404, Category: CategoryNotFound, Server: "https://rdap.example.com/"},
+		},
+		{
+			description: "it should report how long a cooldown lasts",
+			err:         &CooldownError{Host: "rdap.example.com", Until: time.Now().Add(10 * time.Second)},
+			expected:    ErrorReport{Category: CategoryRateLimited, Retryable: true, RetryAfter: 10},
+		},
+		{
+			description: "it should report the server of a transport error",
+			err:         &url.Error{Op: "Get", URL: "https://rdap.example.com/domain/example.com", Err: fmt.Errorf("rdap.example.com: %w", ErrHostDenied)},
+			expected:    ErrorReport{Category: CategoryDenied, Server: "https://rdap.example.com"},
+		},
+		{
+			description: "it should report timeouts as retryable",
+			err:         fmt.Errorf("bootstrap dns: %w", context.DeadlineExceeded),
+			expected:    ErrorReport{Category: CategoryTimeout, Retryable: true},
+		},
+		{
+			description: "it should report responses that aren't rdap",
+			err:         &ContentTypeError{Server: "https://rdap.example.com/", ContentType: "text/html"},
+			expected:    ErrorReport{Category: CategoryBadResponse, Server: "https://rdap.example.com/"},
+		},
+		{
+			description: "it should report malformed input",
+			err:         &strconv.NumError{Func: "ParseUint", Num: "x", Err: strconv.ErrSyntax},
+			expected:    ErrorReport{Category: CategoryInvalidInput},
+		},
+		{
+			description: "it should report missing servers",
+			err:         ErrNoServer,
+			expected:    ErrorReport{Category: CategoryNoServer},
+		},
+	}
+
+	for i, test := range tests {
+		report := NewErrorReport(test.err, target)
+		test.expected.Message, test.expected.Type, test.expected.Target = test.err.Error(), target.Type, target.Value
+
+		if report != test.expected {
+			t.Fatalf("At index %d (%s): expected %+v, got %+v", i, test.description, test.expected, report)
+		}
+	}
+
+	b, _ := json.Marshal(NewErrorReport(ErrNoServer, target))
+
+	if expected := `{"category":"no_server","message":"no rdap server found","type":"domain","target":"example.com","retryable":false}`; string(b) != expected {
+		t.Fatalf("expected %s, got %s", expected, b)
+	}
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+const mediaTypeDNSMessage = "application/dns-message"
+
+// NameserverResolver returns a resolver that sends every DNS query to the
+// nameserver at address, such as "192.0.2.53:53", instead of the ones the
+// system is configured with.
+func NameserverResolver(address string) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+}
+
+// DoHResolver returns a resolver that sends every DNS query to the
+// DNS-over-HTTPS endpoint at url, as in RFC 8484. client, which defaults to
+// http.DefaultClient, must be able to reach url without this resolver.
+func DoHResolver(url string, client *http.Client) *net.Resolver {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return &dohConn{ctx: ctx, url: url, client: client}, nil
+		},
+	}
+}
+
+// dohConn carries the length-prefixed DNS messages the resolver exchanges
+// over a stream connection, posting each query to a DoH endpoint as it is
+// written and queueing the answer for reading.
+type dohConn struct {
+	ctx    context.Context
+	url    string
+	client *http.Client
+
+	written  bytes.Buffer
+	answers  bytes.Buffer
+	deadline time.Time
+}
+
+func (c *dohConn) Write(b []byte) (int, error) {
+	c.written.Write(b)
+
+	for c.written.Len() >= 2 {
+		size := int(binary.BigEndian.Uint16(c.written.Bytes()))
+
+		if c.written.Len() < 2+size {
+			break
+		}
+
+		c.written.Next(2)
+
+		answer, err := c.exchange(c.written.Next(size))
+
+		if err != nil {
+			return 0, err
+		}
+
+		binary.Write(&c.answers, binary.BigEndian, uint16(len(answer)))
+		c.answers.Write(answer)
+	}
+
+	return len(b), nil
+}
+
+func (c *dohConn) exchange(query []byte) ([]byte, error) {
+	ctx := c.ctx
+
+	if !c.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, c.deadline)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(query))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", mediaTypeDNSMessage)
+	req.Header.Set("Accept", mediaTypeDNSMessage)
+
+	resp, err := c.client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, c.url)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 65535))
+}
+
+func (c *dohConn) Read(b []byte) (int, error) {
+	if c.answers.Len() == 0 {
+		return 0, io.EOF
+	}
+
+	return c.answers.Read(b)
+}
+
+func (c *dohConn) Close() error                       { return nil }
+func (c *dohConn) LocalAddr() net.Addr                { return dohAddr(c.url) }
+func (c *dohConn) RemoteAddr() net.Addr               { return dohAddr(c.url) }
+func (c *dohConn) SetDeadline(t time.Time) error      { c.deadline = t; return nil }
+func (c *dohConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *dohConn) SetWriteDeadline(t time.Time) error { c.deadline = t; return nil }
+
+type dohAddr string
+
+func (a dohAddr) Network() string { return "https" }
+func (a dohAddr) String() string  { return string(a) }
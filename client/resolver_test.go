@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// dohHandler answers A queries for any name with 127.0.0.1 and every other
+// query with no records.
+func dohHandler(t *testing.T, queries *int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != mediaTypeDNSMessage {
+			t.Errorf("expected a POSTed dns message, got %s %q", r.Method, r.Header.Get("Content-Type"))
+		}
+
+		query, _ := io.ReadAll(r.Body)
+		*queries++
+
+		end := 12
+
+		for end < len(query) && query[end] != 0 {
+			end += int(query[end]) + 1
+		}
+
+		end += 5
+		qtype := binary.BigEndian.Uint16(query[end-4:])
+
+		answer := append([]byte{}, query[:end]...)
+		answer[2], answer[3] = 0x81, 0x80
+		binary.BigEndian.PutUint16(answer[6:], 0)
+		binary.BigEndian.PutUint16(answer[8:], 0)
+		binary.BigEndian.PutUint16(answer[10:], 0)
+
+		if qtype == 1 {
+			binary.BigEndian.PutUint16(answer[6:], 1)
+			answer = append(answer, 0xc0, 0x0c, 0, 1, 0, 1, 0, 0, 0, 60, 0, 4, 127, 0, 0, 1)
+		}
+
+		w.Header().Set("Content-Type", mediaTypeDNSMessage)
+		w.Write(answer)
+	}
+}
+
+func TestDoHResolver(t *testing.T) {
+	queries := 0
+	doh := httptest.NewServer(dohHandler(t, &queries))
+	defer doh.Close()
+
+	rdap := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	}))
+	defer rdap.Close()
+
+	resolver := DoHResolver(doh.URL, doh.Client())
+	addrs, err := resolver.LookupHost(context.Background(), "rdap.example.")
+
+	if err != nil || len(addrs) != 1 || addrs[0] != "127.0.0.1" {
+		t.Fatalf("expected the DoH answer, got %v (%v)", addrs, err)
+	}
+
+	_, port, _ := net.SplitHostPort(rdap.Listener.Addr().String())
+	transport := NewTransport(TransportOptions{Resolver: resolver})
+	client := &Client{HTTP: &http.Client{Transport: transport}, Host: "http://rdap.example.:" + port}
+
+	domain, err := client.Domain(context.Background(), "example.com")
+
+	if err != nil || domain.LDHName != "example.com" {
+		t.Fatalf("expected the server to be reached through the resolver, got %+v (%v)", domain, err)
+	}
+
+	if queries < 3 {
+		t.Fatalf("expected the transport to resolve through DoH, got %d queries", queries)
+	}
+}
+
+func TestDoHResolverStatus(t *testing.T) {
+	doh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer doh.Close()
+
+	if _, err := DoHResolver(doh.URL, nil).LookupHost(context.Background(), "rdap.example."); err == nil {
+		t.Fatal("expected a failing endpoint to fail the lookup")
+	}
+}
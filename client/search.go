@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/garslo/rdap-client/bootstrap"
+	"github.com/garslo/rdap-client/protocol"
+)
+
+// SearchOptions tunes the searches of a Client. The zero value fetches
+// only the first page of results.
+type SearchOptions struct {
+	// Pages bounds the pages of a paged result set that are fetched,
+	// following the next links of RFC 8977, and merged. Only the first page
+	// is fetched when it is zero, and every page when it is negative.
+	Pages int
+}
+
+// SearchDomains looks up the domains matching pattern, such as
+// "exam*.com", on the servers the dns registry lists for its top-level
+// domain.
+func (c *Client) SearchDomains(ctx context.Context, pattern string, opts SearchOptions) (*protocol.SearchResults, error) {
+	servers, err := c.searchServers(ctx, pattern)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.search(ctx, servers, "domains?"+url.Values{"name": {pattern}}.Encode(), opts)
+}
+
+// SearchNameservers looks up the nameservers matching pattern, such as
+// "ns*.example.com", the way SearchDomains looks domains up.
+func (c *Client) SearchNameservers(ctx context.Context, pattern string, opts SearchOptions) (*protocol.SearchResults, error) {
+	servers, err := c.searchServers(ctx, pattern)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.search(ctx, servers, "nameservers?"+url.Values{"name": {pattern}}.Encode(), opts)
+}
+
+// SearchEntities looks up the entities whose full name matches pattern on
+// the server at base.
+func (c *Client) SearchEntities(ctx context.Context, base, pattern string, opts SearchOptions) (*protocol.SearchResults, error) {
+	return c.search(ctx, []string{base}, "entities?"+url.Values{"fn": {pattern}}.Encode(), opts)
+}
+
+func (c *Client) searchServers(ctx context.Context, pattern string) ([]string, error) {
+	name, err := protocol.NormalizeDomain(pattern)
+
+	if err != nil {
+		return nil, err
+	}
+
+	labels := strings.Split(name, ".")
+
+	return c.servers(func(r *bootstrap.Registries) ([]string, error) {
+		return r.Domain(ctx, labels[len(labels)-1])
+	})
+}
+
+// search fetches the first page of results for path and then the pages
+// opts asks for, stopping when a next link repeats. Truncated and
+// PagingMetadata are those of the last page fetched, so Next tells whether
+// results were left on the server.
+func (c *Client) search(ctx context.Context, servers []string, path string, opts SearchOptions) (*protocol.SearchResults, error) {
+	var results protocol.SearchResults
+
+	if _, err := c.query(ctx, servers, path, &results); err != nil {
+		return nil, err
+	}
+
+	pages := opts.Pages
+
+	if pages == 0 {
+		pages = 1
+	}
+
+	seen := map[string]bool{}
+
+	for page := 1; page != pages && results.Next() != "" && !seen[results.Next()]; page++ {
+		var next protocol.SearchResults
+
+		seen[results.Next()] = true
+
+		if _, err := c.query(ctx, []string{results.Next()}, "", &next); err != nil {
+			return nil, err
+		}
+
+		results.Domains = append(results.Domains, next.Domains...)
+		results.Nameservers = append(results.Nameservers, next.Nameservers...)
+		results.Entities = append(results.Entities, next.Entities...)
+		results.Notices = next.Notices
+		results.PagingMetadata = next.PagingMetadata
+		results.Truncated = next.Truncated
+	}
+
+	return &results, nil
+}
+
+// SearchDomainsFunc is SearchDomains for result sets too large to hold in
+// memory: it calls visit with each *protocol.Domain of the first page as it
+// is received, as protocol.StreamSearchResults does, and returns the rest
+// of the response. Responses are decoded strictly, even when Lenient is
+// set.
+func (c *Client) SearchDomainsFunc(ctx context.Context, pattern string, visit func(interface{}) error) (*protocol.SearchResults, error) {
+	servers, err := c.searchServers(ctx, pattern)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.searchFunc(ctx, servers, "domains?"+url.Values{"name": {pattern}}.Encode(), visit)
+}
+
+// SearchNameserversFunc is SearchNameservers streamed like
+// SearchDomainsFunc.
+func (c *Client) SearchNameserversFunc(ctx context.Context, pattern string, visit func(interface{}) error) (*protocol.SearchResults, error) {
+	servers, err := c.searchServers(ctx, pattern)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.searchFunc(ctx, servers, "nameservers?"+url.Values{"name": {pattern}}.Encode(), visit)
+}
+
+// SearchEntitiesFunc is SearchEntities streamed like SearchDomainsFunc.
+func (c *Client) SearchEntitiesFunc(ctx context.Context, base, pattern string, visit func(interface{}) error) (*protocol.SearchResults, error) {
+	return c.searchFunc(ctx, []string{base}, "entities?"+url.Values{"fn": {pattern}}.Encode(), visit)
+}
+
+func (c *Client) searchFunc(ctx context.Context, servers []string, path string, visit func(interface{}) error) (*protocol.SearchResults, error) {
+	stream := &searchStream{visit: visit}
+
+	if _, err := c.query(ctx, servers, path, stream); err != nil {
+		return nil, err
+	}
+
+	return stream.results, nil
+}
+
+type searchStream struct {
+	visit   func(interface{}) error
+	results *protocol.SearchResults
+}
+
+func (s *searchStream) decodeStream(r io.Reader) error {
+	results, err := protocol.StreamSearchResults(r, s.visit)
+	s.results = results
+
+	return err
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/garslo/rdap-client/bootstrap"
+	"github.com/garslo/rdap-client/protocol"
+)
+
+func TestSearch(t *testing.T) {
+	var server *httptest.Server
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.RequestURI() {
+		case "/domains?name=exam%2A.com":
+			w.Write([]byte(`{
+				"domainSearchResults": [{"objectClassName": "domain", "ldhName": "example.com"}],
+				"paging_metadata": {"totalCount": 3, "links": [{"rel": "next", "href": "` + server.URL + `/domains?name=exam%2A.com&page=2"}]}
+			}`))
+		case "/domains?name=exam%2A.com&page=2":
+			w.Write([]byte(`{
+				"domainSearchResults": [{"objectClassName": "domain", "ldhName": "examine.com"}],
+				"notices": [{"title": "Search Policy", "type": "result set truncated due to excessive load"}]
+			}`))
+		case "/entities?fn=Alice%2A":
+			w.Write([]byte(`{
+				"rdapConformance": ["rdap_level_0", "incompleteData"],
+				"entitySearchResults": [{"objectClassName": "entity", "handle": "ALICE-1"}]
+			}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	registries := &bootstrap.Registries{}
+	registries.Set(bootstrap.DNS, bootstrap.ServiceRegistry{Services: bootstrap.ServicesList{{{"com"}, {server.URL}}}})
+
+	client := &Client{Bootstrap: registries}
+	ctx := context.Background()
+
+	first, err := client.SearchDomains(ctx, "exam*.com", SearchOptions{})
+
+	if err != nil || first.Len() != 1 || first.Truncated || first.Next() == "" {
+		t.Fatalf("expected the first page only, got %+v (%v)", first, err)
+	}
+
+	all, err := client.SearchDomains(ctx, "exam*.com", SearchOptions{Pages: -1})
+
+	if err != nil || all.Len() != 2 || all.Domains[1].LDHName != "examine.com" || !all.Truncated || all.Next() != "" {
+		t.Fatalf("expected both pages and the truncation notice, got %+v (%v)", all, err)
+	}
+
+	var names []string
+
+	rest, err := client.SearchDomainsFunc(ctx, "exam*.com", func(object interface{}) error {
+		names = append(names, object.(*protocol.Domain).LDHName)
+		return nil
+	})
+
+	if err != nil || len(names) != 1 || names[0] != "example.com" || rest.Next() == "" {
+		t.Fatalf("expected the first page to be streamed, got %q and %+v (%v)", names, rest, err)
+	}
+
+	entities, err := client.SearchEntities(ctx, server.URL, "Alice*", SearchOptions{})
+
+	if err != nil || len(entities.Entities) != 1 || !entities.Truncated {
+		t.Fatalf("expected incompleteData to mark the results truncated, got %+v (%v)", entities, err)
+	}
+}
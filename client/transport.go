@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// TransportOptions tunes the connections queries are sent over. Zero values
+// keep the settings of http.DefaultTransport.
+type TransportOptions struct {
+	// MaxIdleConnsPerHost bounds the idle connections kept open to each
+	// server for reuse.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is how long an idle connection is kept open.
+	IdleConnTimeout time.Duration
+	// DisableKeepAlives opens a new connection for every request.
+	DisableKeepAlives bool
+	// DisableHTTP2 forces HTTP/1.1 for servers whose HTTP/2 implementation
+	// misbehaves. Otherwise HTTP/2 is negotiated over TLS, and parallel
+	// queries to one server share a single multiplexed connection.
+	DisableHTTP2 bool
+	// DialContext replaces the dialer connections are opened with.
+	DialContext func(ctx context.Context, network, address string) (net.Conn, error)
+	// Network restricts connections to IPv4 with "tcp4" or to IPv6 with
+	// "tcp6", for dual-stack hosts where one family is broken.
+	Network string
+	// Resolver looks up the addresses of servers in place of the system
+	// resolver, such as one from NameserverResolver or DoHResolver. It is
+	// ignored when DialContext is set.
+	Resolver *net.Resolver
+	// Pins lists the SPKI pins accepted for each host, keyed by lower-case
+	// host name, in the form SPKIPin returns. Connections to a listed host
+	// fail with ErrPinMismatch unless its certificate chain carries one of
+	// them. Servers reached by IP address can't be pinned, as TLS carries
+	// no name for them.
+	Pins map[string][]string
+}
+
+// NewTransport returns a copy of http.DefaultTransport with opts applied,
+// for use as the Transport of a Client's HTTP client or under one of the
+// caching, recording and rate limiting transports.
+func NewTransport(opts TransportOptions) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if opts.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+
+		if transport.MaxIdleConns < opts.MaxIdleConnsPerHost {
+			transport.MaxIdleConns = opts.MaxIdleConnsPerHost
+		}
+	}
+
+	if opts.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = opts.IdleConnTimeout
+	}
+
+	if opts.DialContext != nil {
+		transport.DialContext = opts.DialContext
+	} else if opts.Resolver != nil {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Resolver: opts.Resolver}
+		transport.DialContext = dialer.DialContext
+	}
+
+	if opts.Network == "tcp4" || opts.Network == "tcp6" {
+		dial := transport.DialContext
+
+		transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+			if network == "tcp" {
+				network = opts.Network
+			}
+
+			return dial(ctx, network, address)
+		}
+	}
+
+	transport.DisableKeepAlives = opts.DisableKeepAlives
+	transport.ForceAttemptHTTP2 = !opts.DisableHTTP2
+
+	if opts.DisableHTTP2 {
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	if len(opts.Pins) > 0 {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+
+		transport.TLSClientConfig.VerifyConnection = verifyPins(opts.Pins)
+	}
+
+	return transport
+}
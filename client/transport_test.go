@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	tests := []struct {
+		description   string
+		opts          TransportOptions
+		expectedDials int
+	}{
+		{
+			description:   "it should reuse connections by default",
+			opts:          TransportOptions{MaxIdleConnsPerHost: 4, IdleConnTimeout: time.Minute},
+			expectedDials: 1,
+		},
+		{
+			description:   "it should open a connection per request without keep-alives",
+			opts:          TransportOptions{DisableKeepAlives: true},
+			expectedDials: 3,
+		},
+	}
+
+	for i, test := range tests {
+		dials := 0
+		dialer := &net.Dialer{}
+		test.opts.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+			dials++
+			return dialer.DialContext(ctx, network, address)
+		}
+
+		transport := NewTransport(test.opts)
+		client := &http.Client{Transport: transport}
+
+		for j := 0; j < 3; j++ {
+			resp, err := client.Get(server.URL)
+
+			if err != nil {
+				t.Fatalf("At index %d (%s): unexpected error %s", i, test.description, err)
+			}
+
+			resp.Body.Close()
+		}
+
+		transport.CloseIdleConnections()
+
+		if dials != test.expectedDials {
+			t.Fatalf("At index %d (%s): expected %d dials, got %d", i, test.description, test.expectedDials, dials)
+		}
+
+		if test.opts.MaxIdleConnsPerHost > 0 && (transport.MaxIdleConnsPerHost != 4 || transport.IdleConnTimeout != time.Minute) {
+			t.Fatalf("At index %d (%s): expected the pool settings to be applied, got %d %s", i, test.description, transport.MaxIdleConnsPerHost, transport.IdleConnTimeout)
+		}
+	}
+}
+
+func TestNewTransportHTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	roots := server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	for _, disable := range []bool{false, true} {
+		transport := NewTransport(TransportOptions{DisableHTTP2: disable})
+		transport.TLSClientConfig = roots.Clone()
+
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp.Body.Close()
+
+		if expected := map[bool]string{false: "HTTP/2.0", true: "HTTP/1.1"}[disable]; resp.Proto != expected {
+			t.Fatalf("expected %s with DisableHTTP2 %t, got %s", expected, disable, resp.Proto)
+		}
+	}
+}
+
+func TestNewTransportNetwork(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	tests := []struct {
+		network   string
+		expectErr bool
+	}{
+		{network: "tcp4"},
+		{network: "tcp6", expectErr: true},
+	}
+
+	for i, test := range tests {
+		var networks []string
+
+		dialer := &net.Dialer{}
+		client := &http.Client{
+			Transport: NewTransport(TransportOptions{
+				Network: test.network,
+				DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+					networks = append(networks, network)
+					return dialer.DialContext(ctx, network, address)
+				},
+			}),
+		}
+
+		resp, err := client.Get(url)
+
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		if (err != nil) != test.expectErr || len(networks) != 1 || networks[0] != test.network {
+			t.Fatalf("At index %d (%s): expected to dial %s only, dialed %v (%v)", i, test.network, test.network, networks, err)
+		}
+	}
+}
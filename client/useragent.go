@@ -0,0 +1,53 @@
+package client
+
+import "net/http"
+
+// Version is the version of this module reported in DefaultUserAgent.
+const Version = "0.1"
+
+// DefaultUserAgent identifies the queries and bootstrap fetches of a Client
+// whose UserAgent is unset. Several registries throttle or block the generic
+// Go-http-client agent, and operators use the URL to reach the maintainers.
+const DefaultUserAgent = "rdap-client/" + Version + " (+https://github.com/garslo/rdap-client)"
+
+// UserAgent returns DefaultUserAgent preceded by product, such as
+// "mytool/1.2 (+mailto:noc@example.net)", for callers that want to identify
+// themselves without hiding the library they are built on.
+func UserAgent(product string) string {
+	if product == "" {
+		return DefaultUserAgent
+	}
+
+	return product + " " + DefaultUserAgent
+}
+
+// UserAgentTransport sets the User-Agent header of the requests it sends
+// that don't already have one, such as bootstrap fetches.
+type UserAgentTransport struct {
+	// UserAgent replaces DefaultUserAgent when set.
+	UserAgent string
+	// Transport defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+func (t UserAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	userAgent := t.UserAgent
+
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	if req.Header.Get("User-Agent") != "" {
+		return transport.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", userAgent)
+
+	return transport.RoundTrip(req)
+}
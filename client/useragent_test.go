@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/garslo/rdap-client/bootstrap"
+)
+
+func TestUserAgent(t *testing.T) {
+	var agents []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.UserAgent())
+		w.Write([]byte(`{"objectClassName": "domain"}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	(&Client{Host: server.URL, Bootstrap: &bootstrap.Registries{}}).Domain(ctx, "example.com")
+	(&Client{Host: server.URL, Bootstrap: &bootstrap.Registries{}, UserAgent: UserAgent("mytool/1.2")}).Domain(ctx, "example.com")
+
+	expected := []string{DefaultUserAgent, "mytool/1.2 " + DefaultUserAgent}
+
+	if len(agents) != 2 || agents[0] != expected[0] || agents[1] != expected[1] {
+		t.Fatalf("expected %q, got %q", expected, agents)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	httpClient := &http.Client{Transport: UserAgentTransport{UserAgent: "bootstrap/1"}}
+
+	resp, err := httpClient.Do(req)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp.Body.Close()
+
+	if agents[2] != "bootstrap/1" || req.Header.Get("User-Agent") != "" {
+		t.Fatalf("expected the transport to set the agent on a copy, got %q", agents[2])
+	}
+}
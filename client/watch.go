@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/garslo/rdap-client/protocol"
+)
+
+type WatchEvent struct {
+	Time time.Time
+	// Old and New are the normalized responses before and after a change.
+	// Old is nil for the first successful fetch.
+	Old []byte
+	New []byte
+	Err error
+}
+
+// Watch calls fetch immediately and then every interval, and invokes notify
+// with ctx and the first response, with every response protocol.Diff finds different
+// from the previous one, and with every fetch error. Reordering the entities,
+// events or nameservers of a response is thus not a change. It returns
+// ctx.Err() once ctx is done, and an error straight away for an interval
+// that isn't positive.
+func Watch(ctx context.Context, interval time.Duration, fetch func(context.Context) ([]byte, error), notify func(context.Context, WatchEvent)) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid watch interval %s: expected a positive duration", interval)
+	}
+
+	var previous []byte
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		b, err := fetch(ctx)
+
+		if err == nil {
+			b, err = protocol.Normalize(b)
+		}
+
+		changed := previous == nil
+
+		if err == nil && previous != nil {
+			var differences []protocol.Difference
+
+			differences, err = protocol.Diff(previous, b)
+			changed = len(differences) > 0
+		}
+
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			notify(ctx, WatchEvent{Time: time.Now(), Err: err})
+		case changed:
+			notify(ctx, WatchEvent{Time: time.Now(), Old: previous, New: b})
+			previous = b
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
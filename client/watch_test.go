@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	responses := []string{
+		`{"ldhName": "example.com", "nameservers": [{"ldhName": "ns1.example.net"}, {"ldhName": "ns3.example.net"}], "events": [{"eventAction": "last update of RDAP database", "eventDate": "2024-01-01T00:00:00Z"}]}`,
+		`{"ldhName": "example.com", "nameservers": [{"ldhName": "ns1.example.net"}, {"ldhName": "ns3.example.net"}], "notices": [{"title": "Rate limit"}], "events": [{"eventAction": "last update of RDAP database", "eventDate": "2024-01-01T01:00:00Z"}]}`,
+		`{"ldhName": "example.com", "nameservers": [{"ldhName": "ns3.example.net"}, {"ldhName": "ns1.example.net"}]}`,
+		``,
+		`{"ldhName": "example.com", "nameservers": [{"ldhName": "ns2.example.net"}]}`,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	fetch := func(context.Context) ([]byte, error) {
+		defer func() { calls++ }()
+
+		if calls >= len(responses) {
+			cancel()
+			return nil, ctx.Err()
+		}
+
+		if responses[calls] == "" {
+			return nil, fmt.Errorf("server unreachable")
+		}
+
+		return []byte(responses[calls]), nil
+	}
+
+	var events []string
+
+	err := Watch(ctx, time.Millisecond, fetch, func(_ context.Context, event WatchEvent) {
+		events = append(events, fmt.Sprintf("%s -> %s (%v)", event.Old, event.New, event.Err))
+	})
+
+	if err != context.Canceled {
+		t.Fatalf("expected watch to stop with context.Canceled, got %v", err)
+	}
+
+	expected := []string{
+		` -> {"ldhName":"example.com","nameservers":[{"ldhName":"ns1.example.net"},{"ldhName":"ns3.example.net"}]} (<nil>)`,
+		` ->  (server unreachable)`,
+		`{"ldhName":"example.com","nameservers":[{"ldhName":"ns1.example.net"},{"ldhName":"ns3.example.net"}]} -> {"ldhName":"example.com","nameservers":[{"ldhName":"ns2.example.net"}]} (<nil>)`,
+	}
+
+	if !reflect.DeepEqual(expected, events) {
+		t.Fatalf("expected %q, got %q", expected, events)
+	}
+
+	if err := Watch(context.Background(), 0, fetch, func(context.Context, WatchEvent) {}); err == nil {
+		t.Fatal("expected an error for a zero interval")
+	}
+}
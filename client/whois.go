@@ -0,0 +1,227 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/garslo/rdap-client/protocol"
+)
+
+// WhoisServer is a port 43 whois server (RFC 3912) answering each query
+// with the RDAP lookup ParseTarget guesses for it, rendered by
+// protocol.Whois, so that tools that only speak whois keep working:
+//
+//	l, _ := net.Listen("tcp", ":43")
+//	(&client.WhoisServer{Client: c}).Serve(l)
+//
+// Leading query flags, such as the -B of RIPE's server, are ignored.
+type WhoisServer struct {
+	Client *Client
+	// Timeout bounds the reading of a query and its lookup; it defaults to
+	// 30 seconds.
+	Timeout time.Duration
+}
+
+// Serve answers the connections accepted on l until accepting one fails,
+// returning that error.
+func (s *WhoisServer) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+
+		if err != nil {
+			return err
+		}
+
+		go s.serve(conn)
+	}
+}
+
+func (s *WhoisServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	timeout := s.Timeout
+
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+
+	if err != nil && line == "" {
+		return
+	}
+
+	var query string
+
+	for _, field := range strings.Fields(line) {
+		if !strings.HasPrefix(field, "-") {
+			query = field
+		}
+	}
+
+	if query == "" {
+		fmt.Fprint(conn, "% Empty query\r\n")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	object, err := s.Client.Query(ctx, ParseTarget(query))
+
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrNoServer):
+		fmt.Fprintf(conn, "%% No entries found for %s\r\n", query)
+	case err != nil:
+		fmt.Fprintf(conn, "%% Error: %s\r\n", err)
+	default:
+		if record, ok := object.(*WhoisRecord); ok {
+			fmt.Fprint(conn, record.Text)
+			return
+		}
+
+		fmt.Fprint(conn, strings.ReplaceAll(protocol.Whois(object), "\n", "\r\n"))
+	}
+}
+
+// IANAWhois is the whois server Client.Whois asks first unless WhoisHost is
+// set. It refers each query to the server of the registry holding it.
+const IANAWhois = "whois.iana.org:43"
+
+// WhoisRecord is the answer of a port 43 whois server, which Query returns
+// in place of an RDAP object when WhoisFallback is set and RDAP has none.
+// Text is passed through as the server at Server sent it, unparsed.
+type WhoisRecord struct {
+	Server string
+	Query  string
+	Text   string
+}
+
+// Whois sends query to the port 43 whois server (RFC 3912) at WhoisHost, or
+// IANAWhois, and to the server its "refer:" line points at, if any,
+// returning the answer of the last server asked. Policy and Limiter apply
+// as they do to RDAP queries.
+func (c *Client) Whois(ctx context.Context, query string) (*WhoisRecord, error) {
+	server := c.WhoisHost
+
+	if server == "" {
+		server = IANAWhois
+	}
+
+	text, err := c.whois(ctx, server, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if refer := whoisReferral(text); refer != "" && refer != server {
+		server = refer
+
+		if text, err = c.whois(ctx, server, query); err != nil {
+			return nil, err
+		}
+	}
+
+	return &WhoisRecord{Server: server, Query: query, Text: text}, nil
+}
+
+func (c *Client) whois(ctx context.Context, server, query string) (string, error) {
+	host, _, err := net.SplitHostPort(server)
+
+	if err != nil {
+		return "", err
+	}
+
+	if !c.Policy.Allowed(host) {
+		return "", fmt.Errorf("whois %s: %w", host, ErrHostDenied)
+	}
+
+	if limiter := c.limiter(); limiter != nil {
+		if err := limiter.Wait(ctx, host); err != nil {
+			return "", err
+		}
+	}
+
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", server)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	deadline, ok := ctx.Deadline()
+
+	if !ok {
+		deadline = time.Now().Add(30 * time.Second)
+	}
+
+	conn.SetDeadline(deadline)
+
+	if _, err := fmt.Fprintf(conn, "%s\r\n", query); err != nil {
+		return "", err
+	}
+
+	// Whois answers are a few kilobytes; a megabyte is plenty.
+	text, err := io.ReadAll(io.LimitReader(conn, 1<<20))
+
+	if err != nil {
+		return "", fmt.Errorf("whois %s: %w", host, err)
+	}
+
+	return string(text), nil
+}
+
+// whoisReferral returns the server the "refer:" line of a whois answer
+// points at, with port 43 unless it names one.
+func whoisReferral(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+
+		if !ok || !strings.EqualFold(strings.TrimSpace(key), "refer") {
+			continue
+		}
+
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+
+		if _, _, err := net.SplitHostPort(value); err != nil {
+			value = net.JoinHostPort(value, "43")
+		}
+
+		return value
+	}
+
+	return ""
+}
+
+// whoisQuery returns what to ask a whois server for target, and false for
+// the targets whois has no referral for, such as entities.
+func whoisQuery(target Target) (string, bool) {
+	switch target.Type {
+	case TargetDomain:
+		name, err := protocol.NormalizeDomain(target.Value)
+
+		return strings.TrimPrefix(name, "."), err == nil
+	case TargetIP:
+		return target.Value, true
+	case TargetAutnum:
+		return "AS" + strings.TrimPrefix(strings.ToUpper(target.Value), "AS"), true
+	}
+
+	return "", false
+}
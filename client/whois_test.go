@@ -0,0 +1,152 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/garslo/rdap-client/bootstrap"
+)
+
+func TestWhoisServer(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/autnum/64500" {
+			w.Write([]byte(`{"objectClassName": "autnum", "handle": "AS64500", "startAutnum": 64500, "name": "EXAMPLE"}`))
+			return
+		}
+
+		http.NotFound(w, r)
+	}))
+	defer upstream.Close()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer l.Close()
+
+	go (&WhoisServer{Client: &Client{Host: upstream.URL, Bootstrap: &bootstrap.Registries{}}}).Serve(l)
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{query: "-B AS64500\r\n", expected: "ASNumber: 64500\r\nASName: EXAMPLE\r\nASHandle: AS64500\r\n"},
+		{query: "example.com\r\n", expected: "% No entries found for example.com\r\n"},
+	}
+
+	for i, test := range tests {
+		conn, err := net.Dial("tcp", l.Addr().String())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conn.Write([]byte(test.query))
+		answer, _ := io.ReadAll(conn)
+		conn.Close()
+
+		if string(answer) != test.expected {
+			t.Fatalf("At index %d (%q): expected %q, got %q", i, test.query, test.expected, answer)
+		}
+	}
+}
+
+// whoisListener answers each whois query with answer(query).
+func whoisListener(t *testing.T, answer func(string) string) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+
+			if err != nil {
+				return
+			}
+
+			query, _ := bufio.NewReader(conn).ReadString('\n')
+			io.WriteString(conn, answer(strings.TrimSpace(query)))
+			conn.Close()
+		}
+	}()
+
+	return l
+}
+
+func TestWhoisFallback(t *testing.T) {
+	registry := whoisListener(t, func(query string) string {
+		return "Domain Name: " + query + "\r\n"
+	})
+	defer registry.Close()
+
+	iana := whoisListener(t, func(query string) string {
+		return "% IANA WHOIS server\r\n\r\nrefer:        " + registry.Addr().String() + "\r\n"
+	})
+	defer iana.Close()
+
+	registries := &bootstrap.Registries{}
+	registries.Set(bootstrap.DNS, bootstrap.ServiceRegistry{})
+	ctx := context.Background()
+
+	tests := []struct {
+		description string
+		client      *Client
+		target      Target
+		expected    string
+		err         error
+	}{
+		{
+			description: "no fallback unless asked for",
+			client:      &Client{Bootstrap: registries, WhoisHost: iana.Addr().String()},
+			target:      ParseTarget("example.test"),
+			err:         ErrNoServer,
+		},
+		{
+			description: "the referred server answers",
+			client:      &Client{Bootstrap: registries, WhoisHost: iana.Addr().String(), WhoisFallback: true},
+			target:      ParseTarget("EXAMPLE.test"),
+			expected:    registry.Addr().String() + " example.test Domain Name: example.test\r\n",
+		},
+		{
+			description: "a denied whois server keeps the RDAP error",
+			client:      &Client{Bootstrap: registries, WhoisHost: iana.Addr().String(), WhoisFallback: true, Policy: &HostPolicy{Deny: []string{"127.0.0.1"}}},
+			target:      ParseTarget("example.test"),
+			err:         ErrNoServer,
+		},
+	}
+
+	for i, test := range tests {
+		object, err := test.client.Query(ctx, test.target)
+
+		if !errors.Is(err, test.err) {
+			t.Fatalf("At index %d (%s): expected error %v, got %v", i, test.description, test.err, err)
+		}
+
+		if err != nil {
+			continue
+		}
+
+		record, ok := object.(*WhoisRecord)
+
+		if !ok {
+			t.Fatalf("At index %d (%s): expected a whois record, got %T", i, test.description, object)
+		}
+
+		if got := fmt.Sprintf("%s %s %s", record.Server, record.Query, record.Text); got != test.expected {
+			t.Fatalf("At index %d (%s): expected %q, got %q", i, test.description, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,385 @@
+// Package config loads the defaults a heavy RDAP user would otherwise pass on
+// every invocation from a configuration file and RDAP_* environment
+// variables, and builds a client.Client from them. A front-end calls Load,
+// then ApplyEnv, then applies its own flags, so that flags take precedence
+// over the environment and the environment over the file.
+//
+// The file is TOML:
+//
+//	format = "json"
+//	lang = "en"
+//	timeout = "10s"
+//	proxy = "http://proxy.example.net:3128"
+//	resolver = "https://dns.example.net/dns-query"
+//	host = "https://rdap.example.com/"
+//	user_agent = "mytool/1.2 (+mailto:noc@example.net)"
+//
+//	[cache]
+//	dir = "~/.cache/rdap"
+//	ttl = "1h"
+//
+//	[bootstrap]
+//	url = "https://bootstrap.example.net/"
+//	dns = "https://bootstrap.example.net/dns.json"
+//
+//	[hosts."rdap.example.com"]
+//	username = "alice"
+//	password = "secret"
+//	pins = "sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="
+//
+// The file format and the RDAP_* variables follow semantic versioning along
+// with the Go API: a minor release may add keys and variables, but a file
+// that loads under one release loads the same way under every later
+// release of the same major version.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/garslo/rdap-client/bootstrap"
+	"github.com/garslo/rdap-client/client"
+)
+
+// Host holds the credentials sent to one RDAP server. Token, when set, is
+// sent as a bearer token instead of basic authentication. Pins, when set,
+// are the SPKI pins one of which the server's certificate chain must carry,
+// as client.TransportOptions.Pins describes.
+type Host struct {
+	Username string
+	Password string
+	Token    string
+	Pins     []string
+}
+
+type Config struct {
+	// Format is the preferred output format of a front-end.
+	Format string
+	// Lang is the preferred language of answers, sent as Accept-Language.
+	Lang    string
+	Timeout time.Duration
+	Proxy   string
+	// Resolver resolves server host names in place of the system resolver:
+	// a DNS-over-HTTPS endpoint when it is an https URL, otherwise the
+	// address of a nameserver.
+	Resolver string
+	// CacheDir enables a client.CachingTransport keeping entries for
+	// CacheTTL.
+	CacheDir string
+	CacheTTL time.Duration
+	// BootstrapURL is the base URL the bootstrap registries are fetched
+	// from, as <kind>.json, and Bootstrap overrides it for single kinds.
+	BootstrapURL string
+	Bootstrap    map[bootstrap.Kind]string
+	// Host is the base URL every query is sent to, bypassing bootstrap.
+	Host string
+	// UserAgent identifies the front-end ahead of client.DefaultUserAgent.
+	UserAgent string
+	// Hosts holds credentials keyed by server host name.
+	Hosts map[string]Host
+}
+
+// DefaultPath returns where the configuration file is looked for when no
+// path is given: rdap/config.toml under $XDG_CONFIG_HOME, or under ~/.config
+// when that is unset.
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+
+	if dir == "" {
+		home, err := os.UserHomeDir()
+
+		if err != nil {
+			return ""
+		}
+
+		dir = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(dir, "rdap", "config.toml")
+}
+
+// Load reads the configuration file at path, or at DefaultPath when path is
+// empty. A missing default file yields an empty Config; a missing explicit
+// one is an error.
+func Load(path string) (Config, error) {
+	explicit := path != ""
+
+	if !explicit {
+		path = DefaultPath()
+	}
+
+	f, err := os.Open(path)
+
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return Config{}, nil
+	}
+
+	if err != nil {
+		return Config{}, err
+	}
+
+	defer f.Close()
+
+	config, err := Parse(f)
+
+	if err != nil {
+		return config, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// Parse reads a configuration file from r, rejecting unknown keys.
+func Parse(r io.Reader) (Config, error) {
+	var config Config
+
+	settings, err := parseTOML(r)
+
+	if err != nil {
+		return config, err
+	}
+
+	for _, s := range settings {
+		if err := config.set(s.key, s.value); err != nil {
+			return config, fmt.Errorf("line %d: %s: %w", s.line, strings.Join(s.key, "."), err)
+		}
+	}
+
+	return config, nil
+}
+
+func (c *Config) set(key []string, value interface{}) error {
+	var err error
+
+	switch {
+	case len(key) == 1 && key[0] == "format":
+		c.Format, err = stringValue(value)
+	case len(key) == 1 && key[0] == "lang":
+		c.Lang, err = stringValue(value)
+	case len(key) == 1 && key[0] == "timeout":
+		c.Timeout, err = durationValue(value)
+	case len(key) == 1 && key[0] == "proxy":
+		c.Proxy, err = stringValue(value)
+	case len(key) == 1 && key[0] == "resolver":
+		c.Resolver, err = stringValue(value)
+	case len(key) == 1 && key[0] == "host":
+		c.Host, err = stringValue(value)
+	case len(key) == 1 && key[0] == "user_agent":
+		c.UserAgent, err = stringValue(value)
+	case len(key) == 2 && key[0] == "cache" && key[1] == "dir":
+		if c.CacheDir, err = stringValue(value); err == nil {
+			c.CacheDir, err = expandHome(c.CacheDir)
+		}
+	case len(key) == 2 && key[0] == "cache" && key[1] == "ttl":
+		c.CacheTTL, err = durationValue(value)
+	case len(key) == 2 && key[0] == "bootstrap" && key[1] == "url":
+		c.BootstrapURL, err = stringValue(value)
+	case len(key) == 2 && key[0] == "bootstrap":
+		kind := bootstrap.Kind(key[1])
+
+		if kind != bootstrap.DNS && kind != bootstrap.IPv4 && kind != bootstrap.IPv6 && kind != bootstrap.ASN {
+			return fmt.Errorf("unknown bootstrap registry")
+		}
+
+		if c.Bootstrap == nil {
+			c.Bootstrap = map[bootstrap.Kind]string{}
+		}
+
+		c.Bootstrap[kind], err = stringValue(value)
+	case len(key) == 3 && key[0] == "hosts":
+		if c.Hosts == nil {
+			c.Hosts = map[string]Host{}
+		}
+
+		host := c.Hosts[strings.ToLower(key[1])]
+
+		switch key[2] {
+		case "username":
+			host.Username, err = stringValue(value)
+		case "password":
+			host.Password, err = stringValue(value)
+		case "token":
+			host.Token, err = stringValue(value)
+		case "pins":
+			host.Pins, err = pinsValue(value)
+		default:
+			return fmt.Errorf("unknown key")
+		}
+
+		c.Hosts[strings.ToLower(key[1])] = host
+	default:
+		return fmt.Errorf("unknown key")
+	}
+
+	return err
+}
+
+func stringValue(value interface{}) (string, error) {
+	s, ok := value.(string)
+
+	if !ok {
+		return "", fmt.Errorf("expected a string, got %v", value)
+	}
+
+	return s, nil
+}
+
+// pinsValue accepts SPKI pins separated by commas or spaces, with or
+// without their "sha256/" prefix.
+func pinsValue(value interface{}) ([]string, error) {
+	s, err := stringValue(value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pins := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+
+	for i, pin := range pins {
+		if pins[i], err = client.ParsePin(pin); err != nil {
+			return nil, err
+		}
+	}
+
+	return pins, nil
+}
+
+// durationValue accepts Go duration strings such as "1m30s" and integers,
+// taken as seconds.
+func durationValue(value interface{}) (time.Duration, error) {
+	switch v := value.(type) {
+	case int64:
+		return time.Duration(v) * time.Second, nil
+	case string:
+		return time.ParseDuration(v)
+	}
+
+	return 0, fmt.Errorf("expected a duration, got %v", value)
+}
+
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, path[1:]), nil
+}
+
+// Client builds a client honoring the proxy, resolver, timeout, cache,
+// credentials and bootstrap overrides in c.
+func (c Config) Client() (*client.Client, error) {
+	var opts client.TransportOptions
+
+	switch {
+	case strings.HasPrefix(c.Resolver, "https://"):
+		opts.Resolver = client.DoHResolver(c.Resolver, nil)
+	case c.Resolver != "":
+		address := c.Resolver
+
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			address = net.JoinHostPort(strings.Trim(address, "[]"), "53")
+		}
+
+		opts.Resolver = client.NameserverResolver(address)
+	}
+
+	for name, host := range c.Hosts {
+		if len(host.Pins) > 0 {
+			if opts.Pins == nil {
+				opts.Pins = map[string][]string{}
+			}
+
+			opts.Pins[name] = host.Pins
+		}
+	}
+
+	transport := client.NewTransport(opts)
+
+	if c.Proxy != "" {
+		proxy, err := url.Parse(c.Proxy)
+
+		if err != nil {
+			return nil, fmt.Errorf("proxy: %w", err)
+		}
+
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	var roundTripper http.RoundTripper = transport
+
+	// The cache sits below the credentials, so that it sees their
+	// Authorization header and leaves authenticated answers out.
+	if c.CacheDir != "" {
+		roundTripper = &client.CachingTransport{Dir: c.CacheDir, TTL: c.CacheTTL, Transport: roundTripper}
+	}
+
+	if len(c.Hosts) > 0 {
+		roundTripper = credentialsTransport{hosts: c.Hosts, transport: roundTripper}
+	}
+
+	roundTripper = client.UserAgentTransport{UserAgent: client.UserAgent(c.UserAgent), Transport: roundTripper}
+
+	rdap := &client.Client{
+		HTTP:      &http.Client{Transport: roundTripper, Timeout: c.Timeout},
+		Host:      c.Host,
+		UserAgent: client.UserAgent(c.UserAgent),
+		Language:  c.Lang,
+	}
+
+	if c.BootstrapURL != "" || len(c.Bootstrap) > 0 {
+		urls := map[bootstrap.Kind]string{}
+
+		for _, kind := range []bootstrap.Kind{bootstrap.DNS, bootstrap.IPv4, bootstrap.IPv6, bootstrap.ASN} {
+			if override, ok := c.Bootstrap[kind]; ok {
+				urls[kind] = override
+			} else if c.BootstrapURL != "" {
+				urls[kind] = strings.TrimSuffix(c.BootstrapURL, "/") + "/" + string(kind) + ".json"
+			}
+		}
+
+		rdap.Bootstrap = &bootstrap.Registries{Client: rdap.HTTP, URLs: urls}
+	}
+
+	return rdap, nil
+}
+
+// credentialsTransport authenticates requests to the hosts it has
+// credentials for, and only those, so that a redirect to another server
+// doesn't leak them.
+type credentialsTransport struct {
+	hosts     map[string]Host
+	transport http.RoundTripper
+}
+
+func (t credentialsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host, ok := t.hosts[strings.ToLower(req.URL.Hostname())]
+
+	if !ok || host.Username == "" && host.Password == "" && host.Token == "" {
+		return t.transport.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+
+	if host.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+host.Token)
+	} else {
+		req.SetBasicAuth(host.Username, host.Password)
+	}
+
+	return t.transport.RoundTrip(req)
+}
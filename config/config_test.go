@@ -0,0 +1,161 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garslo/rdap-client/bootstrap"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		description   string
+		file          string
+		expected      Config
+		expectedError error
+	}{
+		{
+			description: "it should read every setting",
+			file: `
+				# defaults for every lookup
+				format = "json"
+				lang = "en"
+				timeout = 10
+				proxy = 'http://proxy.example.net:3128' # trailing comment
+				resolver = "192.0.2.53"
+				user_agent = "mytool/1.2"
+
+				[cache]
+				dir = "/var/cache/rdap"
+				ttl = "1h30m"
+
+				[bootstrap]
+				dns = "https://bootstrap.example.net/dns.json"
+
+				[hosts."RDAP.example.com"]
+				username = "alice"
+				password = "s#cret"
+				pins = "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=, sha256/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
+
+				[hosts]
+				"rdap.example.org".token = "abc"
+			`,
+			expected: Config{
+				Format:    "json",
+				Lang:      "en",
+				Timeout:   10 * time.Second,
+				Proxy:     "http://proxy.example.net:3128",
+				Resolver:  "192.0.2.53",
+				CacheDir:  "/var/cache/rdap",
+				CacheTTL:  90 * time.Minute,
+				Bootstrap: map[bootstrap.Kind]string{bootstrap.DNS: "https://bootstrap.example.net/dns.json"},
+				UserAgent: "mytool/1.2",
+				Hosts: map[string]Host{
+					"rdap.example.com": {Username: "alice", Password: "s#cret", Pins: []string{"sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=", "sha256/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="}},
+					"rdap.example.org": {Token: "abc"},
+				},
+			},
+		},
+		{
+			description:   "it should reject unknown keys",
+			file:          "[cache]\nsize = 10\n",
+			expectedError: fmt.Errorf("line 2: cache.size: unknown key"),
+		},
+		{
+			description:   "it should reject values of the wrong type",
+			file:          "format = true\n",
+			expectedError: fmt.Errorf("line 1: format: expected a string, got true"),
+		},
+		{
+			description:   "it should reject malformed pins",
+			file:          "[hosts.\"rdap.example.com\"]\npins = \"sha256/short\"\n",
+			expectedError: fmt.Errorf(`line 2: hosts.rdap.example.com.pins: invalid pin "sha256/short": expected the base64 of a sha256 digest`),
+		},
+		{
+			description:   "it should reject unsupported syntax",
+			file:          "\nformat = [\"json\"]\n",
+			expectedError: fmt.Errorf(`line 2: unsupported value ["json"]`),
+		},
+	}
+
+	for i, test := range tests {
+		config, err := Parse(strings.NewReader(test.file))
+
+		if fmt.Sprintf("%v", test.expectedError) != fmt.Sprintf("%v", err) {
+			t.Fatalf("At index %d (%s): expected error %v, got %v", i, test.description, test.expectedError, err)
+		}
+
+		if test.expectedError == nil && !reflect.DeepEqual(test.expected, config) {
+			t.Fatalf("At index %d (%s): expected %+v, got %+v", i, test.description, test.expected, config)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	if config, err := Load(""); err != nil || !reflect.DeepEqual(Config{}, config) {
+		t.Fatalf("expected a missing default file to be ignored, got %+v (%v)", config, err)
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.toml")); err == nil {
+		t.Fatalf("expected a missing explicit file to be an error")
+	}
+
+	os.MkdirAll(filepath.Join(dir, "rdap"), 0755)
+	os.WriteFile(filepath.Join(dir, "rdap", "config.toml"), []byte(`format = "text"`), 0644)
+
+	if config, err := Load(""); err != nil || config.Format != "text" {
+		t.Fatalf("expected the default file to be read, got %+v (%v)", config, err)
+	}
+}
+
+func TestClient(t *testing.T) {
+	var (
+		authorization []string
+		base          string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+
+		if r.URL.Path == "/dns.json" {
+			fmt.Fprintf(w, `{"services": [[["com"], [%q]]]}`, base)
+			return
+		}
+
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	}))
+	defer server.Close()
+
+	base = strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/rdap/"
+
+	config := Config{
+		Timeout:   time.Second,
+		Bootstrap: map[bootstrap.Kind]string{bootstrap.DNS: server.URL + "/dns.json"},
+		Hosts:     map[string]Host{"localhost": {Token: "abc"}},
+	}
+
+	rdap, err := config.Client()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rdap.Domain(context.Background(), "example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"", "Bearer abc"}; !reflect.DeepEqual(expected, authorization) {
+		t.Fatalf("expected credentials only for the configured host, got %q", authorization)
+	}
+}
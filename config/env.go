@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const (
+	EnvCacheDir     = "RDAP_CACHE_DIR"
+	EnvBootstrapURL = "RDAP_BOOTSTRAP_URL"
+	EnvTimeout      = "RDAP_TIMEOUT"
+	EnvFormat       = "RDAP_FORMAT"
+	EnvHost         = "RDAP_HOST"
+)
+
+// ApplyEnv overrides c with the RDAP_* environment variables that are set
+// and not empty. RDAP_TIMEOUT takes a Go duration such as "30s" or a number
+// of seconds.
+func (c *Config) ApplyEnv() error {
+	if dir := os.Getenv(EnvCacheDir); dir != "" {
+		c.CacheDir = dir
+	}
+
+	if url := os.Getenv(EnvBootstrapURL); url != "" {
+		c.BootstrapURL = url
+		c.Bootstrap = nil
+	}
+
+	if timeout := os.Getenv(EnvTimeout); timeout != "" {
+		var value interface{} = timeout
+
+		if seconds, err := strconv.ParseInt(timeout, 10, 64); err == nil {
+			value = seconds
+		}
+
+		d, err := durationValue(value)
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", EnvTimeout, err)
+		}
+
+		c.Timeout = d
+	}
+
+	if format := os.Getenv(EnvFormat); format != "" {
+		c.Format = format
+	}
+
+	if host := os.Getenv(EnvHost); host != "" {
+		c.Host = host
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/garslo/rdap-client/bootstrap"
+)
+
+func TestApplyEnv(t *testing.T) {
+	config := Config{
+		Format:    "json",
+		Timeout:   time.Minute,
+		CacheDir:  "/var/cache/rdap",
+		Bootstrap: map[bootstrap.Kind]string{bootstrap.DNS: "https://bootstrap.example.net/dns.json"},
+		Proxy:     "http://proxy.example.net:3128",
+	}
+
+	t.Setenv(EnvCacheDir, "/tmp/rdap")
+	t.Setenv(EnvBootstrapURL, "https://mirror.example.net/rdap/")
+	t.Setenv(EnvTimeout, "5")
+	t.Setenv(EnvFormat, "")
+	t.Setenv(EnvHost, "https://rdap.example.com/")
+
+	if err := config.ApplyEnv(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Config{
+		Format:       "json",
+		Timeout:      5 * time.Second,
+		CacheDir:     "/tmp/rdap",
+		BootstrapURL: "https://mirror.example.net/rdap/",
+		Host:         "https://rdap.example.com/",
+		Proxy:        "http://proxy.example.net:3128",
+	}
+
+	if !reflect.DeepEqual(expected, config) {
+		t.Fatalf("expected %+v, got %+v", expected, config)
+	}
+
+	t.Setenv(EnvTimeout, "soon")
+
+	if err := config.ApplyEnv(); err == nil || err.Error() != `RDAP_TIMEOUT: time: invalid duration "soon"` {
+		t.Fatalf("expected an invalid timeout to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// setting is one key = value line of a configuration file, with key
+// qualified by the table it appears in.
+type setting struct {
+	line  int
+	key   []string
+	value interface{}
+}
+
+// parseTOML reads the subset of TOML the configuration file uses: tables,
+// dotted and quoted keys, strings, integers and booleans. Arrays, inline
+// tables and dates are rejected.
+func parseTOML(r io.Reader) ([]setting, error) {
+	var (
+		settings []setting
+		table    []string
+		scanner  = bufio.NewScanner(r)
+	)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(stripComment(scanner.Text()))
+
+		if text == "" {
+			continue
+		}
+
+		if strings.HasPrefix(text, "[") {
+			if !strings.HasSuffix(text, "]") || strings.HasPrefix(text, "[[") {
+				return nil, fmt.Errorf("line %d: invalid table header %q", line, text)
+			}
+
+			key, err := parseKey(text[1 : len(text)-1])
+
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+
+			table = key
+			continue
+		}
+
+		i := strings.Index(text, "=")
+
+		if i < 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", line)
+		}
+
+		key, err := parseKey(text[:i])
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		value, err := parseValue(strings.TrimSpace(text[i+1:]))
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		settings = append(settings, setting{
+			line:  line,
+			key:   append(append([]string(nil), table...), key...),
+			value: value,
+		})
+	}
+
+	return settings, scanner.Err()
+}
+
+// stripComment cuts text at the first # outside a string.
+func stripComment(text string) string {
+	var quote byte
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == '#':
+			return text[:i]
+		}
+	}
+
+	return text
+}
+
+func parseKey(text string) ([]string, error) {
+	var key []string
+
+	for text = strings.TrimSpace(text); text != ""; {
+		var part string
+
+		if text[0] == '"' || text[0] == '\'' {
+			end := strings.IndexByte(text[1:], text[0])
+
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated key %q", text)
+			}
+
+			part, text = text[1:end+1], strings.TrimSpace(text[end+2:])
+		} else {
+			end := strings.IndexByte(text, '.')
+
+			if end < 0 {
+				end = len(text)
+			}
+
+			part, text = strings.TrimSpace(text[:end]), strings.TrimSpace(text[end:])
+
+			if part == "" || strings.ContainsAny(part, " \t\"'") {
+				return nil, fmt.Errorf("invalid key %q", part)
+			}
+		}
+
+		key = append(key, part)
+
+		if text != "" {
+			if text[0] != '.' {
+				return nil, fmt.Errorf("invalid key %q", text)
+			}
+
+			text = strings.TrimSpace(text[1:])
+		}
+	}
+
+	if len(key) == 0 {
+		return nil, fmt.Errorf("empty key")
+	}
+
+	return key, nil
+}
+
+func parseValue(text string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(text, `"`):
+		return strconv.Unquote(text)
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") || strings.Contains(text[1:len(text)-1], "'") {
+			return nil, fmt.Errorf("invalid string %s", text)
+		}
+
+		return text[1 : len(text)-1], nil
+	case text == "true", text == "false":
+		return text == "true", nil
+	}
+
+	n, err := strconv.ParseInt(strings.ReplaceAll(text, "_", ""), 10, 64)
+
+	if err != nil {
+		return nil, fmt.Errorf("unsupported value %s", text)
+	}
+
+	return n, nil
+}
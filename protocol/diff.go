@@ -0,0 +1,192 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+type DiffKind string
+
+const (
+	DiffAdded   DiffKind = "added"
+	DiffRemoved DiffKind = "removed"
+	DiffChanged DiffKind = "changed"
+)
+
+// Difference is a single field-level change between two responses. Old and
+// New hold the JSON encoding of the value on each side.
+type Difference struct {
+	Path string
+	Kind DiffKind
+	Old  string
+	New  string
+}
+
+func (d Difference) String() string {
+	switch d.Kind {
+	case DiffAdded:
+		return fmt.Sprintf("+ %s: %s", d.Path, d.New)
+	case DiffRemoved:
+		return fmt.Sprintf("- %s: %s", d.Path, d.Old)
+	}
+
+	return fmt.Sprintf("~ %s: %s -> %s", d.Path, d.Old, d.New)
+}
+
+// identityMembers name the members that identify an element of an unordered
+// RDAP array, tried in order. Arrays of objects are matched on these rather
+// than on position, so reordering entities, events or nameservers is not
+// reported as a change.
+var identityMembers = []string{"handle", "ldhName", "eventAction", "rel", "title"}
+
+var orderedArrays = map[string]bool{
+	"description": true,
+	"vcardArray":  true,
+}
+
+// Diff compares two raw responses and returns their differences sorted by
+// path. Volatile members are ignored as in Normalize. Elements of arrays are
+// matched by identity, so paths use the matched key ("/entities[ABC123]/...")
+// rather than an index.
+func Diff(old, new []byte) ([]Difference, error) {
+	var trees [2]interface{}
+
+	for i, b := range [][]byte{old, new} {
+		normalized, err := Normalize(b)
+
+		if err != nil {
+			return nil, err
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(normalized))
+		decoder.UseNumber()
+
+		if err := decoder.Decode(&trees[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	var differences []Difference
+
+	diffValues("", "", trees[0], trees[1], &differences)
+
+	sort.SliceStable(differences, func(i, j int) bool {
+		return differences[i].Path < differences[j].Path
+	})
+
+	return differences, nil
+}
+
+func encodeValue(value interface{}) string {
+	b, _ := json.Marshal(value)
+	return string(b)
+}
+
+func diffValues(path, key string, old, new interface{}, differences *[]Difference) {
+	switch o := old.(type) {
+	case map[string]interface{}:
+		if n, ok := new.(map[string]interface{}); ok {
+			diffObjects(path, o, n, differences)
+			return
+		}
+	case []interface{}:
+		if n, ok := new.([]interface{}); ok {
+			diffArrays(path, key, o, n, differences)
+			return
+		}
+	}
+
+	if encodeValue(old) != encodeValue(new) {
+		*differences = append(*differences, Difference{Path: path, Kind: DiffChanged, Old: encodeValue(old), New: encodeValue(new)})
+	}
+}
+
+func diffObjects(path string, old, new map[string]interface{}, differences *[]Difference) {
+	for key, value := range old {
+		if newValue, ok := new[key]; ok {
+			diffValues(path+"/"+key, key, value, newValue, differences)
+		} else {
+			*differences = append(*differences, Difference{Path: path + "/" + key, Kind: DiffRemoved, Old: encodeValue(value)})
+		}
+	}
+
+	for key, value := range new {
+		if _, ok := old[key]; !ok {
+			*differences = append(*differences, Difference{Path: path + "/" + key, Kind: DiffAdded, New: encodeValue(value)})
+		}
+	}
+}
+
+// elementKey identifies an array element. Objects use their first identity
+// member present, strings are their own key and anything else is identified
+// by its encoding, so arrays such as status and roles behave as sets.
+func elementKey(element interface{}) string {
+	if s, ok := element.(string); ok {
+		return s
+	}
+
+	if object, ok := element.(map[string]interface{}); ok {
+		for _, member := range identityMembers {
+			if value, ok := object[member].(string); ok {
+				return value
+			}
+		}
+	}
+
+	return encodeValue(element)
+}
+
+func diffArrays(path, key string, old, new []interface{}, differences *[]Difference) {
+	if orderedArrays[key] {
+		if encodeValue(old) != encodeValue(new) {
+			*differences = append(*differences, Difference{Path: path, Kind: DiffChanged, Old: encodeValue(old), New: encodeValue(new)})
+		}
+
+		return
+	}
+
+	index := func(elements []interface{}) ([]string, map[string]interface{}) {
+		var (
+			keys   []string
+			byKey  = map[string]interface{}{}
+			counts = map[string]int{}
+		)
+
+		for _, element := range elements {
+			k := elementKey(element)
+
+			// Repeated keys, e.g. two events with the same action, are
+			// disambiguated by occurrence.
+			if counts[k]++; counts[k] > 1 {
+				k += "#" + strconv.Itoa(counts[k])
+			}
+
+			keys = append(keys, k)
+			byKey[k] = element
+		}
+
+		return keys, byKey
+	}
+
+	oldKeys, oldElements := index(old)
+	newKeys, newElements := index(new)
+
+	for _, k := range oldKeys {
+		elementPath := path + "[" + k + "]"
+
+		if newElement, ok := newElements[k]; ok {
+			diffValues(elementPath, "", oldElements[k], newElement, differences)
+		} else {
+			*differences = append(*differences, Difference{Path: elementPath, Kind: DiffRemoved, Old: encodeValue(oldElements[k])})
+		}
+	}
+
+	for _, k := range newKeys {
+		if _, ok := oldElements[k]; !ok {
+			*differences = append(*differences, Difference{Path: path + "[" + k + "]", Kind: DiffAdded, New: encodeValue(newElements[k])})
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package protocol
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		description string
+		old         string
+		new         string
+		expected    []string
+	}{
+		{
+			description: "it should ignore reordered arrays and volatile members",
+			old: `{
+				"ldhName": "example.com",
+				"status": ["active", "client transfer prohibited"],
+				"nameservers": [{"ldhName": "ns1.example.net"}, {"ldhName": "ns2.example.net"}],
+				"events": [
+					{"eventAction": "registration", "eventDate": "2010-01-01T00:00:00Z"},
+					{"eventAction": "last update of RDAP database", "eventDate": "2024-01-01T00:00:00Z"}
+				]
+			}`,
+			new: `{
+				"ldhName": "example.com",
+				"status": ["client transfer prohibited", "active"],
+				"nameservers": [{"ldhName": "ns2.example.net"}, {"ldhName": "ns1.example.net"}],
+				"notices": [{"title": "Terms of Use"}],
+				"events": [
+					{"eventAction": "last update of RDAP database", "eventDate": "2024-02-01T00:00:00Z"},
+					{"eventAction": "registration", "eventDate": "2010-01-01T00:00:00Z"}
+				]
+			}`,
+		},
+		{
+			description: "it should report field level changes",
+			old: `{
+				"ldhName": "example.com",
+				"port43": "whois.example.com",
+				"status": ["active"],
+				"entities": [{"handle": "REG-1", "roles": ["registrar"]}, {"handle": "C-1", "roles": ["registrant"]}],
+				"events": [{"eventAction": "expiration", "eventDate": "2025-01-01T00:00:00Z"}]
+			}`,
+			new: `{
+				"ldhName": "example.com",
+				"status": ["active", "pending transfer"],
+				"entities": [{"handle": "C-1", "roles": ["registrant", "administrative"]}, {"handle": "REG-2", "roles": ["registrar"]}],
+				"events": [{"eventAction": "expiration", "eventDate": "2026-01-01T00:00:00Z"}],
+				"secureDNS": {"delegationSigned": true}
+			}`,
+			expected: []string{
+				`+ /entities[C-1]/roles[administrative]: "administrative"`,
+				`- /entities[REG-1]: {"handle":"REG-1","roles":["registrar"]}`,
+				`+ /entities[REG-2]: {"handle":"REG-2","roles":["registrar"]}`,
+				`~ /events[expiration]/eventDate: "2025-01-01T00:00:00Z" -> "2026-01-01T00:00:00Z"`,
+				`- /port43: "whois.example.com"`,
+				`+ /secureDNS: {"delegationSigned":true}`,
+				`+ /status[pending transfer]: "pending transfer"`,
+			},
+		},
+	}
+
+	for i, test := range tests {
+		differences, err := Diff([]byte(test.old), []byte(test.new))
+
+		if err != nil {
+			t.Fatalf("At index %d (%s): unexpected error %s", i, test.description, err)
+		}
+
+		var report []string
+
+		for _, difference := range differences {
+			report = append(report, difference.String())
+		}
+
+		if !reflect.DeepEqual(test.expected, report) {
+			t.Fatalf("At index %d (%s): expected %q, got %q", i, test.description, test.expected, report)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package protocol
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type SecureDNS struct {
+	ZoneSigned       bool      `json:"zoneSigned"`
+	DelegationSigned bool      `json:"delegationSigned"`
+	MaxSigLife       uint64    `json:"maxSigLife,omitempty"`
+	DSData           []DSData  `json:"dsData,omitempty"`
+	KeyData          []KeyData `json:"keyData,omitempty"`
+	Unknown          Members   `json:"-"`
+}
+
+type DSData struct {
+	KeyTag     uint16  `json:"keyTag"`
+	Algorithm  uint8   `json:"algorithm"`
+	Digest     string  `json:"digest"`
+	DigestType uint8   `json:"digestType"`
+	Events     Events  `json:"events,omitempty"`
+	Links      []Link  `json:"links,omitempty"`
+	Unknown    Members `json:"-"`
+}
+
+type KeyData struct {
+	Flags     uint16  `json:"flags"`
+	Protocol  uint8   `json:"protocol"`
+	PublicKey string  `json:"publicKey"`
+	Algorithm uint8   `json:"algorithm"`
+	Events    Events  `json:"events,omitempty"`
+	Links     []Link  `json:"links,omitempty"`
+	Unknown   Members `json:"-"`
+}
+
+var algorithmNames = map[uint8]string{
+	1:  "RSAMD5",
+	3:  "DSA",
+	5:  "RSASHA1",
+	6:  "DSA-NSEC3-SHA1",
+	7:  "RSASHA1-NSEC3-SHA1",
+	8:  "RSASHA256",
+	10: "RSASHA512",
+	12: "ECC-GOST",
+	13: "ECDSAP256SHA256",
+	14: "ECDSAP384SHA384",
+	15: "ED25519",
+	16: "ED448",
+}
+
+var digestTypeNames = map[uint8]string{
+	1: "SHA-1",
+	2: "SHA-256",
+	3: "GOST R 34.11-94",
+	4: "SHA-384",
+}
+
+func AlgorithmName(algorithm uint8) string {
+	if name, ok := algorithmNames[algorithm]; ok {
+		return name
+	}
+
+	return strconv.Itoa(int(algorithm))
+}
+
+func DigestTypeName(digestType uint8) string {
+	if name, ok := digestTypeNames[digestType]; ok {
+		return name
+	}
+
+	return strconv.Itoa(int(digestType))
+}
+
+func (d DSData) String() string {
+	return fmt.Sprintf("DS key tag %d, algorithm %s, digest %s %s", d.KeyTag, AlgorithmName(d.Algorithm), DigestTypeName(d.DigestType), d.Digest)
+}
+
+func (k KeyData) String() string {
+	return fmt.Sprintf("DNSKEY flags %d, protocol %d, algorithm %s, key %s", k.Flags, k.Protocol, AlgorithmName(k.Algorithm), k.PublicKey)
+}
@@ -0,0 +1,58 @@
+package protocol
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSecureDNS(t *testing.T) {
+	var domain Domain
+
+	err := UnmarshalLenient([]byte(`{
+		"objectClassName": "domain",
+		"secureDNS": {
+			"zoneSigned": true,
+			"delegationSigned": "true",
+			"dsData": [{"keyTag": "12345", "algorithm": 13, "digestType": 2, "digest": "ABCDEF"}],
+			"keyData": {"flags": 257, "protocol": 3, "algorithm": 99, "publicKey": "AwEAAQ=="}
+		}
+	}`), &domain)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &SecureDNS{
+		ZoneSigned:       true,
+		DelegationSigned: true,
+		DSData:           []DSData{{KeyTag: 12345, Algorithm: 13, DigestType: 2, Digest: "ABCDEF"}},
+		KeyData:          []KeyData{{Flags: 257, Protocol: 3, Algorithm: 99, PublicKey: "AwEAAQ=="}},
+	}
+
+	if !reflect.DeepEqual(expected, domain.SecureDNS) {
+		t.Fatalf("expected %+v, got %+v", expected, domain.SecureDNS)
+	}
+
+	tests := []struct {
+		description string
+		value       interface{ String() string }
+		expected    string
+	}{
+		{
+			description: "it should render ds data with algorithm and digest names",
+			value:       domain.SecureDNS.DSData[0],
+			expected:    "DS key tag 12345, algorithm ECDSAP256SHA256, digest SHA-256 ABCDEF",
+		},
+		{
+			description: "it should render key data with unknown algorithms as numbers",
+			value:       domain.SecureDNS.KeyData[0],
+			expected:    "DNSKEY flags 257, protocol 3, algorithm 99, key AwEAAQ==",
+		},
+	}
+
+	for i, test := range tests {
+		if test.value.String() != test.expected {
+			t.Fatalf("At index %d (%s): expected %q, got %q", i, test.description, test.expected, test.value.String())
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package protocol
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Error is the error response of RFC 9083 section 6 that servers send with
+// 4xx and 5xx statuses. It implements error.
+type Error struct {
+	RDAPConformance []string `json:"rdapConformance,omitempty"`
+	Notices         []Notice `json:"notices,omitempty"`
+	ErrorCode       int      `json:"errorCode"`
+	Title           string   `json:"title,omitempty"`
+	Description     []string `json:"description,omitempty"`
+	Lang            string   `json:"lang,omitempty"`
+	Unknown         Members  `json:"-"`
+}
+
+func (e *Error) Error() string {
+	message := "rdap error " + strconv.Itoa(e.ErrorCode)
+
+	if e.Title != "" {
+		message += ": " + e.Title
+	}
+
+	if len(e.Description) > 0 {
+		message += " (" + strings.Join(e.Description, " ") + ")"
+	}
+
+	return message
+}
+
+// String renders the error as indented plain text followed by its notices,
+// for display to a user.
+func (e *Error) String() string {
+	lines := []string{fmt.Sprintf("Error %d", e.ErrorCode)}
+
+	if e.Title != "" {
+		lines[0] += ": " + e.Title
+	}
+
+	for _, description := range e.Description {
+		lines = append(lines, "  "+description)
+	}
+
+	for _, notice := range e.Notices {
+		lines = append(lines, "", notice.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
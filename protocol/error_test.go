@@ -0,0 +1,36 @@
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	var rdapError Error
+
+	body := `{
+		"rdapConformance": ["rdap_level_0"],
+		"errorCode": 404,
+		"title": "Not Found",
+		"description": ["The domain was not found."],
+		"notices": [{"title": "Terms of Use", "description": ["Be nice."]}]
+	}`
+
+	if err := json.Unmarshal([]byte(body), &rdapError); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "rdap error 404: Not Found (The domain was not found.)"; rdapError.Error() != expected {
+		t.Fatalf("expected %q, got %q", expected, rdapError.Error())
+	}
+
+	expected := "Error 404: Not Found\n" +
+		"  The domain was not found.\n" +
+		"\n" +
+		"Terms of Use\n" +
+		"  Be nice."
+
+	if rdapError.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, rdapError.String())
+	}
+}
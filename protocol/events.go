@@ -0,0 +1,70 @@
+package protocol
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	EventRegistration = "registration"
+	EventExpiration   = "expiration"
+	EventLastChanged  = "last changed"
+	EventTransfer     = "transfer"
+	EventDeletion     = "deletion"
+	EventReregistered = "reregistration"
+	EventLastUpdate   = "last update of RDAP database"
+)
+
+func (e Events) Len() int {
+	return len(e)
+}
+
+func (e Events) Swap(i, j int) {
+	e[i], e[j] = e[j], e[i]
+}
+
+func (e Events) Less(i, j int) bool {
+	return e[i].Date.Before(e[j].Date)
+}
+
+// Sorted returns a chronologically ordered copy of e, leaving e untouched.
+func (e Events) Sorted() Events {
+	sorted := append(Events(nil), e...)
+	sort.Stable(sorted)
+
+	return sorted
+}
+
+// Latest returns the most recent event with the given action.
+func (e Events) Latest(action string) (Event, bool) {
+	var (
+		latest Event
+		found  bool
+	)
+
+	for _, event := range e {
+		if event.Action == action && (!found || event.Date.After(latest.Date)) {
+			latest = event
+			found = true
+		}
+	}
+
+	return latest, found
+}
+
+func (e Events) date(action string) (time.Time, bool) {
+	event, ok := e.Latest(action)
+	return event.Date, ok
+}
+
+func (e Events) RegistrationDate() (time.Time, bool) {
+	return e.date(EventRegistration)
+}
+
+func (e Events) ExpirationDate() (time.Time, bool) {
+	return e.date(EventExpiration)
+}
+
+func (e Events) LastChanged() (time.Time, bool) {
+	return e.date(EventLastChanged)
+}
@@ -0,0 +1,72 @@
+package protocol
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEvents(t *testing.T) {
+	var (
+		registered = time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+		changed    = time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC)
+		rechanged  = time.Date(2016, 6, 1, 0, 0, 0, 0, time.UTC)
+		expires    = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	events := Events{
+		{Action: EventExpiration, Date: expires},
+		{Action: EventLastChanged, Date: rechanged},
+		{Action: EventRegistration, Date: registered},
+		{Action: EventLastChanged, Date: changed},
+	}
+
+	tests := []struct {
+		description string
+		accessor    func() (time.Time, bool)
+		expected    time.Time
+		found       bool
+	}{
+		{
+			description: "it should find the registration date",
+			accessor:    events.RegistrationDate,
+			expected:    registered,
+			found:       true,
+		},
+		{
+			description: "it should find the expiration date",
+			accessor:    events.ExpirationDate,
+			expected:    expires,
+			found:       true,
+		},
+		{
+			description: "it should find the latest change",
+			accessor:    events.LastChanged,
+			expected:    rechanged,
+			found:       true,
+		},
+		{
+			description: "it should not find events that are absent",
+			accessor:    Events{}.RegistrationDate,
+		},
+	}
+
+	for i, test := range tests {
+		date, found := test.accessor()
+
+		if found != test.found || !date.Equal(test.expected) {
+			t.Fatalf("At index %d (%s): expected %v (%t), got %v (%t)", i, test.description, test.expected, test.found, date, found)
+		}
+	}
+
+	sorted := events.Sorted()
+	expected := Events{events[2], events[3], events[1], events[0]}
+
+	if !reflect.DeepEqual(expected, sorted) {
+		t.Fatalf("expected %v, got %v", expected, sorted)
+	}
+
+	if events[0].Action != EventExpiration {
+		t.Fatalf("expected Sorted to leave the original events untouched")
+	}
+}
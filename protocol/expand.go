@@ -0,0 +1,97 @@
+package protocol
+
+import "sync"
+
+// ExpandEntities replaces every entity in entities, at any depth, that carries
+// a handle but no contact details with the entity returned by lookup. Each
+// handle is looked up once, with at most concurrency lookups in flight. The
+// roles an entity holds in its parent object are kept. The error of the first
+// handle, in the order entities appear, whose lookup failed is returned;
+// entities whose lookup failed are left untouched.
+func ExpandEntities(entities []Entity, concurrency int, lookup func(handle string) (Entity, error)) error {
+	var (
+		pending = map[string][]*Entity{}
+		handles []string
+		results = map[string]Entity{}
+		errs    = map[string]error{}
+		mutex   sync.Mutex
+		wg      sync.WaitGroup
+	)
+
+	collectUnexpanded(entities, pending, &handles)
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	semaphore := make(chan struct{}, concurrency)
+
+	for _, handle := range handles {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(handle string) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			entity, err := lookup(handle)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if err != nil {
+				errs[handle] = err
+				return
+			}
+
+			results[handle] = entity
+		}(handle)
+	}
+
+	wg.Wait()
+
+	var firstErr error
+
+	for _, handle := range handles {
+		if err, ok := errs[handle]; ok {
+			if firstErr == nil {
+				firstErr = err
+			}
+
+			continue
+		}
+
+		for _, target := range pending[handle] {
+			expanded := results[handle]
+
+			if len(target.Roles) > 0 {
+				expanded.Roles = target.Roles
+			}
+
+			*target = expanded
+		}
+	}
+
+	return firstErr
+}
+
+// collectUnexpanded adds the entities to expand to pending, keyed by handle,
+// and appends each handle to handles the first time it is seen.
+func collectUnexpanded(entities []Entity, pending map[string][]*Entity, handles *[]string) {
+	for i := range entities {
+		entity := &entities[i]
+
+		if entity.Handle != "" && entity.VCardArray == nil {
+			if _, ok := pending[entity.Handle]; !ok {
+				*handles = append(*handles, entity.Handle)
+			}
+
+			pending[entity.Handle] = append(pending[entity.Handle], entity)
+			continue
+		}
+
+		collectUnexpanded(entity.Entities, pending, handles)
+	}
+}
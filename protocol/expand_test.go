@@ -0,0 +1,82 @@
+package protocol
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestExpandEntities(t *testing.T) {
+	var (
+		mutex   sync.Mutex
+		lookups = map[string]int{}
+		vcard   = []interface{}{"vcard", []interface{}{}}
+	)
+
+	lookup := func(handle string) (Entity, error) {
+		mutex.Lock()
+		lookups[handle]++
+		mutex.Unlock()
+
+		if handle == "MISSING" || handle == "GONE" {
+			return Entity{}, fmt.Errorf("entity %s not found", handle)
+		}
+
+		return Entity{ObjectClassName: "entity", Handle: handle, VCardArray: vcard, Roles: []string{"registrant"}}, nil
+	}
+
+	entities := []Entity{
+		{Handle: "ABC", Roles: []string{"technical"}},
+		{
+			Handle:     "REGISTRAR",
+			VCardArray: vcard,
+			Entities:   []Entity{{Handle: "ABC", Roles: []string{"abuse"}}, {Handle: "DEF"}},
+		},
+	}
+
+	if err := ExpandEntities(entities, 2, lookup); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Entity{
+		{ObjectClassName: "entity", Handle: "ABC", VCardArray: vcard, Roles: []string{"technical"}},
+		{
+			Handle:     "REGISTRAR",
+			VCardArray: vcard,
+			Entities: []Entity{
+				{ObjectClassName: "entity", Handle: "ABC", VCardArray: vcard, Roles: []string{"abuse"}},
+				{ObjectClassName: "entity", Handle: "DEF", VCardArray: vcard, Roles: []string{"registrant"}},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, entities) {
+		t.Fatalf("expected %+v, got %+v", expected, entities)
+	}
+
+	if !reflect.DeepEqual(map[string]int{"ABC": 1, "DEF": 1}, lookups) {
+		t.Fatalf("expected each handle to be looked up once, got %v", lookups)
+	}
+
+	missing := []Entity{{Handle: "MISSING"}}
+	err := ExpandEntities(missing, 1, lookup)
+
+	if fmt.Sprintf("%v", err) != "entity MISSING not found" {
+		t.Fatalf("expected lookup error, got %v", err)
+	}
+
+	if !reflect.DeepEqual([]Entity{{Handle: "MISSING"}}, missing) {
+		t.Fatalf("expected failed entity to be left untouched, got %+v", missing)
+	}
+
+	// Several failures report the first handle, whichever lookup fails
+	// first.
+	for i := 0; i < 20; i++ {
+		err := ExpandEntities([]Entity{{Handle: "GONE"}, {Handle: "ABC"}, {Handle: "MISSING"}}, 3, lookup)
+
+		if fmt.Sprintf("%v", err) != "entity GONE not found" {
+			t.Fatalf("expected the error of the first failed handle, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package protocol
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ExtensionDecoder decodes the members an RDAP extension adds to an object
+// of class objectClassName, given the members the object has no field for.
+// It returns nil when the object carries none of the extension's members.
+type ExtensionDecoder func(objectClassName string, members Members) (interface{}, error)
+
+var (
+	extensionsMutex sync.RWMutex
+	extensions      = map[string]ExtensionDecoder{}
+)
+
+// RegisterExtension makes decode run on every object of a response whose
+// rdapConformance lists identifier, storing its result in the object's
+// Extensions under identifier. It panics if decode is nil or identifier is
+// already registered.
+func RegisterExtension(identifier string, decode ExtensionDecoder) {
+	extensionsMutex.Lock()
+	defer extensionsMutex.Unlock()
+
+	if decode == nil {
+		panic("protocol: RegisterExtension decoder is nil")
+	}
+
+	if _, ok := extensions[identifier]; ok {
+		panic("protocol: RegisterExtension called twice for " + identifier)
+	}
+
+	extensions[identifier] = decode
+}
+
+// decodeExtensions runs the decoders registered for the identifiers in
+// conformance over root and every object nested in it.
+func decodeExtensions(root interface{}, conformance []string) error {
+	extensionsMutex.RLock()
+	defer extensionsMutex.RUnlock()
+
+	var decoders []string
+
+	for _, identifier := range conformance {
+		if _, ok := extensions[identifier]; ok {
+			decoders = append(decoders, identifier)
+		}
+	}
+
+	if len(decoders) == 0 {
+		return nil
+	}
+
+	return walkObjects(reflect.ValueOf(root), func(d *Diagnostics, objectClassName string, members Members) error {
+		for _, identifier := range decoders {
+			value, err := extensions[identifier](objectClassName, members)
+
+			if err != nil {
+				return fmt.Errorf("extension %s: %w", identifier, err)
+			}
+
+			if value == nil {
+				continue
+			}
+
+			if d.Extensions == nil {
+				d.Extensions = map[string]interface{}{}
+			}
+
+			d.Extensions[identifier] = value
+		}
+
+		return nil
+	})
+}
+
+var diagnosticsType = reflect.TypeOf(Diagnostics{})
+
+// walkObjects calls visit for every object class instance reachable from v,
+// recognized by its embedded Diagnostics and objectClassName.
+func walkObjects(v reflect.Value, visit func(*Diagnostics, string, Members) error) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+
+		return walkObjects(v.Elem(), visit)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := walkObjects(v.Index(i), visit); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		if field, ok := v.Type().FieldByName("Diagnostics"); ok && field.Anonymous && field.Type == diagnosticsType && v.CanAddr() && v.FieldByName("ObjectClassName").IsValid() {
+			d := v.FieldByIndex(field.Index).Addr().Interface().(*Diagnostics)
+			members, _ := v.FieldByName("Unknown").Interface().(Members)
+
+			if err := visit(d, v.FieldByName("ObjectClassName").String(), members); err != nil {
+				return err
+			}
+		}
+
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				if err := walkObjects(v.Field(i), visit); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type cidr0 struct {
+	V4Prefix string `json:"v4prefix"`
+	Length   int    `json:"length"`
+}
+
+func init() {
+	RegisterExtension("cidr0_test", func(objectClassName string, members Members) (interface{}, error) {
+		raw, ok := members["cidr0_cidrs"]
+
+		if !ok || objectClassName != "ip network" {
+			return nil, nil
+		}
+
+		var cidrs []cidr0
+		err := json.Unmarshal(raw, &cidrs)
+
+		return cidrs, err
+	})
+}
+
+func TestRegisterExtension(t *testing.T) {
+	tests := []struct {
+		description   string
+		json          string
+		expected      interface{}
+		expectedError error
+	}{
+		{
+			description: "it should decode extensions the response declares",
+			json: `{"objectClassName": "entity", "rdapConformance": ["rdap_level_0", "cidr0_test"], "networks": [
+				{"objectClassName": "ip network", "cidr0_cidrs": [{"v4prefix": "192.0.2.0", "length": 24}]}
+			]}`,
+			expected: []cidr0{{V4Prefix: "192.0.2.0", Length: 24}},
+		},
+		{
+			description: "it should ignore extensions the response doesn't declare",
+			json: `{"objectClassName": "entity", "rdapConformance": ["rdap_level_0"], "networks": [
+				{"objectClassName": "ip network", "cidr0_cidrs": [{"v4prefix": "192.0.2.0", "length": 24}]}
+			]}`,
+		},
+		{
+			description: "it should report extensions that fail to decode",
+			json: `{"objectClassName": "entity", "rdapConformance": ["cidr0_test"], "networks": [
+				{"objectClassName": "ip network", "cidr0_cidrs": {}}
+			]}`,
+			expectedError: fmt.Errorf("extension cidr0_test: json: cannot unmarshal object into Go value of type []protocol.cidr0"),
+		},
+	}
+
+	for i, test := range tests {
+		var entity Entity
+		err := json.Unmarshal([]byte(test.json), &entity)
+
+		if fmt.Sprintf("%v", test.expectedError) != fmt.Sprintf("%v", err) {
+			t.Fatalf("At index %d (%s): expected error %v, got %v", i, test.description, test.expectedError, err)
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if entity.Extensions != nil {
+			t.Fatalf("At index %d (%s): expected no extensions on the entity, got %v", i, test.description, entity.Extensions)
+		}
+
+		if extension := entity.Networks[0].Extensions["cidr0_test"]; !reflect.DeepEqual(test.expected, extension) {
+			t.Fatalf("At index %d (%s): expected %v, got %v", i, test.description, test.expected, extension)
+		}
+	}
+}
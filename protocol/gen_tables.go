@@ -0,0 +1,213 @@
+//go:build ignore
+
+// gen_tables writes idna_tables.go, the Unicode data ToASCII normalizes
+// with, from the Unicode Character Database:
+//
+//	go run gen_tables.go [-ucd https://www.unicode.org/Public/15.1.0/ucd]
+//
+// The -ucd flag also accepts a local directory holding UnicodeData.txt and
+// CompositionExclusions.txt.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	ucd := flag.String("ucd", "https://www.unicode.org/Public/15.1.0/ucd", "URL or directory of the Unicode Character Database")
+	output := flag.String("output", "idna_tables.go", "file to write")
+	flag.Parse()
+
+	version := regexp.MustCompile(`\d+\.\d+\.\d+`).FindString(*ucd)
+
+	if version == "" {
+		log.Fatalf("no Unicode version in %s", *ucd)
+	}
+
+	var (
+		classes       = map[rune]uint8{}
+		decomposition = map[rune][]rune{}
+		width         = map[rune]rune{}
+		exclusions    []rune
+	)
+
+	for _, fields := range readFields(*ucd, "UnicodeData.txt") {
+		r := parseRune(fields[0])
+
+		if class, _ := strconv.Atoi(fields[3]); class != 0 {
+			classes[r] = uint8(class)
+		}
+
+		if fields[5] == "" {
+			continue
+		}
+
+		mapping := strings.Fields(fields[5])
+
+		switch mapping[0] {
+		case "<wide>", "<narrow>":
+			if len(mapping) == 2 {
+				width[r] = parseRune(mapping[1])
+			}
+		default:
+			if !strings.HasPrefix(mapping[0], "<") {
+				for _, m := range mapping {
+					decomposition[r] = append(decomposition[r], parseRune(m))
+				}
+			}
+		}
+	}
+
+	for _, fields := range readFields(*ucd, "CompositionExclusions.txt") {
+		exclusions = append(exclusions, parseRune(fields[0]))
+	}
+
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "// Code generated by gen_tables.go from Unicode %s; DO NOT EDIT.\n\n", version)
+	fmt.Fprintf(&b, "package protocol\n\n")
+
+	fmt.Fprintf(&b, "// canonicalClasses holds the non-zero canonical combining classes.\nvar canonicalClasses = map[rune]uint8{")
+	writeEntries(&b, classes, func(v uint8) string { return strconv.Itoa(int(v)) })
+	fmt.Fprintf(&b, "}\n\n")
+
+	fmt.Fprintf(&b, "// canonicalDecompositions holds the canonical decomposition mappings,\n// one level deep.\nvar canonicalDecompositions = map[rune][]rune{")
+	writeEntries(&b, decomposition, func(v []rune) string {
+		var runes []string
+
+		for _, r := range v {
+			runes = append(runes, fmt.Sprintf("0x%04X", r))
+		}
+
+		return "{" + strings.Join(runes, ", ") + "}"
+	})
+	fmt.Fprintf(&b, "}\n\n")
+
+	fmt.Fprintf(&b, "// compositionExclusions lists the script-specific and post composition\n// version exclusions, which NFC doesn't recompose.\nvar compositionExclusions = []rune{")
+
+	for i, r := range exclusions {
+		if i%8 == 0 {
+			b.WriteString("\n")
+		}
+
+		fmt.Fprintf(&b, "0x%04X, ", r)
+	}
+
+	fmt.Fprintf(&b, "\n}\n\n")
+
+	fmt.Fprintf(&b, "// widthMappings maps the fullwidth and halfwidth forms to the characters\n// they stand for.\nvar widthMappings = map[rune]rune{")
+	writeEntries(&b, width, func(v rune) string { return fmt.Sprintf("0x%04X", v) })
+	fmt.Fprintf(&b, "}\n")
+
+	source, err := format.Source(b.Bytes())
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(*output, source, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readFields returns the semicolon-separated fields of the lines of name,
+// without comments and blank lines.
+func readFields(ucd, name string) [][]string {
+	var r io.Reader
+
+	if strings.HasPrefix(ucd, "http://") || strings.HasPrefix(ucd, "https://") {
+		resp, err := http.Get(strings.TrimSuffix(ucd, "/") + "/" + name)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			log.Fatalf("unexpected status %d fetching %s", resp.StatusCode, name)
+		}
+
+		r = resp.Body
+	} else {
+		f, err := os.Open(filepath.Join(ucd, name))
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		defer f.Close()
+		r = f
+	}
+
+	var lines [][]string
+
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields := strings.Split(line, ";")
+
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+
+		lines = append(lines, fields)
+	}
+
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	return lines
+}
+
+func parseRune(s string) rune {
+	n, err := strconv.ParseUint(s, 16, 32)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return rune(n)
+}
+
+// writeEntries writes the entries of m in code point order, eight to a
+// line.
+func writeEntries[V any](b *bytes.Buffer, m map[rune]V, value func(V) string) {
+	keys := make([]rune, 0, len(m))
+
+	for r := range m {
+		keys = append(keys, r)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	for i, r := range keys {
+		if i%8 == 0 {
+			b.WriteString("\n")
+		}
+
+		fmt.Fprintf(b, "0x%04X: %s, ", r, value(m[r]))
+	}
+
+	b.WriteString("\n")
+}
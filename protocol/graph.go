@@ -0,0 +1,243 @@
+package protocol
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Graph accumulates RDAP objects and the relationships between them so that
+// several lookups can be drawn as one Graphviz graph. Objects are identified
+// by class and handle (or name), so an entity shared by two domains becomes a
+// single node.
+type Graph struct {
+	nodes map[string]string
+	edges map[[2]string][]string
+}
+
+func NewGraph() *Graph {
+	return &Graph{
+		nodes: map[string]string{},
+		edges: map[[2]string][]string{},
+	}
+}
+
+// Add records object, which must be one of the RDAP object types or a
+// pointer to one, along with everything it embeds.
+func (g *Graph) Add(object interface{}) error {
+	switch o := object.(type) {
+	case Domain:
+		g.addDomain(o)
+	case *Domain:
+		g.addDomain(*o)
+	case Entity:
+		g.addEntity(o)
+	case *Entity:
+		g.addEntity(*o)
+	case Nameserver:
+		g.addNameserver(o)
+	case *Nameserver:
+		g.addNameserver(*o)
+	case IPNetwork:
+		g.addIPNetwork(o)
+	case *IPNetwork:
+		g.addIPNetwork(*o)
+	case Autnum:
+		g.addAutnum(o)
+	case *Autnum:
+		g.addAutnum(*o)
+	default:
+		return fmt.Errorf("unsupported object type %T", object)
+	}
+
+	return nil
+}
+
+func (g *Graph) node(class, key, label string) string {
+	id := class + ":" + strings.ToLower(key)
+
+	if existing, ok := g.nodes[id]; !ok || (existing == key && label != key) {
+		g.nodes[id] = label
+	}
+
+	return id
+}
+
+func (g *Graph) edge(from, to, label string) {
+	key := [2]string{from, to}
+
+	for _, existing := range g.edges[key] {
+		if existing == label {
+			return
+		}
+	}
+
+	g.edges[key] = append(g.edges[key], label)
+}
+
+func (g *Graph) addEntities(from string, entities []Entity) {
+	for _, entity := range entities {
+		to := g.addEntity(entity)
+		label := strings.Join(entity.Roles, ", ")
+
+		if label == "" {
+			label = "entity"
+		}
+
+		g.edge(from, to, label)
+	}
+}
+
+func (g *Graph) addDomain(d Domain) string {
+	name := d.LDHName
+
+	if name == "" {
+		name = d.Handle
+	}
+
+	id := g.node("domain", name, d.DisplayName())
+
+	for _, nameserver := range d.Nameservers {
+		g.edge(id, g.addNameserver(nameserver), "nameserver")
+	}
+
+	if d.Network != nil {
+		g.edge(id, g.addIPNetwork(*d.Network), "network")
+	}
+
+	g.addEntities(id, d.Entities)
+
+	return id
+}
+
+func (g *Graph) addEntity(e Entity) string {
+	key, label := e.Handle, e.Handle
+
+	// Entities without a handle (often redacted contacts) can't be told
+	// apart, so each one gets its own node.
+	if key == "" {
+		key, label = fmt.Sprintf("#%d", len(g.nodes)), "(no handle)"
+	}
+
+	id := g.node("entity", key, label)
+
+	for _, network := range e.Networks {
+		g.edge(id, g.addIPNetwork(network), "network")
+	}
+
+	for _, autnum := range e.Autnums {
+		g.edge(id, g.addAutnum(autnum), "autnum")
+	}
+
+	g.addEntities(id, e.Entities)
+
+	return id
+}
+
+func (g *Graph) addNameserver(n Nameserver) string {
+	name := n.LDHName
+
+	if name == "" {
+		name = n.Handle
+	}
+
+	id := g.node("nameserver", name, name)
+	g.addEntities(id, n.Entities)
+
+	return id
+}
+
+func (g *Graph) addIPNetwork(n IPNetwork) string {
+	key := n.Handle
+
+	if key == "" {
+		key = n.StartAddress + " - " + n.EndAddress
+	}
+
+	label := n.StartAddress + " - " + n.EndAddress
+
+	if n.Name != "" {
+		label = n.Name + "\n" + label
+	}
+
+	id := g.node("ip network", key, label)
+	g.addEntities(id, n.Entities)
+
+	return id
+}
+
+func (g *Graph) addAutnum(a Autnum) string {
+	key := a.Handle
+
+	if key == "" {
+		key = fmt.Sprintf("AS%d-AS%d", a.StartAutnum, a.EndAutnum)
+	}
+
+	label := fmt.Sprintf("AS%d", a.StartAutnum)
+
+	if a.EndAutnum != a.StartAutnum && a.EndAutnum != 0 {
+		label = fmt.Sprintf("AS%d - AS%d", a.StartAutnum, a.EndAutnum)
+	}
+
+	if a.Name != "" {
+		label = a.Name + "\n" + label
+	}
+
+	id := g.node("autnum", key, label)
+	g.addEntities(id, a.Entities)
+
+	return id
+}
+
+var nodeShapes = map[string]string{
+	"domain":     "box",
+	"entity":     "ellipse",
+	"nameserver": "diamond",
+	"ip network": "component",
+	"autnum":     "hexagon",
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language. Nodes and edges are
+// sorted so the output is stable across runs.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var (
+		ids   []string
+		edges [][2]string
+		buf   = bufio.NewWriter(w)
+	)
+
+	for id := range g.nodes {
+		ids = append(ids, id)
+	}
+
+	for edge := range g.edges {
+		edges = append(edges, edge)
+	}
+
+	sort.Strings(ids)
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i][0] != edges[j][0] {
+			return edges[i][0] < edges[j][0]
+		}
+
+		return edges[i][1] < edges[j][1]
+	})
+
+	fmt.Fprintln(buf, "digraph rdap {")
+
+	for _, id := range ids {
+		class := strings.SplitN(id, ":", 2)[0]
+		fmt.Fprintf(buf, "\t%s [label=%s, shape=%s];\n", strconv.Quote(id), strconv.Quote(g.nodes[id]), nodeShapes[class])
+	}
+
+	for _, edge := range edges {
+		fmt.Fprintf(buf, "\t%s -> %s [label=%s];\n", strconv.Quote(edge[0]), strconv.Quote(edge[1]), strconv.Quote(strings.Join(g.edges[edge], ", ")))
+	}
+
+	fmt.Fprintln(buf, "}")
+
+	return buf.Flush()
+}
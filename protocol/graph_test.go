@@ -0,0 +1,71 @@
+package protocol
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestGraphWriteDOT(t *testing.T) {
+	registrar := Entity{Handle: "292", Roles: []string{"registrar"}}
+
+	graph := NewGraph()
+	objects := []interface{}{
+		Domain{
+			LDHName:     "example.com",
+			Nameservers: []Nameserver{{LDHName: "ns1.example.net"}},
+			Entities:    []Entity{registrar, {Roles: []string{"registrant"}}},
+		},
+		&Domain{
+			LDHName:  "example.org",
+			Entities: []Entity{registrar},
+		},
+		IPNetwork{
+			Handle:       "NET-192-0-2-0-1",
+			Name:         "EXAMPLE",
+			StartAddress: "192.0.2.0",
+			EndAddress:   "192.0.2.255",
+			Entities:     []Entity{{Handle: "ORG-1", Roles: []string{"registrant", "abuse"}, Autnums: []Autnum{{StartAutnum: 64512, EndAutnum: 64512}}}},
+		},
+	}
+
+	for _, object := range objects {
+		if err := graph.Add(object); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+
+	if err := graph.WriteDOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `digraph rdap {
+	"autnum:as64512-as64512" [label="AS64512", shape=hexagon];
+	"domain:example.com" [label="example.com", shape=box];
+	"domain:example.org" [label="example.org", shape=box];
+	"entity:#3" [label="(no handle)", shape=ellipse];
+	"entity:292" [label="292", shape=ellipse];
+	"entity:org-1" [label="ORG-1", shape=ellipse];
+	"ip network:net-192-0-2-0-1" [label="EXAMPLE\n192.0.2.0 - 192.0.2.255", shape=component];
+	"nameserver:ns1.example.net" [label="ns1.example.net", shape=diamond];
+	"domain:example.com" -> "entity:#3" [label="registrant"];
+	"domain:example.com" -> "entity:292" [label="registrar"];
+	"domain:example.com" -> "nameserver:ns1.example.net" [label="nameserver"];
+	"domain:example.org" -> "entity:292" [label="registrar"];
+	"entity:org-1" -> "autnum:as64512-as64512" [label="autnum"];
+	"ip network:net-192-0-2-0-1" -> "entity:org-1" [label="registrant, abuse"];
+}
+`
+
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	err := graph.Add("example.com")
+
+	if fmt.Sprintf("%v", err) != "unsupported object type string" {
+		t.Fatalf("expected unsupported object error, got %v", err)
+	}
+}
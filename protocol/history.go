@@ -0,0 +1,57 @@
+package protocol
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// History is the answer of the "history_version_0" extension, offered by
+// registries such as APNIC under /history/: every version of an object
+// the registry has kept, with when it applied.
+type History struct {
+	RDAPConformance []string        `json:"rdapConformance,omitempty"`
+	Notices         []Notice        `json:"notices,omitempty"`
+	Records         []HistoryRecord `json:"records"`
+	Unknown         Members         `json:"-"`
+}
+
+// HistoryRecord is one version of an object. ApplicableUntil is nil for the
+// current version.
+type HistoryRecord struct {
+	ApplicableFrom  time.Time       `json:"applicableFrom"`
+	ApplicableUntil *time.Time      `json:"applicableUntil,omitempty"`
+	Content         json.RawMessage `json:"content"`
+	Unknown         Members         `json:"-"`
+}
+
+// HistoryChange is what changed when a version of an object took effect.
+type HistoryChange struct {
+	Time        time.Time
+	Differences []Difference
+}
+
+// Timeline orders the records of h chronologically and returns the changes
+// between consecutive versions as found by Diff, skipping versions that
+// differ only in volatile members.
+func (h *History) Timeline() ([]HistoryChange, error) {
+	records := append([]HistoryRecord(nil), h.Records...)
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].ApplicableFrom.Before(records[j].ApplicableFrom) })
+
+	var changes []HistoryChange
+
+	for i := 1; i < len(records); i++ {
+		differences, err := Diff(records[i-1].Content, records[i].Content)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(differences) > 0 {
+			changes = append(changes, HistoryChange{Time: records[i].ApplicableFrom, Differences: differences})
+		}
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,325 @@
+package protocol
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Punycode parameters from RFC 3492, section 5.
+const (
+	punycodeBase        = 36
+	punycodeTMin        = 1
+	punycodeTMax        = 26
+	punycodeSkew        = 38
+	punycodeDamp        = 700
+	punycodeInitialBias = 72
+	punycodeInitialN    = 128
+
+	acePrefix = "xn--"
+)
+
+// ToASCII converts every U-label of domain to its A-label, preparing the
+// name as an IDNA lookup does: fullwidth and halfwidth forms are mapped to
+// the characters they stand for, the case is lowered and the labels are
+// put in Normalization Form C, so that "ＥＸＡＭＰＬＥ" and a decomposed
+// "e\u0301" give the A-labels of "example" and "é". This covers the UTS #46
+// mappings names are typed with, not its whole table.
+//
+// U-labels are then checked against the core of IDNA2008: letters, marks
+// and digits only, besides hyphens and the "*" of search patterns, no
+// leading mark, no hyphen at either end or in the third and fourth
+// positions, and A-labels of at most 63 characters. ASCII labels are left
+// for the server to judge.
+func ToASCII(domain string) (string, error) {
+	labels := strings.Split(strings.ToLower(mapWidth(domain)), ".")
+
+	for i, label := range labels {
+		if isASCII(label) {
+			continue
+		}
+
+		label = nfc(label)
+
+		if err := checkULabel(label); err != nil {
+			return "", fmt.Errorf("invalid label %q: %v", label, err)
+		}
+
+		encoded, err := punycodeEncode(label)
+
+		if err != nil {
+			return "", fmt.Errorf("invalid label %q: %v", label, err)
+		}
+
+		if len(acePrefix)+len(encoded) > 63 {
+			return "", fmt.Errorf("invalid label %q: longer than 63 characters encoded", label)
+		}
+
+		labels[i] = acePrefix + encoded
+	}
+
+	return strings.Join(labels, "."), nil
+}
+
+// checkULabel reports why label, in Normalization Form C, isn't a valid
+// U-label.
+func checkULabel(label string) error {
+	if !utf8.ValidString(label) {
+		return fmt.Errorf("not valid utf-8")
+	}
+
+	if r, _ := utf8.DecodeRuneInString(label); unicode.Is(unicode.M, r) {
+		return fmt.Errorf("starts with the combining mark %U", r)
+	}
+
+	if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") || len(label) >= 4 && label[2:4] == "--" {
+		return fmt.Errorf("misplaced hyphen")
+	}
+
+	for _, r := range label {
+		if r != '-' && r != '*' && !unicode.In(r, unicode.L, unicode.M, unicode.Nd) {
+			return fmt.Errorf("disallowed character %U", r)
+		}
+	}
+
+	return nil
+}
+
+// labelSeparators are the full stops IDNA accepts between labels besides
+// ".", as typed with East Asian input methods.
+var labelSeparators = strings.NewReplacer("\u3002", ".", "\uff0e", ".", "\uff61", ".")
+
+// NormalizeDomain returns the canonical form of domain that bootstrap
+// matching and query URLs use, so that "EXAMPLE.COM." and "exämple.com"
+// behave as "example.com" and "xn--exmple-cua.com": label separators become
+// dots, the trailing dot of an absolute name is dropped and ToASCII lowers
+// the case and encodes U-labels.
+func NormalizeDomain(domain string) (string, error) {
+	domain = labelSeparators.Replace(domain)
+
+	if domain != "." {
+		domain = strings.TrimSuffix(domain, ".")
+	}
+
+	return ToASCII(domain)
+}
+
+// ToUnicode converts every A-label of domain back to its U-label.
+func ToUnicode(domain string) (string, error) {
+	labels := strings.Split(domain, ".")
+
+	for i, label := range labels {
+		if !strings.HasPrefix(strings.ToLower(label), acePrefix) {
+			continue
+		}
+
+		decoded, err := punycodeDecode(strings.ToLower(label[len(acePrefix):]))
+
+		if err != nil {
+			return "", fmt.Errorf("invalid label %q: %v", label, err)
+		}
+
+		labels[i] = decoded
+	}
+
+	return strings.Join(labels, "."), nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
+
+func punycodeAdapt(delta, points int, first bool) int {
+	if first {
+		delta /= punycodeDamp
+	} else {
+		delta /= 2
+	}
+
+	delta += delta / points
+	k := 0
+
+	for delta > ((punycodeBase-punycodeTMin)*punycodeTMax)/2 {
+		delta /= punycodeBase - punycodeTMin
+		k += punycodeBase
+	}
+
+	return k + (punycodeBase-punycodeTMin+1)*delta/(delta+punycodeSkew)
+}
+
+func punycodeThreshold(k, bias int) int {
+	switch {
+	case k <= bias:
+		return punycodeTMin
+	case k >= bias+punycodeTMax:
+		return punycodeTMax
+	}
+
+	return k - bias
+}
+
+func punycodeEncodeDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+
+	return byte('0' + d - 26)
+}
+
+func punycodeDecodeDigit(c byte) (int, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c-'0') + 26, true
+	case c >= 'a' && c <= 'z':
+		return int(c - 'a'), true
+	case c >= 'A' && c <= 'Z':
+		return int(c - 'A'), true
+	}
+
+	return 0, false
+}
+
+func punycodeEncode(label string) (string, error) {
+	if !utf8.ValidString(label) {
+		return "", fmt.Errorf("not valid utf-8")
+	}
+
+	var (
+		input  = []rune(label)
+		output []byte
+		n      = punycodeInitialN
+		delta  = 0
+		bias   = punycodeInitialBias
+	)
+
+	for _, r := range input {
+		if r < utf8.RuneSelf {
+			output = append(output, byte(r))
+		}
+	}
+
+	basic := len(output)
+	handled := basic
+
+	if basic > 0 {
+		output = append(output, '-')
+	}
+
+	for handled < len(input) {
+		m := int(utf8.MaxRune) + 1
+
+		for _, r := range input {
+			if int(r) >= n && int(r) < m {
+				m = int(r)
+			}
+		}
+
+		delta += (m - n) * (handled + 1)
+		n = m
+
+		for _, r := range input {
+			if int(r) < n {
+				delta++
+			}
+
+			if int(r) != n {
+				continue
+			}
+
+			q := delta
+
+			for k := punycodeBase; ; k += punycodeBase {
+				t := punycodeThreshold(k, bias)
+
+				if q < t {
+					break
+				}
+
+				output = append(output, punycodeEncodeDigit(t+(q-t)%(punycodeBase-t)))
+				q = (q - t) / (punycodeBase - t)
+			}
+
+			output = append(output, punycodeEncodeDigit(q))
+			bias = punycodeAdapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+
+		delta++
+		n++
+	}
+
+	return string(output), nil
+}
+
+func punycodeDecode(encoded string) (string, error) {
+	var (
+		output []rune
+		n      = punycodeInitialN
+		i      = 0
+		bias   = punycodeInitialBias
+		pos    = 0
+	)
+
+	if b := strings.LastIndex(encoded, "-"); b >= 0 {
+		if !isASCII(encoded[:b]) {
+			return "", fmt.Errorf("non-basic code point before delimiter")
+		}
+
+		output = []rune(encoded[:b])
+		pos = b + 1
+	}
+
+	for pos < len(encoded) {
+		oldi, w := i, 1
+
+		for k := punycodeBase; ; k += punycodeBase {
+			if pos >= len(encoded) {
+				return "", fmt.Errorf("truncated input")
+			}
+
+			digit, ok := punycodeDecodeDigit(encoded[pos])
+			pos++
+
+			if !ok {
+				return "", fmt.Errorf("invalid digit %q", encoded[pos-1])
+			}
+
+			i += digit * w
+			t := punycodeThreshold(k, bias)
+
+			if digit < t {
+				break
+			}
+
+			w *= punycodeBase - t
+
+			if w > utf8.MaxRune {
+				return "", fmt.Errorf("overflow")
+			}
+		}
+
+		points := len(output) + 1
+		bias = punycodeAdapt(i-oldi, points, oldi == 0)
+		n += i / points
+		i %= points
+
+		if n > utf8.MaxRune {
+			return "", fmt.Errorf("overflow")
+		}
+
+		output = append(output, 0)
+		copy(output[i+1:], output[i:])
+		output[i] = rune(n)
+		i++
+	}
+
+	return string(output), nil
+}
@@ -0,0 +1,172 @@
+package protocol
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestIDNA(t *testing.T) {
+	tests := []struct {
+		description   string
+		unicode       string
+		ascii         string
+		expectedError error
+	}{
+		{
+			description: "it should convert a label with a single non-ascii rune",
+			unicode:     "münchen.de",
+			ascii:       "xn--mnchen-3ya.de",
+		},
+		{
+			description: "it should convert a label without basic code points",
+			unicode:     "テスト",
+			ascii:       "xn--zckzah",
+		},
+		{
+			description: "it should convert several labels",
+			unicode:     "bücher.ドメイン名例",
+			ascii:       "xn--bcher-kva.xn--eckwd4c7cu47r2wf",
+		},
+		{
+			description: "it should leave ascii names alone",
+			unicode:     "example.com",
+			ascii:       "example.com",
+		},
+	}
+
+	for i, test := range tests {
+		ascii, err := ToASCII(test.unicode)
+
+		if err != nil {
+			t.Fatalf("At index %d (%s): unexpected error %s", i, test.description, err)
+		}
+
+		if ascii != test.ascii {
+			t.Fatalf("At index %d (%s): expected %q, got %q", i, test.description, test.ascii, ascii)
+		}
+
+		unicode, err := ToUnicode(ascii)
+
+		if err != nil {
+			t.Fatalf("At index %d (%s): unexpected error %s", i, test.description, err)
+		}
+
+		if unicode != test.unicode {
+			t.Fatalf("At index %d (%s): expected %q, got %q", i, test.description, test.unicode, unicode)
+		}
+	}
+
+	_, err := ToUnicode("xn--a-!.com")
+	expectedError := fmt.Errorf("invalid label \"xn--a-!\": invalid digit '!'")
+
+	if fmt.Sprintf("%v", expectedError) != fmt.Sprintf("%v", err) {
+		t.Fatalf("expected error %s, got %s", expectedError, err)
+	}
+
+	if name := (Domain{LDHName: "xn--mnchen-3ya.de"}).DisplayName(); name != "xn--mnchen-3ya.de (münchen.de)" {
+		t.Fatalf("expected both forms of the name, got %q", name)
+	}
+}
+
+func TestToASCIIMapping(t *testing.T) {
+	tests := []struct {
+		description   string
+		domain        string
+		expected      string
+		expectedError error
+	}{
+		{
+			description: "it should compose decomposed characters",
+			domain:      "mu\u0308nchen.de",
+			expected:    "xn--mnchen-3ya.de",
+		},
+		{
+			description: "it should reorder combining marks before composing",
+			domain:      "a\u0323\u0302.vn",
+			expected:    "xn--zkg.vn",
+		},
+		{
+			description: "it should compose Hangul syllables",
+			domain:      "\u1112\u1161\u11ab.kr",
+			expected:    "xn--6q8b.kr",
+		},
+		{
+			description: "it should map fullwidth forms",
+			domain:      "ＥＸＡＭＰＬＥ.ｃｏｍ",
+			expected:    "example.com",
+		},
+		{
+			description: "it should map halfwidth katakana and compose their sound marks",
+			domain:      "ﾃｽﾄ.ｶﾞ",
+			expected:    "xn--zckzah.xn--mck",
+		},
+		{
+			description:   "it should reject symbols",
+			domain:        "i\u2665.example",
+			expectedError: fmt.Errorf("invalid label \"i\u2665\": disallowed character U+2665"),
+		},
+		{
+			description:   "it should reject a leading combining mark",
+			domain:        "\u0301a.example",
+			expectedError: fmt.Errorf("invalid label \"\u0301a\": starts with the combining mark U+0301"),
+		},
+		{
+			description:   "it should reject hyphens in the third and fourth positions",
+			domain:        "ab--ü.example",
+			expectedError: fmt.Errorf("invalid label \"ab--ü\": misplaced hyphen"),
+		},
+	}
+
+	for i, test := range tests {
+		ascii, err := ToASCII(test.domain)
+
+		if fmt.Sprintf("%v", test.expectedError) != fmt.Sprintf("%v", err) {
+			t.Fatalf("At index %d (%s): expected error %v, got %v", i, test.description, test.expectedError, err)
+		}
+
+		if ascii != test.expected {
+			t.Fatalf("At index %d (%s): expected %q, got %q", i, test.description, test.expected, ascii)
+		}
+	}
+}
+
+func TestNormalizeDomain(t *testing.T) {
+	tests := []struct {
+		description string
+		domain      string
+		expected    string
+	}{
+		{
+			description: "it should lower the case and drop the trailing dot",
+			domain:      "EXAMPLE.COM.",
+			expected:    "example.com",
+		},
+		{
+			description: "it should encode U-labels",
+			domain:      "Exämple.com",
+			expected:    "xn--exmple-cua.com",
+		},
+		{
+			description: "it should accept ideographic full stops",
+			domain:      "bücher。example",
+			expected:    "xn--bcher-kva.example",
+		},
+		{
+			description: "it should leave the root alone",
+			domain:      ".",
+			expected:    ".",
+		},
+	}
+
+	for i, test := range tests {
+		domain, err := NormalizeDomain(test.domain)
+
+		if err != nil {
+			t.Fatalf("At index %d (%s): unexpected error %s", i, test.description, err)
+		}
+
+		if domain != test.expected {
+			t.Fatalf("At index %d (%s): expected %q, got %q", i, test.description, test.expected, domain)
+		}
+	}
+}
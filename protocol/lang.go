@@ -0,0 +1,77 @@
+package protocol
+
+import "strings"
+
+// MatchLang reports whether the language tag tag, such as "en-US", falls
+// under the preferred language lang, such as "en". Tags compare
+// case-insensitively, and an empty lang matches every tag.
+func MatchLang(tag, lang string) bool {
+	tag, lang = strings.ToLower(tag), strings.ToLower(lang)
+
+	return lang == "" || tag == lang || strings.HasPrefix(tag, lang+"-")
+}
+
+// FilterNotices returns the notices or remarks to show a reader preferring
+// lang. When some of them are tagged lang, the ones tagged with another
+// language are left out; untagged ones, which are in the language of the
+// enclosing object, are always kept. It returns notices unchanged when none
+// is in lang, so that a reader never loses the only version there is.
+func FilterNotices(notices []Notice, lang string) []Notice {
+	keep := filterLang(len(notices), func(i int) string { return notices[i].Lang }, lang)
+
+	if keep == nil {
+		return notices
+	}
+
+	var filtered []Notice
+
+	for i, notice := range notices {
+		if keep[i] {
+			filtered = append(filtered, notice)
+		}
+	}
+
+	return filtered
+}
+
+// FilterEntities is FilterNotices for entities, which registries such as
+// JPNIC and KRNIC return once per language.
+func FilterEntities(entities []Entity, lang string) []Entity {
+	keep := filterLang(len(entities), func(i int) string { return entities[i].Lang }, lang)
+
+	if keep == nil {
+		return entities
+	}
+
+	var filtered []Entity
+
+	for i, entity := range entities {
+		if keep[i] {
+			filtered = append(filtered, entity)
+		}
+	}
+
+	return filtered
+}
+
+// filterLang returns which of n items tagged by tag to keep for lang, or
+// nil to keep them all.
+func filterLang(n int, tag func(int) string, lang string) []bool {
+	if lang == "" {
+		return nil
+	}
+
+	keep := make([]bool, n)
+	matched := false
+
+	for i := range keep {
+		keep[i] = tag(i) == "" || MatchLang(tag(i), lang)
+		matched = matched || tag(i) != "" && keep[i]
+	}
+
+	if !matched {
+		return nil
+	}
+
+	return keep
+}
@@ -0,0 +1,48 @@
+package protocol
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFilterNotices(t *testing.T) {
+	notices := []Notice{
+		{Title: "Terms"},
+		{Title: "Remarks", Lang: "ja"},
+		{Title: "Remarks", Lang: "en-US"},
+	}
+
+	tests := []struct {
+		description string
+		lang        string
+		expected    []string
+	}{
+		{
+			description: "it should keep the preferred language and untagged notices",
+			lang:        "EN",
+			expected:    []string{"Terms", "Remarks en-US"},
+		},
+		{
+			description: "it should keep everything when nothing is in the preferred language",
+			lang:        "de",
+			expected:    []string{"Terms", "Remarks ja", "Remarks en-US"},
+		},
+		{
+			description: "it should keep everything without a preference",
+			expected:    []string{"Terms", "Remarks ja", "Remarks en-US"},
+		},
+	}
+
+	for i, test := range tests {
+		var titles []string
+
+		for _, notice := range FilterNotices(notices, test.lang) {
+			titles = append(titles, strings.TrimSpace(notice.Title+" "+notice.Lang))
+		}
+
+		if fmt.Sprint(test.expected) != fmt.Sprint(titles) {
+			t.Fatalf("At index %d (%s): expected %q, got %q", i, test.description, test.expected, titles)
+		}
+	}
+}
@@ -0,0 +1,400 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Diagnostics records how an object was decoded: the raw JSON returned by
+// Raw, the deviations from RFC 9083 that were coerced away while decoding
+// a response with UnmarshalLenient, and the values decoded by registered
+// extensions, keyed by extension identifier.
+type Diagnostics struct {
+	Warnings   []string               `json:"-"`
+	Extensions map[string]interface{} `json:"-"`
+
+	raw json.RawMessage
+}
+
+func (d *Diagnostics) addWarnings(warnings ...string) {
+	d.Warnings = append(d.Warnings, warnings...)
+}
+
+type warner interface {
+	addWarnings(...string)
+	setRaw([]byte)
+}
+
+var (
+	arrayMembers = map[string]bool{
+		"rdapConformance": true,
+		"notices":         true,
+		"remarks":         true,
+		"description":     true,
+		"links":           true,
+		"hreflang":        true,
+		"events":          true,
+		"asEventActor":    true,
+		"entities":        true,
+		"roles":           true,
+		"publicIds":       true,
+		"status":          true,
+		"nameservers":     true,
+		"networks":        true,
+		"autnums":         true,
+		"v4":              true,
+		"v6":              true,
+		"dsData":          true,
+		"keyData":         true,
+		"variants":        true,
+		"relation":        true,
+		"variantNames":    true,
+
+		"domainSearchResults":     true,
+		"nameserverSearchResults": true,
+		"entitySearchResults":     true,
+	}
+
+	numberMembers = map[string]bool{
+		"startAutnum": true,
+		"endAutnum":   true,
+		"maxSigLife":  true,
+		"keyTag":      true,
+		"algorithm":   true,
+		"digestType":  true,
+		"flags":       true,
+		"protocol":    true,
+	}
+
+	boolMembers = map[string]bool{
+		"zoneSigned":       true,
+		"delegationSigned": true,
+	}
+
+	dateMembers = map[string]bool{
+		"eventDate": true,
+	}
+)
+
+// UnmarshalLenient decodes an RDAP response into v like json.Unmarshal, but
+// tolerates common server deviations such as null arrays, numbers sent as
+// strings and loosely formatted dates. Every coercion is recorded as a
+// warning on v when it embeds Diagnostics. Syntactically invalid JSON is
+// still an error.
+func UnmarshalLenient(b []byte, v interface{}) error {
+	var warnings []string
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	tree, err := parseLenient(decoder, b)
+
+	if err != nil {
+		return err
+	}
+
+	coerce("", tree, &warnings)
+
+	// fixed is ours, so the objects decoded from it need not copy it.
+	fixed := tree.appendJSON(nil)
+
+	if d, ok := v.(objectDecoder); ok {
+		err = d.decode(fixed)
+	} else {
+		err = json.Unmarshal(fixed, v)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if w, ok := v.(warner); ok {
+		w.setRaw(b)
+		w.addWarnings(warnings...)
+	}
+
+	return nil
+}
+
+// lenientValue is a JSON value of a response being repaired, with the bytes
+// it was read from. Unless the value or one inside it is coerced, those
+// bytes are written back as they were, so the objects decoded from the
+// repaired JSON keep the Raw the server sent.
+type lenientValue struct {
+	raw []byte
+	// value is a []lenientMember for objects, a []*lenientValue for arrays
+	// and the json.Decoder token for anything else.
+	value   interface{}
+	changed bool
+}
+
+type lenientMember struct {
+	key   string
+	value *lenientValue
+}
+
+// parseLenient reads the next value from decoder, which reads b.
+func parseLenient(decoder *json.Decoder, b []byte) (*lenientValue, error) {
+	start := int(decoder.InputOffset())
+
+	for start < len(b) && strings.IndexByte(" \t\r\n,:", b[start]) >= 0 {
+		start++
+	}
+
+	token, err := decoder.Token()
+
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := &lenientValue{value: token}
+
+	switch token {
+	case json.Delim('{'):
+		members := []lenientMember{}
+
+		for decoder.More() {
+			token, err := decoder.Token()
+
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+
+			key, ok := token.(string)
+
+			if !ok {
+				return nil, fmt.Errorf("unexpected %v in object", token)
+			}
+
+			member, err := parseLenient(decoder, b)
+
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+
+			members = append(members, lenientMember{key, member})
+		}
+
+		parsed.value = members
+	case json.Delim('['):
+		elements := []*lenientValue{}
+
+		for decoder.More() {
+			element, err := parseLenient(decoder, b)
+
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+
+			elements = append(elements, element)
+		}
+
+		parsed.value = elements
+	}
+
+	if token == json.Delim('{') || token == json.Delim('[') {
+		if _, err := decoder.Token(); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+	}
+
+	parsed.raw = b[start:decoder.InputOffset()]
+
+	return parsed, nil
+}
+
+// unexpectedEOF reports the end of the input inside a value as
+// json.Unmarshal does.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
+
+// appendJSON appends value to b as it was read, or, when it was changed,
+// encoded afresh around the members and elements that were not.
+func (v *lenientValue) appendJSON(b []byte) []byte {
+	if !v.changed {
+		return append(b, v.raw...)
+	}
+
+	switch value := v.value.(type) {
+	case []lenientMember:
+		b = append(b, '{')
+
+		for i, member := range value {
+			if i > 0 {
+				b = append(b, ',')
+			}
+
+			key, _ := json.Marshal(member.key)
+			b = append(append(b, key...), ':')
+			b = member.value.appendJSON(b)
+		}
+
+		return append(b, '}')
+	case []*lenientValue:
+		b = append(b, '[')
+
+		for i, element := range value {
+			if i > 0 {
+				b = append(b, ',')
+			}
+
+			b = element.appendJSON(b)
+		}
+
+		return append(b, ']')
+	}
+
+	scalar, _ := json.Marshal(v.value)
+
+	return append(b, scalar...)
+}
+
+func coerce(path string, value *lenientValue, warnings *[]string) {
+	switch v := value.value.(type) {
+	case []lenientMember:
+		order := make([]int, len(v))
+
+		for i := range order {
+			order[i] = i
+		}
+
+		sort.SliceStable(order, func(i, j int) bool { return v[order[i]].key < v[order[j]].key })
+
+		for _, i := range order {
+			memberPath := path + "/" + v[i].key
+			member, keep := coerceMember(memberPath, v[i].key, v[i].value, warnings)
+
+			if !keep {
+				v[i].value = nil
+				value.changed = true
+				continue
+			}
+
+			coerce(memberPath, member, warnings)
+			v[i].value = member
+			value.changed = value.changed || member.changed
+		}
+
+		kept := v[:0]
+
+		for _, member := range v {
+			if member.value != nil {
+				kept = append(kept, member)
+			}
+		}
+
+		value.value = kept
+	case []*lenientValue:
+		for i, element := range v {
+			coerce(path+"/"+strconv.Itoa(i), element, warnings)
+			value.changed = value.changed || element.changed
+		}
+	}
+}
+
+func coerceMember(path, key string, value *lenientValue, warnings *[]string) (*lenientValue, bool) {
+	warn := func(format string, args ...interface{}) {
+		*warnings = append(*warnings, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if value.value == nil {
+		warn("dropped null value")
+		return nil, false
+	}
+
+	switch {
+	case arrayMembers[key]:
+		if _, ok := value.value.([]*lenientValue); !ok {
+			warn("wrapped non-array value in an array")
+			return &lenientValue{value: []*lenientValue{value}, changed: true}, true
+		}
+	case numberMembers[key]:
+		s, ok := value.value.(string)
+
+		if !ok {
+			break
+		}
+
+		if _, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64); err != nil {
+			warn("dropped invalid number %q", s)
+			return nil, false
+		}
+
+		warn("converted string %q to a number", s)
+		return &lenientValue{value: json.Number(strings.TrimSpace(s)), changed: true}, true
+	case boolMembers[key]:
+		s, ok := value.value.(string)
+
+		if !ok {
+			break
+		}
+
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+
+		if err != nil {
+			warn("dropped invalid boolean %q", s)
+			return nil, false
+		}
+
+		warn("converted string %q to a boolean", s)
+		return &lenientValue{value: b, changed: true}, true
+	case dateMembers[key]:
+		s, ok := value.value.(string)
+
+		if !ok {
+			warn("dropped non-string date")
+			return nil, false
+		}
+
+		if _, err := time.Parse(time.RFC3339, s); err == nil {
+			break
+		}
+
+		date, err := parseLooseDate(s)
+
+		if err != nil {
+			warn("dropped unparseable date %q", s)
+			return nil, false
+		}
+
+		warn("normalized date %q", s)
+		return &lenientValue{value: date.Format(time.RFC3339Nano), changed: true}, true
+	}
+
+	return value, true
+}
+
+var looseDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999 MST",
+	"2006-01-02",
+}
+
+func parseLooseDate(s string) (time.Time, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+
+	if len(s) > 10 && s[10] == ' ' {
+		s = s[:10] + "T" + s[11:]
+	}
+
+	for _, layout := range looseDateLayouts {
+		if date, err := time.Parse(layout, s); err == nil {
+			return date.UTC(), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized date format %q", s)
+}
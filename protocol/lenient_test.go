@@ -0,0 +1,143 @@
+package protocol
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUnmarshalLenient(t *testing.T) {
+	tests := []struct {
+		description      string
+		json             string
+		expected         Autnum
+		expectedWarnings []string
+		expectedError    error
+	}{
+		{
+			description: "it should decode a conformant response without warnings",
+			json: `{
+				"objectClassName": "autnum",
+				"startAutnum": 64512,
+				"endAutnum": 64520,
+				"events": [{"eventAction": "registration", "eventDate": "2015-04-17T16:00:00Z"}]
+			}`,
+			expected: Autnum{
+				ObjectClassName: "autnum",
+				StartAutnum:     64512,
+				EndAutnum:       64520,
+				Events: []Event{
+					{Action: "registration", Date: time.Date(2015, 4, 17, 16, 0, 0, 0, time.UTC)},
+				},
+			},
+		},
+		{
+			description: "it should coerce numbers sent as strings",
+			json:        `{"objectClassName": "autnum", "startAutnum": "64512", "endAutnum": " 64520"}`,
+			expected: Autnum{
+				ObjectClassName: "autnum",
+				StartAutnum:     64512,
+				EndAutnum:       64520,
+			},
+			expectedWarnings: []string{
+				`/endAutnum: converted string " 64520" to a number`,
+				`/startAutnum: converted string "64512" to a number`,
+			},
+		},
+		{
+			description: "it should drop null arrays and wrap scalars in arrays",
+			json:        `{"objectClassName": "autnum", "entities": null, "status": "active"}`,
+			expected: Autnum{
+				ObjectClassName: "autnum",
+				Status:          []string{"active"},
+			},
+			expectedWarnings: []string{
+				"/entities: dropped null value",
+				"/status: wrapped non-array value in an array",
+			},
+		},
+		{
+			description: "it should normalize loosely formatted dates",
+			json: `{"objectClassName": "autnum", "events": [
+				{"eventAction": "registration", "eventDate": "2015-04-17t16:00:00z"},
+				{"eventAction": "last changed", "eventDate": "2016-01-02 03:04:05"},
+				{"eventAction": "expiration", "eventDate": "2020-12-31"},
+				{"eventAction": "transfer", "eventDate": "yesterday"}
+			]}`,
+			expected: Autnum{
+				ObjectClassName: "autnum",
+				Events: []Event{
+					{Action: "registration", Date: time.Date(2015, 4, 17, 16, 0, 0, 0, time.UTC)},
+					{Action: "last changed", Date: time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)},
+					{Action: "expiration", Date: time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC)},
+					{Action: "transfer"},
+				},
+			},
+			expectedWarnings: []string{
+				`/events/0/eventDate: normalized date "2015-04-17t16:00:00z"`,
+				`/events/1/eventDate: normalized date "2016-01-02 03:04:05"`,
+				`/events/2/eventDate: normalized date "2020-12-31"`,
+				`/events/3/eventDate: dropped unparseable date "yesterday"`,
+			},
+		},
+		{
+			description:   "it should not decode invalid json",
+			json:          `{"objectClassName": `,
+			expectedError: fmt.Errorf("unexpected EOF"),
+		},
+	}
+
+	for i, test := range tests {
+		var autnum Autnum
+		err := UnmarshalLenient([]byte(test.json), &autnum)
+
+		if test.expectedError != nil && fmt.Sprintf("%v", test.expectedError) != fmt.Sprintf("%v", err) {
+			t.Fatalf("At index %d (%s): expected error %s, got %s", i, test.description, test.expectedError, err)
+		}
+
+		if test.expectedError == nil && err != nil {
+			t.Fatalf("At index %d (%s): unexpected error %s", i, test.description, err)
+		}
+
+		if !reflect.DeepEqual(test.expectedWarnings, autnum.Warnings) {
+			t.Fatalf("At index %d (%s): expected warnings %v, got %v", i, test.description, test.expectedWarnings, autnum.Warnings)
+		}
+
+		autnum.Diagnostics = Diagnostics{}
+
+		if !reflect.DeepEqual(test.expected, autnum) {
+			t.Fatalf("At index %d (%s): expected %+v, got %+v", i, test.description, test.expected, autnum)
+		}
+	}
+}
+
+func TestUnmarshalLenientRaw(t *testing.T) {
+	untouched := `{ "objectClassName" : "entity",  "handle": "UNTOUCHED" }`
+	response := `{"objectClassName": "autnum", "entities": [
+		` + untouched + `,
+		{"objectClassName": "entity", "handle": "REPAIRED", "roles": "abuse"}
+	]}`
+
+	var autnum Autnum
+
+	if err := UnmarshalLenient([]byte(response), &autnum); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		description string
+		raw         []byte
+		expected    string
+	}{
+		{description: "the response itself", raw: autnum.Raw(), expected: response},
+		{description: "an object nothing was coerced in", raw: autnum.Entities[0].Raw(), expected: untouched},
+		{description: "a repaired object", raw: autnum.Entities[1].Raw(), expected: `{"objectClassName":"entity","handle":"REPAIRED","roles":["abuse"]}`},
+	}
+
+	for i, test := range tests {
+		if string(test.raw) != test.expected {
+			t.Fatalf("At index %d (%s): expected %s, got %s", i, test.description, test.expected, test.raw)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package protocol
+
+import (
+	"net/url"
+	"strings"
+)
+
+const MediaTypeRDAP = "application/rdap+json"
+
+// RelatedLinks returns the links pointing at related RDAP objects, such as a
+// registry domain's link to the registrar's record. Links whose media type is
+// not RDAP, whose target is not absolute http(s), or that repeat an earlier
+// target are skipped.
+func RelatedLinks(links []Link) []Link {
+	var (
+		related []Link
+		seen    = map[string]bool{}
+	)
+
+	for _, link := range links {
+		if !strings.EqualFold(link.Rel, "related") || seen[link.Href] {
+			continue
+		}
+
+		if link.Type != "" && !strings.EqualFold(link.Type, MediaTypeRDAP) {
+			continue
+		}
+
+		u, err := url.Parse(link.Href)
+
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			continue
+		}
+
+		seen[link.Href] = true
+		related = append(related, link)
+	}
+
+	return related
+}
+
+// MediaTypeGeofeed is the media type of the geofeed files of RFC 8805.
+const MediaTypeGeofeed = "application/geofeed+csv"
+
+// GeofeedLinks returns the links to geofeed files, published in RDAP with
+// rel "geo" and the geofeed media type as in RFC 9632 section 5.
+func GeofeedLinks(links []Link) []Link {
+	var geofeeds []Link
+
+	for _, link := range links {
+		if strings.EqualFold(link.Rel, "geo") && strings.EqualFold(link.Type, MediaTypeGeofeed) && link.Href != "" {
+			geofeeds = append(geofeeds, link)
+		}
+	}
+
+	return geofeeds
+}
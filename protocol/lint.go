@@ -0,0 +1,338 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+type Finding struct {
+	Path     string
+	Severity Severity
+	Message  string
+}
+
+func (f Finding) String() string {
+	path := f.Path
+
+	if path == "" {
+		path = "/"
+	}
+
+	return fmt.Sprintf("%s: %s: %s", f.Severity, path, f.Message)
+}
+
+const (
+	rdapLevel0         = "rdap_level_0"
+	icannProfileLevel0 = "icann_rdap_response_profile_0"
+)
+
+var objectClassNames = map[string]bool{
+	"domain":     true,
+	"entity":     true,
+	"nameserver": true,
+	"autnum":     true,
+	"ip network": true,
+}
+
+var searchResultMembers = []string{
+	"domainSearchResults",
+	"entitySearchResults",
+	"nameserverSearchResults",
+}
+
+var icannProfileNotices = []string{
+	"Terms of Use",
+	"Status Codes",
+	"RDDS Inaccuracy Complaint Form",
+}
+
+// Lint checks a raw RDAP response against RFC 9083 and, when the response
+// claims conformance to it, the ICANN gTLD RDAP response profile. An error
+// is only returned when b is not a JSON object.
+func Lint(b []byte) ([]Finding, error) {
+	var (
+		tree     interface{}
+		findings []Finding
+		coerced  []string
+	)
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	root, ok := tree.(map[string]interface{})
+
+	if !ok {
+		return nil, fmt.Errorf("response is not a json object")
+	}
+
+	l := &linter{}
+	conformance := l.lintConformance(root)
+
+	switch {
+	case root["errorCode"] != nil:
+		l.lintError(root)
+	case isSearchResult(root):
+		for _, member := range searchResultMembers {
+			results, _ := root[member].([]interface{})
+
+			for i, result := range results {
+				if object, ok := result.(map[string]interface{}); ok {
+					l.lintObject("/"+member+"/"+strconv.Itoa(i), object)
+				}
+			}
+		}
+	default:
+		l.lintObject("", root)
+
+		if conformance[icannProfileLevel0] && root["objectClassName"] == "domain" {
+			l.lintICANNNotices(root)
+		}
+	}
+
+	// Anything UnmarshalLenient would have to coerce is a conformance error.
+	decoder = json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	if parsed, err := parseLenient(decoder, b); err == nil {
+		coerce("", parsed, &coerced)
+	}
+
+	for _, warning := range coerced {
+		parts := strings.SplitN(warning, ": ", 2)
+		findings = append(findings, Finding{Path: parts[0], Severity: SeverityError, Message: "requires lenient decoding: " + parts[1]})
+	}
+
+	return append(l.findings, findings...), nil
+}
+
+func isSearchResult(root map[string]interface{}) bool {
+	for _, member := range searchResultMembers {
+		if _, ok := root[member]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+type linter struct {
+	findings []Finding
+}
+
+func (l *linter) errorf(path, format string, args ...interface{}) {
+	l.findings = append(l.findings, Finding{Path: path, Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) warnf(path, format string, args ...interface{}) {
+	l.findings = append(l.findings, Finding{Path: path, Severity: SeverityWarning, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) lintConformance(root map[string]interface{}) map[string]bool {
+	conformance := map[string]bool{}
+	values, ok := root["rdapConformance"].([]interface{})
+
+	if !ok {
+		l.errorf("/rdapConformance", "missing rdapConformance")
+		return conformance
+	}
+
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			conformance[s] = true
+		}
+	}
+
+	if !conformance[rdapLevel0] {
+		l.errorf("/rdapConformance", "missing %q", rdapLevel0)
+	}
+
+	return conformance
+}
+
+func (l *linter) lintError(root map[string]interface{}) {
+	if _, ok := root["errorCode"].(json.Number); !ok {
+		l.errorf("/errorCode", "errorCode is not a number")
+	}
+
+	if _, ok := root["description"]; ok {
+		if _, ok := root["description"].([]interface{}); !ok {
+			l.errorf("/description", "description is not an array of strings")
+		}
+	}
+}
+
+func (l *linter) lintObject(path string, object map[string]interface{}) {
+	className, _ := object["objectClassName"].(string)
+
+	switch {
+	case className == "":
+		l.errorf(path+"/objectClassName", "missing objectClassName")
+	case !objectClassNames[className]:
+		l.errorf(path+"/objectClassName", "unknown objectClassName %q", className)
+	}
+
+	if path != "" {
+		if _, ok := object["rdapConformance"]; ok {
+			l.warnf(path+"/rdapConformance", "rdapConformance is only allowed in the topmost object")
+		}
+	}
+
+	switch className {
+	case "domain":
+		if _, ok := object["ldhName"].(string); !ok {
+			l.warnf(path+"/ldhName", "domain has no ldhName")
+		}
+	case "ip network":
+		l.lintIPNetwork(path, object)
+	case "autnum":
+		l.lintAutnum(path, object)
+	}
+
+	l.lintStatus(path, object)
+	l.lintLinks(path, object)
+	l.lintEvents(path, object)
+
+	for _, member := range []string{"entities", "nameservers", "networks", "autnums"} {
+		children, _ := object[member].([]interface{})
+
+		for i, child := range children {
+			if child, ok := child.(map[string]interface{}); ok {
+				l.lintObject(path+"/"+member+"/"+strconv.Itoa(i), child)
+			}
+		}
+	}
+
+	if network, ok := object["network"].(map[string]interface{}); ok {
+		l.lintObject(path+"/network", network)
+	}
+}
+
+func (l *linter) lintIPNetwork(path string, object map[string]interface{}) {
+	version, _ := object["ipVersion"].(string)
+
+	if version != "v4" && version != "v6" {
+		l.errorf(path+"/ipVersion", "ipVersion must be \"v4\" or \"v6\"")
+	}
+
+	for _, member := range []string{"startAddress", "endAddress"} {
+		address, _ := object[member].(string)
+		ip := net.ParseIP(address)
+
+		switch {
+		case ip == nil:
+			l.errorf(path+"/"+member, "invalid ip address %q", address)
+		case version == "v4" && ip.To4() == nil, version == "v6" && ip.To4() != nil:
+			l.errorf(path+"/"+member, "address %q does not match ipVersion %q", address, version)
+		}
+	}
+}
+
+func (l *linter) lintAutnum(path string, object map[string]interface{}) {
+	start, startErr := strconv.ParseUint(fmt.Sprint(object["startAutnum"]), 10, 32)
+	end, endErr := strconv.ParseUint(fmt.Sprint(object["endAutnum"]), 10, 32)
+
+	if startErr != nil || endErr != nil {
+		l.errorf(path, "startAutnum and endAutnum must be 32-bit numbers")
+		return
+	}
+
+	if start > end {
+		l.errorf(path, "startAutnum %d is greater than endAutnum %d", start, end)
+	}
+}
+
+func (l *linter) lintStatus(path string, object map[string]interface{}) {
+	values, _ := object["status"].([]interface{})
+
+	for i, value := range values {
+		if s, ok := value.(string); ok && !IsStatus(s) {
+			l.warnf(path+"/status/"+strconv.Itoa(i), "unregistered status value %q", s)
+		}
+	}
+}
+
+func (l *linter) lintLinks(path string, object map[string]interface{}) {
+	links, _ := object["links"].([]interface{})
+
+	for i, link := range links {
+		link, ok := link.(map[string]interface{})
+		linkPath := path + "/links/" + strconv.Itoa(i)
+
+		if !ok {
+			l.errorf(linkPath, "link is not an object")
+			continue
+		}
+
+		href, _ := link["href"].(string)
+
+		if u, err := url.Parse(href); err != nil || !u.IsAbs() {
+			l.errorf(linkPath+"/href", "href %q is not an absolute uri", href)
+		}
+
+		if _, ok := link["rel"].(string); !ok {
+			l.warnf(linkPath+"/rel", "link has no rel")
+		}
+
+		if _, ok := link["value"].(string); !ok {
+			l.warnf(linkPath+"/value", "link has no value")
+		}
+	}
+}
+
+func (l *linter) lintEvents(path string, object map[string]interface{}) {
+	events, _ := object["events"].([]interface{})
+
+	for i, event := range events {
+		event, ok := event.(map[string]interface{})
+		eventPath := path + "/events/" + strconv.Itoa(i)
+
+		if !ok {
+			l.errorf(eventPath, "event is not an object")
+			continue
+		}
+
+		if _, ok := event["eventAction"].(string); !ok {
+			l.errorf(eventPath+"/eventAction", "event has no eventAction")
+		}
+
+		// Malformed dates are reported through the lenient coercion pass.
+		if _, ok := event["eventDate"]; !ok {
+			l.errorf(eventPath+"/eventDate", "event has no eventDate")
+		}
+	}
+}
+
+func (l *linter) lintICANNNotices(root map[string]interface{}) {
+	titles := map[string]bool{}
+	notices, _ := root["notices"].([]interface{})
+
+	for _, notice := range notices {
+		if notice, ok := notice.(map[string]interface{}); ok {
+			if title, ok := notice["title"].(string); ok {
+				titles[title] = true
+			}
+		}
+	}
+
+	for _, title := range icannProfileNotices {
+		if !titles[title] {
+			l.errorf("/notices", "missing %q notice required by %s", title, icannProfileLevel0)
+		}
+	}
+}
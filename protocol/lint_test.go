@@ -0,0 +1,100 @@
+package protocol
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		description   string
+		json          string
+		expected      []string
+		expectedError error
+	}{
+		{
+			description: "it should not report anything for a conformant response",
+			json: `{
+				"rdapConformance": ["rdap_level_0"],
+				"objectClassName": "domain",
+				"ldhName": "example.com",
+				"status": ["active"],
+				"links": [{"value": "https://rdap.example.com/domain/example.com", "rel": "self", "href": "https://rdap.example.com/domain/example.com"}],
+				"events": [{"eventAction": "registration", "eventDate": "2015-04-17T16:00:00Z"}]
+			}`,
+		},
+		{
+			description: "it should report conformance, link, event and status problems",
+			json: `{
+				"objectClassName": "domain",
+				"status": ["sleeping"],
+				"links": [{"href": "/domain/example.com"}],
+				"events": [{"eventAction": "registration", "eventDate": "2015-04-17 16:00:00"}],
+				"entities": [{"rdapConformance": ["rdap_level_0"], "roles": "registrant"}]
+			}`,
+			expected: []string{
+				"error: /rdapConformance: missing rdapConformance",
+				"warning: /ldhName: domain has no ldhName",
+				`warning: /status/0: unregistered status value "sleeping"`,
+				`error: /links/0/href: href "/domain/example.com" is not an absolute uri`,
+				"warning: /links/0/rel: link has no rel",
+				"warning: /links/0/value: link has no value",
+				"error: /entities/0/objectClassName: missing objectClassName",
+				"warning: /entities/0/rdapConformance: rdapConformance is only allowed in the topmost object",
+				"error: /entities/0/roles: requires lenient decoding: wrapped non-array value in an array",
+				`error: /events/0/eventDate: requires lenient decoding: normalized date "2015-04-17 16:00:00"`,
+			},
+		},
+		{
+			description: "it should check ip networks and autnums",
+			json: `{
+				"rdapConformance": ["rdap_level_0"],
+				"objectClassName": "ip network",
+				"ipVersion": "v4",
+				"startAddress": "2001:db8::",
+				"endAddress": "192.0.2.255",
+				"autnums": [{"objectClassName": "autnum", "startAutnum": 20, "endAutnum": 10}]
+			}`,
+			expected: []string{
+				`error: /startAddress: address "2001:db8::" does not match ipVersion "v4"`,
+				"error: /autnums/0: startAutnum 20 is greater than endAutnum 10",
+			},
+		},
+		{
+			description: "it should require the icann profile notices for domains",
+			json: `{
+				"rdapConformance": ["rdap_level_0", "icann_rdap_response_profile_0"],
+				"objectClassName": "domain",
+				"ldhName": "example.com",
+				"notices": [{"title": "Terms of Use", "description": []}]
+			}`,
+			expected: []string{
+				`error: /notices: missing "Status Codes" notice required by icann_rdap_response_profile_0`,
+				`error: /notices: missing "RDDS Inaccuracy Complaint Form" notice required by icann_rdap_response_profile_0`,
+			},
+		},
+		{
+			description:   "it should not lint a response that is not an object",
+			json:          `[]`,
+			expectedError: fmt.Errorf("response is not a json object"),
+		},
+	}
+
+	for i, test := range tests {
+		var report []string
+		findings, err := Lint([]byte(test.json))
+
+		if test.expectedError != nil && fmt.Sprintf("%v", test.expectedError) != fmt.Sprintf("%v", err) {
+			t.Fatalf("At index %d (%s): expected error %s, got %s", i, test.description, test.expectedError, err)
+		}
+
+		for _, finding := range findings {
+			report = append(report, finding.String())
+		}
+
+		if !reflect.DeepEqual(test.expected, report) {
+			t.Fatalf("At index %d (%s): expected %q, got %q", i, test.description, test.expected, report)
+		}
+	}
+}
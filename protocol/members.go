@@ -0,0 +1,648 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Members holds the members of a JSON object that its Go type has no field
+// for, such as those added by RDAP extensions, keyed by member name. They are
+// written back out when the object is encoded again.
+type Members map[string]json.RawMessage
+
+// memberFields caches, for each struct type, the index of the field that
+// decodes each lower-cased member name, since encoding/json matches member
+// names case-insensitively.
+var memberFields sync.Map
+
+func fieldsByMember(t reflect.Type) map[string][]int {
+	if fields, ok := memberFields.Load(t); ok {
+		return fields.(map[string][]int)
+	}
+
+	fields := map[string][]int{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+
+		if tag == "-" {
+			continue
+		}
+
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			for name, index := range fieldsByMember(field.Type) {
+				if _, ok := fields[name]; !ok {
+					fields[name] = append([]int{i}, index...)
+				}
+			}
+
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fields[strings.ToLower(name)] = []int{i}
+	}
+
+	memberFields.Store(t, fields)
+
+	return fields
+}
+
+// member is a member of a JSON object, with its value as a slice of the
+// buffer holding the object.
+type member struct {
+	name   string
+	value  []byte
+	offset int
+}
+
+// objectDecoder is implemented by the objects that keep the JSON they were
+// decoded from. decode takes ownership of b, so that the objects nested in
+// it keep slices of b rather than copies of their own.
+type objectDecoder interface {
+	decode(b []byte) error
+}
+
+var (
+	objectDecoderType = reflect.TypeOf((*objectDecoder)(nil)).Elem()
+	errMalformed      = errors.New("protocol: malformed JSON object")
+)
+
+func skipSpace(b []byte, i int) int {
+	for i < len(b) && (b[i] == ' ' || b[i] == '\t' || b[i] == '\n' || b[i] == '\r') {
+		i++
+	}
+
+	return i
+}
+
+// skipValue returns the index just past the JSON value starting at b[i].
+func skipValue(b []byte, i int) (int, error) {
+	depth := 0
+
+	for ; i < len(b); i++ {
+		switch b[i] {
+		case '"':
+			for i++; i < len(b) && b[i] != '"'; i++ {
+				if b[i] == '\\' {
+					i++
+				}
+			}
+
+			if i >= len(b) {
+				return 0, errMalformed
+			}
+		case '{', '[':
+			depth++
+			continue
+		case '}', ']':
+			if depth == 0 {
+				return i, nil
+			}
+
+			depth--
+		case ',', ':', ' ', '\t', '\n', '\r':
+			if depth == 0 {
+				return i, nil
+			}
+
+			continue
+		default:
+			continue
+		}
+
+		if depth == 0 {
+			return i + 1, nil
+		}
+	}
+
+	if depth != 0 {
+		return 0, errMalformed
+	}
+
+	return i, nil
+}
+
+// splitObject splits b, a JSON object that encoding/json has already
+// checked, into its members in order, without copying their values.
+func splitObject(b []byte) ([]member, error) {
+	var members []member
+
+	i := skipSpace(b, skipSpace(b, 0)+1)
+
+	if i < len(b) && b[i] == '}' {
+		return nil, nil
+	}
+
+	for {
+		if i >= len(b) || b[i] != '"' {
+			return nil, errMalformed
+		}
+
+		end, err := skipValue(b, i)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var name string
+
+		if err := json.Unmarshal(b[i:end], &name); err != nil {
+			return nil, err
+		}
+
+		if i = skipSpace(b, end); i >= len(b) || b[i] != ':' {
+			return nil, errMalformed
+		}
+
+		start := skipSpace(b, i+1)
+
+		if i, err = skipValue(b, start); err != nil {
+			return nil, err
+		}
+
+		members = append(members, member{name: name, value: b[start:i], offset: start})
+
+		if i = skipSpace(b, i); i < len(b) && b[i] == '}' {
+			return members, nil
+		}
+
+		if i >= len(b) || b[i] != ',' {
+			return nil, errMalformed
+		}
+
+		i = skipSpace(b, i+1)
+	}
+}
+
+// splitArray splits b, a JSON array that encoding/json has already checked,
+// into its elements, without copying them.
+func splitArray(b []byte) ([][]byte, error) {
+	var elements [][]byte
+
+	i := skipSpace(b, skipSpace(b, 0)+1)
+
+	if i < len(b) && b[i] == ']' {
+		return nil, nil
+	}
+
+	for {
+		end, err := skipValue(b, i)
+
+		if err != nil {
+			return nil, err
+		}
+
+		elements = append(elements, b[i:end])
+
+		if i = skipSpace(b, end); i < len(b) && b[i] == ']' {
+			return elements, nil
+		}
+
+		if i >= len(b) || b[i] != ',' {
+			return nil, errMalformed
+		}
+
+		i = skipSpace(b, i+1)
+	}
+}
+
+// firstByte returns the first byte of b that isn't white space.
+func firstByte(b []byte) byte {
+	if i := skipSpace(b, 0); i < len(b) {
+		return b[i]
+	}
+
+	return 0
+}
+
+// decodeObject unmarshals b into v, a pointer to a struct type without
+// UnmarshalJSON of its own, and collects the members v has no field for into
+// unknown. It decodes each member of b once, into its field or into unknown,
+// in the order they appear, so that the first type error it returns is the
+// earliest as with json.Unmarshal. Unknown members are copied unless owned
+// is set, meaning b belongs to the object.
+func decodeObject(b []byte, v interface{}, unknown *Members, owned bool) error {
+	*unknown = nil
+
+	switch firstByte(b) {
+	case '{':
+	case 'n':
+		return nil
+	default:
+		return json.Unmarshal(b, v)
+	}
+
+	members, err := splitObject(b)
+
+	if err != nil {
+		return err
+	}
+
+	var (
+		object   = reflect.ValueOf(v).Elem()
+		fields   = fieldsByMember(object.Type())
+		firstErr error
+	)
+
+	for _, m := range members {
+		index, ok := fields[strings.ToLower(m.name)]
+
+		if !ok {
+			value := json.RawMessage(m.value)
+
+			if !owned {
+				value = append(json.RawMessage(nil), value...)
+			}
+
+			if *unknown == nil {
+				*unknown = Members{}
+			}
+
+			(*unknown)[m.name] = value
+			continue
+		}
+
+		err := decodeMember(m.value, object.FieldByIndex(index), owned)
+
+		if err == nil {
+			continue
+		}
+
+		typeErr, ok := err.(*json.UnmarshalTypeError)
+
+		if !ok {
+			return err
+		}
+
+		// Like json.Unmarshal, carry on past a value of the wrong type and
+		// report the first.
+		if firstErr == nil {
+			if typeErr.Field == "" {
+				typeErr.Struct, typeErr.Field = object.Type().Name(), m.name
+				typeErr.Offset += int64(m.offset)
+			}
+
+			firstErr = typeErr
+		}
+	}
+
+	return firstErr
+}
+
+// decodeMember decodes value into field. Objects that keep their JSON, and
+// slices of and pointers to them, are handed their slice of value directly
+// when owned is set.
+func decodeMember(value []byte, field reflect.Value, owned bool) error {
+	t := field.Type()
+	c := firstByte(value)
+
+	switch {
+	case !owned:
+	case reflect.PtrTo(t).Implements(objectDecoderType):
+		return field.Addr().Interface().(objectDecoder).decode(value)
+	case t.Kind() == reflect.Ptr && t.Implements(objectDecoderType) && c == '{':
+		if field.IsNil() {
+			field.Set(reflect.New(t.Elem()))
+		}
+
+		return field.Interface().(objectDecoder).decode(value)
+	case t.Kind() == reflect.Slice && reflect.PtrTo(t.Elem()).Implements(objectDecoderType) && c == '[':
+		elements, err := splitArray(value)
+
+		if err != nil {
+			return err
+		}
+
+		field.Set(reflect.MakeSlice(t, len(elements), len(elements)))
+
+		var firstErr error
+
+		for i, element := range elements {
+			err := field.Index(i).Addr().Interface().(objectDecoder).decode(element)
+
+			if _, ok := err.(*json.UnmarshalTypeError); err != nil && !ok {
+				return err
+			}
+
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+
+		return firstErr
+	}
+
+	return json.Unmarshal(value, field.Addr().Interface())
+}
+
+// encodeObject marshals v, a struct type without MarshalJSON of its own, and
+// adds the members in unknown that v does not already encode.
+func encodeObject(v interface{}, unknown Members) ([]byte, error) {
+	b, err := json.Marshal(v)
+
+	if err != nil || len(unknown) == 0 {
+		return b, err
+	}
+
+	var members map[string]json.RawMessage
+
+	if err := json.Unmarshal(b, &members); err != nil {
+		return nil, err
+	}
+
+	for name, value := range unknown {
+		if _, ok := members[name]; !ok {
+			members[name] = value
+		}
+	}
+
+	return json.Marshal(members)
+}
+
+// Raw returns the JSON the object was decoded from, byte for byte. It is nil
+// for objects that were built rather than decoded. Under UnmarshalLenient,
+// the objects holding a value that was coerced return the repaired JSON,
+// compacted, and the others what the server sent.
+func (d Diagnostics) Raw() json.RawMessage {
+	return d.raw
+}
+
+func (d *Diagnostics) setRaw(b []byte) {
+	d.raw = append(json.RawMessage(nil), b...)
+}
+
+func (l *Link) UnmarshalJSON(b []byte) error {
+	type link Link
+	return decodeObject(b, (*link)(l), &l.Unknown, false)
+}
+
+func (l Link) MarshalJSON() ([]byte, error) {
+	type link Link
+	return encodeObject(link(l), l.Unknown)
+}
+
+func (e *Event) UnmarshalJSON(b []byte) error {
+	type event Event
+	return decodeObject(b, (*event)(e), &e.Unknown, false)
+}
+
+func (e Event) MarshalJSON() ([]byte, error) {
+	type event Event
+	return encodeObject(event(e), e.Unknown)
+}
+
+func (n *Notice) UnmarshalJSON(b []byte) error {
+	type notice Notice
+	return decodeObject(b, (*notice)(n), &n.Unknown, false)
+}
+
+func (n Notice) MarshalJSON() ([]byte, error) {
+	type notice Notice
+	return encodeObject(notice(n), n.Unknown)
+}
+
+func (v *VariantName) UnmarshalJSON(b []byte) error {
+	type variantName VariantName
+	return decodeObject(b, (*variantName)(v), &v.Unknown, false)
+}
+
+func (v VariantName) MarshalJSON() ([]byte, error) {
+	type variantName VariantName
+	return encodeObject(variantName(v), v.Unknown)
+}
+
+func (v *Variant) UnmarshalJSON(b []byte) error {
+	type variant Variant
+	return decodeObject(b, (*variant)(v), &v.Unknown, false)
+}
+
+func (v Variant) MarshalJSON() ([]byte, error) {
+	type variant Variant
+	return encodeObject(variant(v), v.Unknown)
+}
+
+func (p *PublicID) UnmarshalJSON(b []byte) error {
+	type publicID PublicID
+	return decodeObject(b, (*publicID)(p), &p.Unknown, false)
+}
+
+func (p PublicID) MarshalJSON() ([]byte, error) {
+	type publicID PublicID
+	return encodeObject(publicID(p), p.Unknown)
+}
+
+func (a *IPAddresses) UnmarshalJSON(b []byte) error {
+	type ipAddresses IPAddresses
+	return decodeObject(b, (*ipAddresses)(a), &a.Unknown, false)
+}
+
+func (a IPAddresses) MarshalJSON() ([]byte, error) {
+	type ipAddresses IPAddresses
+	return encodeObject(ipAddresses(a), a.Unknown)
+}
+
+func (s *SecureDNS) UnmarshalJSON(b []byte) error {
+	type secureDNS SecureDNS
+	return decodeObject(b, (*secureDNS)(s), &s.Unknown, false)
+}
+
+func (s SecureDNS) MarshalJSON() ([]byte, error) {
+	type secureDNS SecureDNS
+	return encodeObject(secureDNS(s), s.Unknown)
+}
+
+func (d *DSData) UnmarshalJSON(b []byte) error {
+	type dsData DSData
+	return decodeObject(b, (*dsData)(d), &d.Unknown, false)
+}
+
+func (d DSData) MarshalJSON() ([]byte, error) {
+	type dsData DSData
+	return encodeObject(dsData(d), d.Unknown)
+}
+
+func (k *KeyData) UnmarshalJSON(b []byte) error {
+	type keyData KeyData
+	return decodeObject(b, (*keyData)(k), &k.Unknown, false)
+}
+
+func (k KeyData) MarshalJSON() ([]byte, error) {
+	type keyData KeyData
+	return encodeObject(keyData(k), k.Unknown)
+}
+
+func (e *Entity) UnmarshalJSON(b []byte) error {
+	return e.decode(append([]byte(nil), b...))
+}
+
+func (e *Entity) decode(b []byte) error {
+	type entity Entity
+	e.raw = b
+
+	if err := decodeObject(b, (*entity)(e), &e.Unknown, true); err != nil {
+		return err
+	}
+
+	return decodeExtensions(e, e.RDAPConformance)
+}
+
+func (e Entity) MarshalJSON() ([]byte, error) {
+	type entity Entity
+	return encodeObject(entity(e), e.Unknown)
+}
+
+func (n *Nameserver) UnmarshalJSON(b []byte) error {
+	return n.decode(append([]byte(nil), b...))
+}
+
+func (n *Nameserver) decode(b []byte) error {
+	type nameserver Nameserver
+	n.raw = b
+
+	if err := decodeObject(b, (*nameserver)(n), &n.Unknown, true); err != nil {
+		return err
+	}
+
+	return decodeExtensions(n, n.RDAPConformance)
+}
+
+func (n Nameserver) MarshalJSON() ([]byte, error) {
+	type nameserver Nameserver
+	return encodeObject(nameserver(n), n.Unknown)
+}
+
+func (d *Domain) UnmarshalJSON(b []byte) error {
+	return d.decode(append([]byte(nil), b...))
+}
+
+func (d *Domain) decode(b []byte) error {
+	type domain Domain
+	d.raw = b
+
+	if err := decodeObject(b, (*domain)(d), &d.Unknown, true); err != nil {
+		return err
+	}
+
+	return decodeExtensions(d, d.RDAPConformance)
+}
+
+func (d Domain) MarshalJSON() ([]byte, error) {
+	type domain Domain
+	return encodeObject(domain(d), d.Unknown)
+}
+
+func (n *IPNetwork) UnmarshalJSON(b []byte) error {
+	return n.decode(append([]byte(nil), b...))
+}
+
+func (n *IPNetwork) decode(b []byte) error {
+	type ipNetwork IPNetwork
+	n.raw = b
+
+	if err := decodeObject(b, (*ipNetwork)(n), &n.Unknown, true); err != nil {
+		return err
+	}
+
+	return decodeExtensions(n, n.RDAPConformance)
+}
+
+func (n IPNetwork) MarshalJSON() ([]byte, error) {
+	type ipNetwork IPNetwork
+	return encodeObject(ipNetwork(n), n.Unknown)
+}
+
+func (a *Autnum) UnmarshalJSON(b []byte) error {
+	return a.decode(append([]byte(nil), b...))
+}
+
+func (a *Autnum) decode(b []byte) error {
+	type autnum Autnum
+	a.raw = b
+
+	if err := decodeObject(b, (*autnum)(a), &a.Unknown, true); err != nil {
+		return err
+	}
+
+	return decodeExtensions(a, a.RDAPConformance)
+}
+
+func (a Autnum) MarshalJSON() ([]byte, error) {
+	type autnum Autnum
+	return encodeObject(autnum(a), a.Unknown)
+}
+
+func (e *Error) UnmarshalJSON(b []byte) error {
+	type rdapError Error
+	return decodeObject(b, (*rdapError)(e), &e.Unknown, false)
+}
+
+func (e Error) MarshalJSON() ([]byte, error) {
+	type rdapError Error
+	return encodeObject(rdapError(e), e.Unknown)
+}
+
+func (r *SearchResults) UnmarshalJSON(b []byte) error {
+	return r.decode(append([]byte(nil), b...))
+}
+
+func (r *SearchResults) decode(b []byte) error {
+	type searchResults SearchResults
+	r.raw = b
+
+	if err := decodeObject(b, (*searchResults)(r), &r.Unknown, true); err != nil {
+		return err
+	}
+
+	r.Truncated = r.isTruncated()
+
+	return decodeExtensions(r, r.RDAPConformance)
+}
+
+func (r SearchResults) MarshalJSON() ([]byte, error) {
+	type searchResults SearchResults
+	return encodeObject(searchResults(r), r.Unknown)
+}
+
+func (m *PagingMetadata) UnmarshalJSON(b []byte) error {
+	type pagingMetadata PagingMetadata
+	return decodeObject(b, (*pagingMetadata)(m), &m.Unknown, false)
+}
+
+func (m PagingMetadata) MarshalJSON() ([]byte, error) {
+	type pagingMetadata PagingMetadata
+	return encodeObject(pagingMetadata(m), m.Unknown)
+}
+
+func (h *History) UnmarshalJSON(b []byte) error {
+	type history History
+	return decodeObject(b, (*history)(h), &h.Unknown, false)
+}
+
+func (h History) MarshalJSON() ([]byte, error) {
+	type history History
+	return encodeObject(history(h), h.Unknown)
+}
+
+func (r *HistoryRecord) UnmarshalJSON(b []byte) error {
+	type historyRecord HistoryRecord
+	return decodeObject(b, (*historyRecord)(r), &r.Unknown, false)
+}
+
+func (r HistoryRecord) MarshalJSON() ([]byte, error) {
+	type historyRecord HistoryRecord
+	return encodeObject(historyRecord(r), r.Unknown)
+}
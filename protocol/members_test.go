@@ -0,0 +1,92 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestUnknownMembers(t *testing.T) {
+	body := `{
+		"objectClassName": "domain",
+		"ldhName": "example.com",
+		"fred_lastUpdated": "2024-01-01",
+		"entities": [{"objectClassName": "entity", "handle": "ABC", "fred_vat": {"id": "CZ1"}}],
+		"secureDNS": {"delegationSigned": false, "fred_keyset": ["K1"]}
+	}`
+
+	var domain Domain
+
+	if err := json.Unmarshal([]byte(body), &domain); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(domain.Raw()) != body {
+		t.Fatalf("expected Raw to return the body, got %s", domain.Raw())
+	}
+
+	nested := domain.Entities[0].Raw()
+
+	if i := bytes.Index(domain.Raw(), nested); i < 0 || &domain.Raw()[i] != &nested[0] {
+		t.Fatalf("expected the raw JSON of nested objects to share the buffer of the response, got a copy of %s", nested)
+	}
+
+	tests := []struct {
+		description string
+		unknown     Members
+		expected    Members
+	}{
+		{
+			description: "it should capture unknown top-level members",
+			unknown:     domain.Unknown,
+			expected:    Members{"fred_lastUpdated": json.RawMessage(`"2024-01-01"`)},
+		},
+		{
+			description: "it should capture unknown members of nested objects",
+			unknown:     domain.Entities[0].Unknown,
+			expected:    Members{"fred_vat": json.RawMessage(`{"id": "CZ1"}`)},
+		},
+		{
+			description: "it should capture unknown members of objects without a class",
+			unknown:     domain.SecureDNS.Unknown,
+			expected:    Members{"fred_keyset": json.RawMessage(`["K1"]`)},
+		},
+	}
+
+	for i, test := range tests {
+		if !reflect.DeepEqual(test.expected, test.unknown) {
+			t.Fatalf("At index %d (%s): expected %s, got %s", i, test.description, test.expected, test.unknown)
+		}
+	}
+
+	b, err := json.Marshal(domain)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var roundTrip Domain
+
+	if err := json.Unmarshal(b, &roundTrip); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := roundTrip.Entities[0].Unknown["fred_vat"]; !ok || roundTrip.Unknown["fred_lastUpdated"] == nil {
+		t.Fatalf("expected unknown members to survive encoding, got %s", b)
+	}
+}
+
+func TestUnknownMembersTypeErrors(t *testing.T) {
+	var entity Entity
+
+	err := json.Unmarshal([]byte(`{"port43": 43, "handle": "ABC", "roles": "registrant", "x_note": 1}`), &entity)
+
+	if typeErr, ok := err.(*json.UnmarshalTypeError); !ok || typeErr.Field != "port43" {
+		t.Fatalf("expected the first type error to be for port43, got %v", err)
+	}
+
+	if entity.Handle != "ABC" || string(entity.Unknown["x_note"]) != "1" {
+		t.Fatalf("expected decoding to carry on past type errors, got %+v", entity)
+	}
+}
@@ -0,0 +1,42 @@
+package protocol
+
+import (
+	"bytes"
+	"net"
+)
+
+// Contains reports whether ip lies between the start and end addresses of
+// n. It reports false when either address is missing or invalid.
+func (n *IPNetwork) Contains(ip net.IP) bool {
+	start, end := net.ParseIP(n.StartAddress), net.ParseIP(n.EndAddress)
+
+	if start == nil || end == nil || ip == nil {
+		return false
+	}
+
+	ip = ip.To16()
+
+	return bytes.Compare(ip, start.To16()) >= 0 && bytes.Compare(ip, end.To16()) <= 0
+}
+
+// ContainsNetwork reports whether every address of network lies between the
+// start and end addresses of n.
+func (n *IPNetwork) ContainsNetwork(network *net.IPNet) bool {
+	first := network.IP
+
+	if len(network.Mask) == net.IPv4len {
+		first = first.To4()
+	}
+
+	if first == nil || len(first) != len(network.Mask) {
+		return false
+	}
+
+	last := make(net.IP, len(first))
+
+	for i := range first {
+		last[i] = first[i] | ^network.Mask[i]
+	}
+
+	return n.Contains(first) && n.Contains(last)
+}
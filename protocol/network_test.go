@@ -0,0 +1,34 @@
+package protocol
+
+import (
+	"net"
+	"testing"
+)
+
+func TestIPNetworkContains(t *testing.T) {
+	network := IPNetwork{StartAddress: "192.0.2.0", EndAddress: "192.0.2.255"}
+
+	tests := []struct {
+		cidr     string
+		expected bool
+	}{
+		{cidr: "192.0.2.0/24", expected: true},
+		{cidr: "192.0.2.128/25", expected: true},
+		{cidr: "192.0.2.1/32", expected: true},
+		{cidr: "192.0.0.0/16", expected: false},
+		{cidr: "198.51.100.0/24", expected: false},
+		{cidr: "2001:db8::/32", expected: false},
+	}
+
+	for i, test := range tests {
+		_, cidr, _ := net.ParseCIDR(test.cidr)
+
+		if contained := network.ContainsNetwork(cidr); contained != test.expected {
+			t.Fatalf("At index %d (%s): expected %t, got %t", i, test.cidr, test.expected, contained)
+		}
+	}
+
+	if (&IPNetwork{}).Contains(net.ParseIP("192.0.2.1")) {
+		t.Fatal("expected a network without addresses to contain nothing")
+	}
+}
@@ -0,0 +1,157 @@
+package protocol
+
+import (
+	"sort"
+	"strings"
+)
+
+//go:generate go run gen_tables.go
+
+// Hangul syllables are composed and decomposed arithmetically, as in
+// section 3.12 of the Unicode standard.
+const (
+	hangulSBase  = 0xAC00
+	hangulLBase  = 0x1100
+	hangulVBase  = 0x1161
+	hangulTBase  = 0x11A7
+	hangulLCount = 19
+	hangulVCount = 21
+	hangulTCount = 28
+	hangulNCount = hangulVCount * hangulTCount
+	hangulSCount = hangulLCount * hangulNCount
+)
+
+// canonicalCompositions maps the pairs NFC recomposes to their composite:
+// every two-rune canonical decomposition of a starter into a starter and a
+// second rune, except the composition exclusions.
+var canonicalCompositions = func() map[[2]rune]rune {
+	excluded := map[rune]bool{}
+
+	for _, r := range compositionExclusions {
+		excluded[r] = true
+	}
+
+	compositions := map[[2]rune]rune{}
+
+	for r, d := range canonicalDecompositions {
+		if len(d) == 2 && !excluded[r] && canonicalClasses[r] == 0 && canonicalClasses[d[0]] == 0 {
+			compositions[[2]rune{d[0], d[1]}] = r
+		}
+	}
+
+	return compositions
+}()
+
+// nfc returns s in Unicode Normalization Form C.
+func nfc(s string) string {
+	if isASCII(s) {
+		return s
+	}
+
+	var runes []rune
+
+	for _, r := range s {
+		runes = decompose(runes, r)
+	}
+
+	// Order every run of non-starters by combining class.
+	for start := 0; start < len(runes); {
+		if canonicalClasses[runes[start]] == 0 {
+			start++
+			continue
+		}
+
+		end := start
+
+		for end < len(runes) && canonicalClasses[runes[end]] != 0 {
+			end++
+		}
+
+		run := runes[start:end]
+		sort.SliceStable(run, func(i, j int) bool { return canonicalClasses[run[i]] < canonicalClasses[run[j]] })
+		start = end
+	}
+
+	var (
+		composed  = runes[:0]
+		starter   = -1
+		lastClass uint8
+	)
+
+	for _, r := range runes {
+		class := canonicalClasses[r]
+
+		// A rune combines with the last starter unless a rune between them
+		// has the same class or is a starter.
+		if starter >= 0 && (len(composed)-1 == starter || lastClass != 0 && lastClass < class) {
+			if c, ok := composePair(composed[starter], r); ok {
+				composed[starter] = c
+				continue
+			}
+		}
+
+		if class == 0 {
+			starter = len(composed)
+		}
+
+		lastClass = class
+		composed = append(composed, r)
+	}
+
+	return string(composed)
+}
+
+// decompose appends the full canonical decomposition of r to runes.
+func decompose(runes []rune, r rune) []rune {
+	if s := r - hangulSBase; s >= 0 && s < hangulSCount {
+		runes = append(runes, hangulLBase+s/hangulNCount, hangulVBase+s%hangulNCount/hangulTCount)
+
+		if t := s % hangulTCount; t != 0 {
+			runes = append(runes, hangulTBase+t)
+		}
+
+		return runes
+	}
+
+	d, ok := canonicalDecompositions[r]
+
+	if !ok {
+		return append(runes, r)
+	}
+
+	for _, r := range d {
+		runes = decompose(runes, r)
+	}
+
+	return runes
+}
+
+func composePair(a, b rune) (rune, bool) {
+	if l, v := a-hangulLBase, b-hangulVBase; l >= 0 && l < hangulLCount && v >= 0 && v < hangulVCount {
+		return hangulSBase + (l*hangulVCount+v)*hangulTCount, true
+	}
+
+	if s, t := a-hangulSBase, b-hangulTBase; s >= 0 && s < hangulSCount && s%hangulTCount == 0 && t > 0 && t < hangulTCount {
+		return a + t, true
+	}
+
+	c, ok := canonicalCompositions[[2]rune{a, b}]
+
+	return c, ok
+}
+
+// mapWidth replaces the fullwidth and halfwidth forms in s, such as "ｅ" and
+// "ｶ", with the characters they stand for.
+func mapWidth(s string) string {
+	if isASCII(s) {
+		return s
+	}
+
+	return strings.Map(func(r rune) rune {
+		if mapped, ok := widthMappings[r]; ok {
+			return mapped
+		}
+
+		return r
+	}, s)
+}
@@ -0,0 +1,60 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Normalize returns a canonical encoding of a raw response with the members
+// that change on every query removed: notices and the "last update of RDAP
+// database" event. Two responses normalize to the same bytes when nothing
+// about the registration itself changed.
+func Normalize(b []byte) ([]byte, error) {
+	var tree interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	if root, ok := tree.(map[string]interface{}); ok {
+		delete(root, "notices")
+	}
+
+	return json.Marshal(dropVolatile(tree))
+}
+
+func dropVolatile(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, member := range v {
+			v[key] = dropVolatile(member)
+		}
+
+		if events, ok := v["events"].([]interface{}); ok {
+			kept := events[:0]
+
+			for _, event := range events {
+				if event, ok := event.(map[string]interface{}); ok && event["eventAction"] == EventLastUpdate {
+					continue
+				}
+
+				kept = append(kept, event)
+			}
+
+			if len(kept) > 0 {
+				v["events"] = kept
+			} else {
+				delete(v, "events")
+			}
+		}
+	case []interface{}:
+		for i, element := range v {
+			v[i] = dropVolatile(element)
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,32 @@
+package protocol
+
+import "strings"
+
+// String renders a notice or remark as indented plain text, one description
+// line per line followed by its links.
+func (n Notice) String() string {
+	var lines []string
+
+	switch {
+	case n.Title != "" && n.Type != "":
+		lines = append(lines, n.Title+" ("+n.Type+")")
+	case n.Title != "":
+		lines = append(lines, n.Title)
+	case n.Type != "":
+		lines = append(lines, n.Type)
+	}
+
+	for _, description := range n.Description {
+		lines = append(lines, "  "+description)
+	}
+
+	for _, link := range n.Links {
+		if link.Rel != "" {
+			lines = append(lines, "  "+link.Rel+": "+link.Href)
+		} else {
+			lines = append(lines, "  "+link.Href)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
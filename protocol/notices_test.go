@@ -0,0 +1,45 @@
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNoticeString(t *testing.T) {
+	tests := []struct {
+		description string
+		json        string
+		expected    string
+	}{
+		{
+			description: "it should render a notice with its links",
+			json: `{
+				"title": "Search Policy",
+				"type": "result set truncated due to authorization",
+				"description": ["Results are limited to 25.", "Log in for more."],
+				"links": [{"rel": "alternate", "href": "https://example.net/help"}]
+			}`,
+			expected: "Search Policy (result set truncated due to authorization)\n" +
+				"  Results are limited to 25.\n" +
+				"  Log in for more.\n" +
+				"  alternate: https://example.net/help",
+		},
+		{
+			description: "it should render an untitled remark",
+			json:        `{"description": ["Some text"], "links": [{"href": "https://example.net/"}]}`,
+			expected:    "  Some text\n  https://example.net/",
+		},
+	}
+
+	for i, test := range tests {
+		var notice Notice
+
+		if err := json.Unmarshal([]byte(test.json), &notice); err != nil {
+			t.Fatalf("At index %d (%s): unexpected error %s", i, test.description, err)
+		}
+
+		if notice.String() != test.expected {
+			t.Fatalf("At index %d (%s): expected %q, got %q", i, test.description, test.expected, notice.String())
+		}
+	}
+}
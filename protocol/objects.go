@@ -0,0 +1,252 @@
+// Package protocol defines the RDAP response objects of RFC 9083 and the
+// tools that work on raw responses without any network access: lenient
+// decoding, linting, normalization and diffing.
+//
+// The package follows semantic versioning. Within a major version, exported
+// types keep their fields and methods, and decoding stays lenient towards
+// members a server adds. New fields, members and lint checks may appear in
+// minor releases, so code should not depend on the full list of lint
+// findings or on the exact output of Diff.
+package protocol
+
+import (
+	"strings"
+	"time"
+)
+
+type Link struct {
+	Value    string   `json:"value,omitempty"`
+	Rel      string   `json:"rel,omitempty"`
+	Href     string   `json:"href"`
+	HrefLang []string `json:"hreflang,omitempty"`
+	Title    string   `json:"title,omitempty"`
+	Media    string   `json:"media,omitempty"`
+	Type     string   `json:"type,omitempty"`
+	Unknown  Members  `json:"-"`
+}
+
+type Event struct {
+	Action  string    `json:"eventAction"`
+	Actor   string    `json:"eventActor,omitempty"`
+	Date    time.Time `json:"eventDate"`
+	Links   []Link    `json:"links,omitempty"`
+	Unknown Members   `json:"-"`
+}
+
+type Events []Event
+
+type Notice struct {
+	Title       string   `json:"title,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Description []string `json:"description,omitempty"`
+	Links       []Link   `json:"links,omitempty"`
+	Lang        string   `json:"lang,omitempty"`
+	Unknown     Members  `json:"-"`
+}
+
+type VariantName struct {
+	LDHName     string  `json:"ldhName,omitempty"`
+	UnicodeName string  `json:"unicodeName,omitempty"`
+	Unknown     Members `json:"-"`
+}
+
+type Variant struct {
+	Relation     []string      `json:"relation,omitempty"`
+	IDNTable     string        `json:"idnTable,omitempty"`
+	VariantNames []VariantName `json:"variantNames,omitempty"`
+	Unknown      Members       `json:"-"`
+}
+
+type PublicID struct {
+	Type       string  `json:"type"`
+	Identifier string  `json:"identifier"`
+	Unknown    Members `json:"-"`
+}
+
+type IPAddresses struct {
+	V4      []string `json:"v4,omitempty"`
+	V6      []string `json:"v6,omitempty"`
+	Unknown Members  `json:"-"`
+}
+
+type Entity struct {
+	Diagnostics
+
+	ObjectClassName string        `json:"objectClassName"`
+	RDAPConformance []string      `json:"rdapConformance,omitempty"`
+	Notices         []Notice      `json:"notices,omitempty"`
+	Handle          string        `json:"handle,omitempty"`
+	VCardArray      []interface{} `json:"vcardArray,omitempty"`
+	Roles           []string      `json:"roles,omitempty"`
+	PublicIDs       []PublicID    `json:"publicIds,omitempty"`
+	Entities        []Entity      `json:"entities,omitempty"`
+	AsEventActor    Events        `json:"asEventActor,omitempty"`
+	Status          []string      `json:"status,omitempty"`
+	Remarks         []Notice      `json:"remarks,omitempty"`
+	Port43          string        `json:"port43,omitempty"`
+	Networks        []IPNetwork   `json:"networks,omitempty"`
+	Autnums         []Autnum      `json:"autnums,omitempty"`
+	Events          Events        `json:"events,omitempty"`
+	Links           []Link        `json:"links,omitempty"`
+	Lang            string        `json:"lang,omitempty"`
+	Unknown         Members       `json:"-"`
+}
+
+type Nameserver struct {
+	Diagnostics
+
+	ObjectClassName string       `json:"objectClassName"`
+	RDAPConformance []string     `json:"rdapConformance,omitempty"`
+	Notices         []Notice     `json:"notices,omitempty"`
+	Handle          string       `json:"handle,omitempty"`
+	LDHName         string       `json:"ldhName,omitempty"`
+	UnicodeName     string       `json:"unicodeName,omitempty"`
+	IPAddresses     *IPAddresses `json:"ipAddresses,omitempty"`
+	Entities        []Entity     `json:"entities,omitempty"`
+	Status          []string     `json:"status,omitempty"`
+	Remarks         []Notice     `json:"remarks,omitempty"`
+	Port43          string       `json:"port43,omitempty"`
+	Events          Events       `json:"events,omitempty"`
+	Links           []Link       `json:"links,omitempty"`
+	Lang            string       `json:"lang,omitempty"`
+	Unknown         Members      `json:"-"`
+}
+
+type Domain struct {
+	Diagnostics
+
+	ObjectClassName string       `json:"objectClassName"`
+	RDAPConformance []string     `json:"rdapConformance,omitempty"`
+	Notices         []Notice     `json:"notices,omitempty"`
+	Handle          string       `json:"handle,omitempty"`
+	LDHName         string       `json:"ldhName,omitempty"`
+	UnicodeName     string       `json:"unicodeName,omitempty"`
+	Variants        []Variant    `json:"variants,omitempty"`
+	Nameservers     []Nameserver `json:"nameservers,omitempty"`
+	Entities        []Entity     `json:"entities,omitempty"`
+	Status          []string     `json:"status,omitempty"`
+	Remarks         []Notice     `json:"remarks,omitempty"`
+	PublicIDs       []PublicID   `json:"publicIds,omitempty"`
+	Port43          string       `json:"port43,omitempty"`
+	Network         *IPNetwork   `json:"network,omitempty"`
+	SecureDNS       *SecureDNS   `json:"secureDNS,omitempty"`
+	Events          Events       `json:"events,omitempty"`
+	Links           []Link       `json:"links,omitempty"`
+	Lang            string       `json:"lang,omitempty"`
+	Unknown         Members      `json:"-"`
+}
+
+type IPNetwork struct {
+	Diagnostics
+
+	ObjectClassName string   `json:"objectClassName"`
+	RDAPConformance []string `json:"rdapConformance,omitempty"`
+	Notices         []Notice `json:"notices,omitempty"`
+	Handle          string   `json:"handle,omitempty"`
+	StartAddress    string   `json:"startAddress,omitempty"`
+	EndAddress      string   `json:"endAddress,omitempty"`
+	IPVersion       string   `json:"ipVersion,omitempty"`
+	Name            string   `json:"name,omitempty"`
+	Type            string   `json:"type,omitempty"`
+	Country         string   `json:"country,omitempty"`
+	ParentHandle    string   `json:"parentHandle,omitempty"`
+	Entities        []Entity `json:"entities,omitempty"`
+	Status          []string `json:"status,omitempty"`
+	Remarks         []Notice `json:"remarks,omitempty"`
+	Port43          string   `json:"port43,omitempty"`
+	Events          Events   `json:"events,omitempty"`
+	Links           []Link   `json:"links,omitempty"`
+	Lang            string   `json:"lang,omitempty"`
+	Unknown         Members  `json:"-"`
+}
+
+type Autnum struct {
+	Diagnostics
+
+	ObjectClassName string   `json:"objectClassName"`
+	RDAPConformance []string `json:"rdapConformance,omitempty"`
+	Notices         []Notice `json:"notices,omitempty"`
+	Handle          string   `json:"handle,omitempty"`
+	StartAutnum     uint32   `json:"startAutnum,omitempty"`
+	EndAutnum       uint32   `json:"endAutnum,omitempty"`
+	Name            string   `json:"name,omitempty"`
+	Type            string   `json:"type,omitempty"`
+	Country         string   `json:"country,omitempty"`
+	Entities        []Entity `json:"entities,omitempty"`
+	Status          []string `json:"status,omitempty"`
+	Remarks         []Notice `json:"remarks,omitempty"`
+	Port43          string   `json:"port43,omitempty"`
+	Events          Events   `json:"events,omitempty"`
+	Links           []Link   `json:"links,omitempty"`
+	Lang            string   `json:"lang,omitempty"`
+	Unknown         Members  `json:"-"`
+}
+
+func (e Entity) HasRole(role string) bool {
+	for _, r := range e.Roles {
+		if strings.EqualFold(r, role) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EntitiesByRole returns every entity, including nested ones, holding at least
+// one of roles. Matching entities are returned in document order.
+func EntitiesByRole(entities []Entity, roles ...string) []Entity {
+	var matches []Entity
+
+	for _, entity := range entities {
+		for _, role := range roles {
+			if entity.HasRole(role) {
+				matches = append(matches, entity)
+				break
+			}
+		}
+
+		matches = append(matches, EntitiesByRole(entity.Entities, roles...)...)
+	}
+
+	return matches
+}
+
+// DisplayName returns the domain's LDH name followed by its Unicode name when
+// they differ, deriving whichever form the server left out.
+func (d Domain) DisplayName() string {
+	name := VariantName{LDHName: d.LDHName, UnicodeName: d.UnicodeName}
+
+	if name.UnicodeName == "" {
+		name.UnicodeName, _ = ToUnicode(d.LDHName)
+	}
+
+	if name.LDHName == "" {
+		name.LDHName, _ = ToASCII(d.UnicodeName)
+	}
+
+	return name.String()
+}
+
+func (n VariantName) String() string {
+	if n.UnicodeName != "" && n.UnicodeName != n.LDHName {
+		return n.LDHName + " (" + n.UnicodeName + ")"
+	}
+
+	return n.LDHName
+}
+
+func (v Variant) String() string {
+	var names []string
+
+	for _, name := range v.VariantNames {
+		names = append(names, name.String())
+	}
+
+	relation := strings.Join(v.Relation, ", ")
+
+	if v.IDNTable != "" {
+		relation += " (" + v.IDNTable + ")"
+	}
+
+	return relation + ": " + strings.Join(names, ", ")
+}
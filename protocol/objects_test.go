@@ -0,0 +1,104 @@
+package protocol
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestEntitiesByRole(t *testing.T) {
+	abuse := Entity{Handle: "ABUSE", Roles: []string{"abuse"}}
+	registrar := Entity{Handle: "REGISTRAR", Roles: []string{"registrar"}, Entities: []Entity{abuse}}
+	registrant := Entity{Handle: "REGISTRANT", Roles: []string{"Registrant", "administrative"}}
+
+	tests := []struct {
+		description string
+		entities    []Entity
+		roles       []string
+		expected    []Entity
+	}{
+		{
+			description: "it should find nested entities by role",
+			entities:    []Entity{registrar, registrant},
+			roles:       []string{"abuse"},
+			expected:    []Entity{abuse},
+		},
+		{
+			description: "it should match any of several roles ignoring case",
+			entities:    []Entity{registrar, registrant},
+			roles:       []string{"registrar", "registrant"},
+			expected:    []Entity{registrar, registrant},
+		},
+		{
+			description: "it should not match unknown roles",
+			entities:    []Entity{registrar, registrant},
+			roles:       []string{"technical"},
+		},
+	}
+
+	for i, test := range tests {
+		entities := EntitiesByRole(test.entities, test.roles...)
+
+		if !reflect.DeepEqual(test.expected, entities) {
+			t.Fatalf("At index %d (%s): expected %+v, got %+v", i, test.description, test.expected, entities)
+		}
+	}
+}
+
+func TestVariants(t *testing.T) {
+	var domain Domain
+
+	err := json.Unmarshal([]byte(`{
+		"objectClassName": "domain",
+		"ldhName": "xn--fo-5ja.example",
+		"variants": [
+			{
+				"relation": ["registered", "conjoined"],
+				"variantNames": [{"ldhName": "xn--fo-cka.example", "unicodeName": "fõo.example"}]
+			},
+			{
+				"relation": ["unregistered", "registration restricted"],
+				"idnTable": ".EXAMPLE Swedish",
+				"variantNames": [{"ldhName": "xn--fo-8ja.example"}, {"ldhName": "xn--fo-9ja.example"}]
+			}
+		]
+	}`), &domain)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"registered, conjoined: xn--fo-cka.example (fõo.example)",
+		"unregistered, registration restricted (.EXAMPLE Swedish): xn--fo-8ja.example, xn--fo-9ja.example",
+	}
+
+	var variants []string
+
+	for _, variant := range domain.Variants {
+		variants = append(variants, variant.String())
+	}
+
+	if !reflect.DeepEqual(expected, variants) {
+		t.Fatalf("expected %q, got %q", expected, variants)
+	}
+}
+
+func TestRelatedLinks(t *testing.T) {
+	registrar := Link{Rel: "related", Type: "application/rdap+json", Href: "https://rdap.registrar.example/domain/example.com"}
+
+	links := []Link{
+		{Rel: "self", Type: "application/rdap+json", Href: "https://rdap.registry.example/domain/example.com"},
+		registrar,
+		registrar,
+		{Rel: "related", Type: "text/html", Href: "https://registrar.example/"},
+		{Rel: "related", Href: "ftp://registrar.example/domain/example.com"},
+		{Rel: "related", Href: "/domain/example.com"},
+	}
+
+	expected := []Link{registrar}
+
+	if related := RelatedLinks(links); !reflect.DeepEqual(expected, related) {
+		t.Fatalf("expected %+v, got %+v", expected, related)
+	}
+}
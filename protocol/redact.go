@@ -0,0 +1,114 @@
+package protocol
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// RedactMode is what RedactPII does with the personal data it finds.
+type RedactMode int
+
+const (
+	// RedactRemove drops personal data altogether.
+	RedactRemove RedactMode = iota
+	// RedactHash replaces personal data with "sha256:" and the start of
+	// the SHA-256 of its lower-cased value, so that records sharing a
+	// contact can still be correlated.
+	RedactHash
+)
+
+// piiProperties are the jCard properties RedactPII treats as personal data.
+var piiProperties = map[string]bool{
+	"email": true,
+	"tel":   true,
+	"adr":   true,
+}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+// RedactPII returns a raw response with the email addresses, phone numbers
+// and postal addresses of its entities' vCards removed or hashed as mode
+// says, along with the email addresses found in any other string, such as
+// a remark. Decoding the result before rendering it keeps personal data
+// out of every output format.
+func RedactPII(b []byte, mode RedactMode) ([]byte, error) {
+	var tree interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(redact(tree, mode))
+}
+
+func redact(value interface{}, mode RedactMode) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, member := range v {
+			if vcard, ok := member.([]interface{}); ok && key == "vcardArray" && len(vcard) == 2 {
+				vcard[1] = redactVCard(vcard[1], mode)
+				continue
+			}
+
+			v[key] = redact(member, mode)
+		}
+	case []interface{}:
+		for i, element := range v {
+			v[i] = redact(element, mode)
+		}
+	case string:
+		return emailPattern.ReplaceAllStringFunc(v, func(email string) string {
+			if mode == RedactHash {
+				return hashPII(email)
+			}
+
+			return "[redacted]"
+		})
+	}
+
+	return value
+}
+
+func redactVCard(value interface{}, mode RedactMode) interface{} {
+	properties, ok := value.([]interface{})
+
+	if !ok {
+		return value
+	}
+
+	kept := properties[:0]
+
+	for _, property := range properties {
+		p, ok := property.([]interface{})
+
+		if !ok || len(p) < 4 {
+			kept = append(kept, property)
+			continue
+		}
+
+		name, _ := p[0].(string)
+
+		if !piiProperties[strings.ToLower(name)] {
+			kept = append(kept, redact(property, mode))
+			continue
+		}
+
+		if mode == RedactHash {
+			kept = append(kept, []interface{}{p[0], map[string]interface{}{}, "text", hashPII(vcardText(p[3:]))})
+		}
+	}
+
+	return kept
+}
+
+func hashPII(s string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(s)))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
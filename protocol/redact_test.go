@@ -0,0 +1,53 @@
+package protocol
+
+import "testing"
+
+func TestRedactPII(t *testing.T) {
+	body := `{
+		"objectClassName": "entity",
+		"handle": "ABC-1",
+		"vcardArray": ["vcard", [
+			["fn", {}, "text", "Alice Example"],
+			["adr", {"label": "1 Main St"}, "text", ["", "", "1 Main St", "Springfield", "", "12345", "US"]],
+			["tel", {"type": "voice"}, "uri", "tel:+1-555-0100"],
+			["email", {}, "text", "Alice@example.net"]
+		]],
+		"remarks": [{"description": ["Write to alice@example.net for abuse."]}]
+	}`
+
+	tests := []struct {
+		description string
+		mode        RedactMode
+		expected    string
+	}{
+		{
+			description: "it should remove personal data",
+			mode:        RedactRemove,
+			expected: `{"handle":"ABC-1","objectClassName":"entity",` +
+				`"remarks":[{"description":["Write to [redacted] for abuse."]}],` +
+				`"vcardArray":["vcard",[["fn",{},"text","Alice Example"]]]}`,
+		},
+		{
+			description: "it should hash personal data consistently",
+			mode:        RedactHash,
+			expected: `{"handle":"ABC-1","objectClassName":"entity",` +
+				`"remarks":[{"description":["Write to sha256:85956a993804cda1 for abuse."]}],` +
+				`"vcardArray":["vcard",[["fn",{},"text","Alice Example"],` +
+				`["adr",{},"text","sha256:` + hashPII("1 Main St, Springfield, 12345, US")[7:] + `"],` +
+				`["tel",{},"text","sha256:` + hashPII("tel:+1-555-0100")[7:] + `"],` +
+				`["email",{},"text","sha256:85956a993804cda1"]]]}`,
+		},
+	}
+
+	for i, test := range tests {
+		redacted, err := RedactPII([]byte(body), test.mode)
+
+		if err != nil {
+			t.Fatalf("At index %d (%s): unexpected error %s", i, test.description, err)
+		}
+
+		if string(redacted) != test.expected {
+			t.Fatalf("At index %d (%s): expected %s, got %s", i, test.description, test.expected, redacted)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package protocol
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	reverseV4Suffix = ".in-addr.arpa"
+	reverseV6Suffix = ".ip6.arpa"
+)
+
+func IsReverseName(name string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	return strings.HasSuffix(name, reverseV4Suffix) || strings.HasSuffix(name, reverseV6Suffix)
+}
+
+// ParseReverseName converts a reverse DNS name such as 2.0.192.in-addr.arpa
+// into the network it covers (192.0.2.0/24), so it can be looked up with an
+// ip network query.
+func ParseReverseName(name string) (*net.IPNet, error) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+
+	switch {
+	case strings.HasSuffix(name, reverseV4Suffix):
+		return parseReverseLabels(strings.TrimSuffix(name, reverseV4Suffix), net.IPv4len, 8, 10)
+	case strings.HasSuffix(name, reverseV6Suffix):
+		return parseReverseLabels(strings.TrimSuffix(name, reverseV6Suffix), net.IPv6len, 4, 16)
+	}
+
+	return nil, fmt.Errorf("not a reverse dns name: %s", name)
+}
+
+func parseReverseLabels(labels string, size, bitsPerLabel, base int) (*net.IPNet, error) {
+	parts := strings.Split(labels, ".")
+
+	if labels == "" || len(parts)*bitsPerLabel > size*8 {
+		return nil, fmt.Errorf("invalid reverse dns name: %s", labels)
+	}
+
+	ip := make(net.IP, size)
+
+	for i := range parts {
+		value, err := strconv.ParseUint(parts[len(parts)-1-i], base, bitsPerLabel)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid reverse dns label %q", parts[len(parts)-1-i])
+		}
+
+		bit := i * bitsPerLabel
+		ip[bit/8] |= byte(value << uint(8-bitsPerLabel-bit%8))
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(len(parts)*bitsPerLabel, size*8)}, nil
+}
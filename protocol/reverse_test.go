@@ -0,0 +1,75 @@
+package protocol
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseReverseName(t *testing.T) {
+	tests := []struct {
+		description   string
+		name          string
+		expected      string
+		expectedError error
+	}{
+		{
+			description: "it should parse a full in-addr.arpa name",
+			name:        "4.3.2.1.in-addr.arpa",
+			expected:    "1.2.3.4/32",
+		},
+		{
+			description: "it should parse a partial in-addr.arpa name with a trailing dot",
+			name:        "2.0.192.IN-ADDR.ARPA.",
+			expected:    "192.0.2.0/24",
+		},
+		{
+			description: "it should parse an ip6.arpa name",
+			name:        "8.b.d.0.1.0.0.2.ip6.arpa",
+			expected:    "2001:db8::/32",
+		},
+		{
+			description: "it should parse an ip6.arpa name with an odd number of nibbles",
+			name:        "f.ip6.arpa",
+			expected:    "f000::/4",
+		},
+		{
+			description:   "it should not parse an invalid octet",
+			name:          "256.in-addr.arpa",
+			expectedError: fmt.Errorf("invalid reverse dns label \"256\""),
+		},
+		{
+			description:   "it should not parse too many labels",
+			name:          "1.1.1.1.1.in-addr.arpa",
+			expectedError: fmt.Errorf("invalid reverse dns name: 1.1.1.1.1"),
+		},
+		{
+			description:   "it should not parse a forward name",
+			name:          "example.com",
+			expectedError: fmt.Errorf("not a reverse dns name: example.com"),
+		},
+	}
+
+	for i, test := range tests {
+		network, err := ParseReverseName(test.name)
+
+		if test.expectedError != nil {
+			if fmt.Sprintf("%v", test.expectedError) != fmt.Sprintf("%v", err) {
+				t.Fatalf("At index %d (%s): expected error %s, got %s", i, test.description, test.expectedError, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("At index %d (%s): unexpected error %s", i, test.description, err)
+		}
+
+		if network.String() != test.expected {
+			t.Fatalf("At index %d (%s): expected %s, got %s", i, test.description, test.expected, network)
+		}
+
+		if !IsReverseName(test.name) {
+			t.Fatalf("At index %d (%s): expected %s to be a reverse name", i, test.description, test.name)
+		}
+	}
+}
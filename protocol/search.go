@@ -0,0 +1,69 @@
+package protocol
+
+import "strings"
+
+// SearchResults is the answer to a domain, nameserver or entity search of
+// RFC 9082 section 3.2. Truncated is set when the server signals that it
+// left results out, and PagingMetadata, from RFC 8977, links to the next
+// page of servers that page their results.
+type SearchResults struct {
+	Diagnostics
+
+	RDAPConformance []string        `json:"rdapConformance,omitempty"`
+	Notices         []Notice        `json:"notices,omitempty"`
+	Domains         []Domain        `json:"domainSearchResults,omitempty"`
+	Nameservers     []Nameserver    `json:"nameserverSearchResults,omitempty"`
+	Entities        []Entity        `json:"entitySearchResults,omitempty"`
+	PagingMetadata  *PagingMetadata `json:"paging_metadata,omitempty"`
+	Lang            string          `json:"lang,omitempty"`
+	Truncated       bool            `json:"-"`
+	Unknown         Members         `json:"-"`
+}
+
+type PagingMetadata struct {
+	TotalCount int     `json:"totalCount,omitempty"`
+	PageSize   int     `json:"pageSize,omitempty"`
+	PageNumber int     `json:"pageNumber,omitempty"`
+	Links      []Link  `json:"links,omitempty"`
+	Unknown    Members `json:"-"`
+}
+
+// Next returns the URL of the next page of results, or "" on the last page
+// and for servers that don't page.
+func (r *SearchResults) Next() string {
+	if r.PagingMetadata == nil {
+		return ""
+	}
+
+	for _, link := range r.PagingMetadata.Links {
+		if link.Rel == "next" {
+			return link.Href
+		}
+	}
+
+	return ""
+}
+
+// Len returns the number of results of all classes.
+func (r *SearchResults) Len() int {
+	return len(r.Domains) + len(r.Nameservers) + len(r.Entities)
+}
+
+// isTruncated recognizes the "result set truncated due to ..." notice types
+// of RFC 9083 section 10.2.1 and the incompleteData conformance some servers
+// announce instead.
+func (r *SearchResults) isTruncated() bool {
+	for _, notice := range r.Notices {
+		if strings.HasPrefix(strings.ToLower(notice.Type), "result set truncated") {
+			return true
+		}
+	}
+
+	for _, identifier := range r.RDAPConformance {
+		if identifier == "incompleteData" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,122 @@
+package protocol
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// sqlSchema holds the tables SQLWriter fills. Entities and events refer to
+// the object they were found in, nested entities included.
+const sqlSchema = `CREATE TABLE IF NOT EXISTS objects (id INTEGER PRIMARY KEY, class TEXT, handle TEXT, name TEXT, raw_json TEXT);
+CREATE TABLE IF NOT EXISTS entities (object_id INTEGER REFERENCES objects(id), handle TEXT, roles TEXT, name TEXT, email TEXT);
+CREATE TABLE IF NOT EXISTS events (object_id INTEGER REFERENCES objects(id), action TEXT, actor TEXT, date TEXT);
+CREATE TABLE IF NOT EXISTS nameservers (object_id INTEGER REFERENCES objects(id), ldh_name TEXT);
+`
+
+// SQLWriter writes raw responses as an SQL script of normalized tables, for
+// loading into SQLite with "sqlite3 results.db < results.sql" and querying
+// bulk results there. The script creates its tables and runs in a single
+// transaction, committed by Close. Objects get their ids from SQLite, so
+// that several scripts can be loaded into one database.
+type SQLWriter struct {
+	w       io.Writer
+	written int
+	err     error
+}
+
+// sqlObjectID refers child rows to the object inserted last.
+const sqlObjectID = "(SELECT max(id) FROM objects)"
+
+func NewSQLWriter(w io.Writer) *SQLWriter {
+	return &SQLWriter{w: w}
+}
+
+// Write adds the object in the raw response b, of any class, to the
+// script.
+func (s *SQLWriter) Write(b []byte) error {
+	var object struct {
+		ObjectClassName string       `json:"objectClassName"`
+		Handle          string       `json:"handle"`
+		LDHName         string       `json:"ldhName"`
+		Name            string       `json:"name"`
+		Entities        []Entity     `json:"entities"`
+		Events          Events       `json:"events"`
+		Nameservers     []Nameserver `json:"nameservers"`
+	}
+
+	if err := UnmarshalLenient(b, &object); err != nil {
+		return err
+	}
+
+	if s.written == 0 {
+		s.printf("BEGIN;\n%s", sqlSchema)
+	}
+
+	s.written++
+	name := object.LDHName
+
+	if name == "" {
+		name = object.Name
+	}
+
+	s.printf("INSERT INTO objects (class, handle, name, raw_json) VALUES (%s, %s, %s, %s);\n", sqlString(object.ObjectClassName), sqlString(object.Handle), sqlString(name), sqlString(string(b)))
+	s.events(object.Events)
+	s.entities(object.Entities)
+
+	for _, nameserver := range object.Nameservers {
+		s.printf("INSERT INTO nameservers VALUES (%s, %s);\n", sqlObjectID, sqlString(nameserver.LDHName))
+	}
+
+	return s.err
+}
+
+func (s *SQLWriter) entities(entities []Entity) {
+	for _, entity := range entities {
+		s.printf("INSERT INTO entities VALUES (%s, %s, %s, %s, %s);\n", sqlObjectID,
+			sqlString(entity.Handle),
+			sqlString(strings.Join(entity.Roles, ",")),
+			sqlString(strings.Join(entity.VCard("fn"), "; ")),
+			sqlString(strings.Join(entity.VCard("email"), "; ")))
+
+		s.events(entity.Events)
+		s.entities(entity.Entities)
+	}
+}
+
+func (s *SQLWriter) events(events Events) {
+	for _, event := range events {
+		var date string
+
+		if !event.Date.IsZero() {
+			date = event.Date.UTC().Format(time.RFC3339)
+		}
+
+		s.printf("INSERT INTO events VALUES (%s, %s, %s, %s);\n", sqlObjectID, sqlString(event.Action), sqlString(event.Actor), sqlString(date))
+	}
+}
+
+// Close commits the transaction of the script. It writes nothing when no
+// object was written.
+func (s *SQLWriter) Close() error {
+	if s.written > 0 {
+		s.printf("COMMIT;\n")
+	}
+
+	return s.err
+}
+
+func (s *SQLWriter) printf(format string, args ...interface{}) {
+	if s.err == nil {
+		_, s.err = fmt.Fprintf(s.w, format, args...)
+	}
+}
+
+func sqlString(s string) string {
+	if s == "" {
+		return "NULL"
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,61 @@
+package protocol
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSQLWriter(t *testing.T) {
+	var script bytes.Buffer
+
+	writer := NewSQLWriter(&script)
+	responses := []string{
+		`{"objectClassName": "domain", "handle": "D1", "ldhName": "o'example.com",
+			"events": [{"eventAction": "registration", "eventDate": "1995-08-14T04:00:00Z"}],
+			"entities": [{"objectClassName": "entity", "handle": "R1", "roles": ["registrar"],
+				"vcardArray": ["vcard", [["fn", {}, "text", "Example Registrar"]]],
+				"entities": [{"objectClassName": "entity", "handle": "A1", "roles": ["abuse"]}]}],
+			"nameservers": [{"objectClassName": "nameserver", "ldhName": "ns1.example.net"}]}`,
+		`{"objectClassName": "autnum", "handle": "AS64500", "name": "EXAMPLE"}`,
+	}
+
+	for _, response := range responses {
+		if err := writer.Write([]byte(response)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(script.String()), "\n")
+	inserts := lines[5:]
+
+	expected := []string{
+		"INSERT INTO objects (class, handle, name, raw_json) VALUES ('domain', 'D1', 'o''example.com', '" + strings.ReplaceAll(responses[0], "'", "''"),
+		"INSERT INTO events VALUES (" + sqlObjectID + ", 'registration', NULL, '1995-08-14T04:00:00Z');",
+		"INSERT INTO entities VALUES (" + sqlObjectID + ", 'R1', 'registrar', 'Example Registrar', NULL);",
+		"INSERT INTO entities VALUES (" + sqlObjectID + ", 'A1', 'abuse', NULL, NULL);",
+		"INSERT INTO nameservers VALUES (" + sqlObjectID + ", 'ns1.example.net');",
+		"INSERT INTO objects (class, handle, name, raw_json) VALUES ('autnum', 'AS64500', 'EXAMPLE', '" + responses[1] + "');",
+		"COMMIT;",
+	}
+
+	if lines[0] != "BEGIN;" || !strings.HasPrefix(script.String(), "BEGIN;\n"+sqlSchema) {
+		t.Fatalf("expected the script to open a transaction and create its tables, got %q", lines[:5])
+	}
+
+	joined := strings.Join(inserts, "\n")
+
+	for _, statement := range expected {
+		if !strings.Contains(joined, statement) {
+			t.Fatalf("expected %q in the script, got %s", statement, joined)
+		}
+	}
+
+	if strings.Contains(joined, "INSERT INTO objects VALUES") {
+		t.Fatalf("expected objects to be numbered by the database, got %s", joined)
+	}
+}
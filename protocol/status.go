@@ -0,0 +1,85 @@
+package protocol
+
+import "strings"
+
+// statuses maps every registered RDAP status value to its EPP status code
+// as defined in RFC 8056, or to "" when EPP has no equivalent.
+var statuses = map[string]string{
+	"validated":                  "",
+	"renew prohibited":           "",
+	"update prohibited":          "",
+	"transfer prohibited":        "",
+	"delete prohibited":          "",
+	"proxy":                      "",
+	"private":                    "",
+	"removed":                    "",
+	"obscured":                   "",
+	"associated":                 "linked",
+	"active":                     "ok",
+	"inactive":                   "inactive",
+	"locked":                     "",
+	"pending create":             "pendingCreate",
+	"pending renew":              "pendingRenew",
+	"pending transfer":           "pendingTransfer",
+	"pending update":             "pendingUpdate",
+	"pending delete":             "pendingDelete",
+	"add period":                 "addPeriod",
+	"auto renew period":          "autoRenewPeriod",
+	"client delete prohibited":   "clientDeleteProhibited",
+	"client hold":                "clientHold",
+	"client renew prohibited":    "clientRenewProhibited",
+	"client transfer prohibited": "clientTransferProhibited",
+	"client update prohibited":   "clientUpdateProhibited",
+	"pending restore":            "pendingRestore",
+	"redemption period":          "redemptionPeriod",
+	"renew period":               "renewPeriod",
+	"server delete prohibited":   "serverDeleteProhibited",
+	"server renew prohibited":    "serverRenewProhibited",
+	"server transfer prohibited": "serverTransferProhibited",
+	"server update prohibited":   "serverUpdateProhibited",
+	"server hold":                "serverHold",
+	"transfer period":            "transferPeriod",
+	"administrative":             "",
+	"reserved":                   "",
+}
+
+var eppStatuses = map[string]string{}
+
+func init() {
+	for status, epp := range statuses {
+		if epp != "" {
+			eppStatuses[strings.ToLower(epp)] = status
+		}
+	}
+}
+
+func IsStatus(status string) bool {
+	_, ok := statuses[status]
+	return ok
+}
+
+func EPPStatus(status string) (string, bool) {
+	epp := statuses[strings.ToLower(status)]
+	return epp, epp != ""
+}
+
+func RDAPStatus(epp string) (string, bool) {
+	status, ok := eppStatuses[strings.ToLower(epp)]
+	return status, ok
+}
+
+// EPPStatuses translates status values to EPP status codes for output,
+// passing through values that have no EPP equivalent unchanged.
+func EPPStatuses(statuses []string) []string {
+	mapped := make([]string, 0, len(statuses))
+
+	for _, status := range statuses {
+		if epp, ok := EPPStatus(status); ok {
+			status = epp
+		}
+
+		mapped = append(mapped, status)
+	}
+
+	return mapped
+}
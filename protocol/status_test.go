@@ -0,0 +1,56 @@
+package protocol
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEPPStatus(t *testing.T) {
+	tests := []struct {
+		description string
+		status      string
+		expected    string
+		found       bool
+	}{
+		{
+			description: "it should map a status to its epp code",
+			status:      "client transfer prohibited",
+			expected:    "clientTransferProhibited",
+			found:       true,
+		},
+		{
+			description: "it should map statuses whose names differ",
+			status:      "Active",
+			expected:    "ok",
+			found:       true,
+		},
+		{
+			description: "it should not map statuses without an epp equivalent",
+			status:      "transfer prohibited",
+		},
+	}
+
+	for i, test := range tests {
+		epp, found := EPPStatus(test.status)
+
+		if epp != test.expected || found != test.found {
+			t.Fatalf("At index %d (%s): expected %q (%t), got %q (%t)", i, test.description, test.expected, test.found, epp, found)
+		}
+
+		if !found {
+			continue
+		}
+
+		if status, _ := RDAPStatus(epp); status != strings.ToLower(test.status) {
+			t.Fatalf("At index %d (%s): expected %q to map back to %q, got %q", i, test.description, epp, test.status, status)
+		}
+	}
+
+	mapped := EPPStatuses([]string{"client hold", "associated", "locked"})
+	expected := []string{"clientHold", "linked", "locked"}
+
+	if !reflect.DeepEqual(expected, mapped) {
+		t.Fatalf("expected %v, got %v", expected, mapped)
+	}
+}
@@ -0,0 +1,129 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// searchResultTypes maps each search result member to the object class its
+// elements decode into.
+var searchResultTypes = map[string]func() interface{}{
+	"domainSearchResults":     func() interface{} { return new(Domain) },
+	"nameserverSearchResults": func() interface{} { return new(Nameserver) },
+	"entitySearchResults":     func() interface{} { return new(Entity) },
+}
+
+// StreamSearchResults decodes a search response from r one result at a
+// time, calling visit with each *Domain, *Nameserver or *Entity as soon as
+// it is decoded, so that result sets of any size are decoded in constant
+// memory. The returned SearchResults holds the other members, such as the
+// notices and paging metadata, with empty result slices. An error returned
+// by visit stops the decoding and is returned as is.
+func StreamSearchResults(r io.Reader, visit func(object interface{}) error) (*SearchResults, error) {
+	decoder := json.NewDecoder(r)
+
+	if err := expectDelim(decoder, '{'); err != nil {
+		return nil, err
+	}
+
+	rest := map[string]json.RawMessage{}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+
+		if err != nil {
+			return nil, err
+		}
+
+		key := token.(string)
+		newObject, ok := searchResultTypes[key]
+
+		if !ok {
+			var value json.RawMessage
+
+			if err := decoder.Decode(&value); err != nil {
+				return nil, err
+			}
+
+			rest[key] = value
+			continue
+		}
+
+		if err := expectDelim(decoder, '['); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+
+		for decoder.More() {
+			object := newObject()
+
+			if err := decoder.Decode(object); err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+
+			if err := visit(object); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := expectDelim(decoder, ']'); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	if err := expectDelim(decoder, '}'); err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(rest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var results SearchResults
+
+	if err := json.Unmarshal(b, &results); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+
+	if err != nil {
+		return err
+	}
+
+	if token != delim {
+		return fmt.Errorf("expected %s, got %v", delim, token)
+	}
+
+	return nil
+}
+
+// NDJSON returns a visit function for StreamSearchResults that writes each
+// result to w as it was received, compacted onto a line of its own.
+func NDJSON(w io.Writer) func(object interface{}) error {
+	return func(object interface{}) error {
+		var line bytes.Buffer
+
+		raw, ok := object.(interface{ Raw() json.RawMessage })
+
+		if !ok {
+			return fmt.Errorf("unexpected result %T", object)
+		}
+
+		if err := json.Compact(&line, raw.Raw()); err != nil {
+			return err
+		}
+
+		line.WriteByte('\n')
+		_, err := w.Write(line.Bytes())
+
+		return err
+	}
+}
@@ -0,0 +1,52 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStreamSearchResults(t *testing.T) {
+	body := `{
+		"rdapConformance": ["rdap_level_0"],
+		"domainSearchResults": [
+			{"objectClassName": "domain", "ldhName": "example.com"},
+			{"objectClassName": "domain", "ldhName": "example.net",
+			 "x_custom": 1}
+		],
+		"notices": [{"title": "Search Policy", "type": "result set truncated due to authorization"}],
+		"paging_metadata": {"totalCount": 2}
+	}`
+
+	var output bytes.Buffer
+
+	results, err := StreamSearchResults(strings.NewReader(body), NDJSON(&output))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"objectClassName":"domain","ldhName":"example.com"}` + "\n" +
+		`{"objectClassName":"domain","ldhName":"example.net","x_custom":1}` + "\n"
+
+	if output.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, output.String())
+	}
+
+	if results.Len() != 0 || !results.Truncated || results.PagingMetadata.TotalCount != 2 {
+		t.Fatalf("expected the other members without the results, got %+v", results)
+	}
+
+	stop := errors.New("stop")
+	visits := 0
+
+	_, err = StreamSearchResults(strings.NewReader(body), func(interface{}) error {
+		visits++
+		return stop
+	})
+
+	if err != stop || visits != 1 {
+		t.Fatalf("expected visit errors to stop decoding, got %v after %d visits", err, visits)
+	}
+}
@@ -0,0 +1,62 @@
+package protocol
+
+import "strings"
+
+// VCard returns the values of the jCard (RFC 7095) properties named name,
+// such as "fn" or "email", in e's vcardArray. Structured values such as
+// the components of an adr are joined with ", ", skipping empty ones.
+func (e Entity) VCard(name string) []string {
+	var values []string
+
+	for _, property := range vcardProperties(e.VCardArray) {
+		if len(property) < 4 {
+			continue
+		}
+
+		if n, _ := property[0].(string); !strings.EqualFold(n, name) {
+			continue
+		}
+
+		if value := vcardText(property[3:]); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+func vcardProperties(vcard []interface{}) [][]interface{} {
+	if len(vcard) < 2 {
+		return nil
+	}
+
+	list, _ := vcard[1].([]interface{})
+	properties := make([][]interface{}, 0, len(list))
+
+	for _, property := range list {
+		if p, ok := property.([]interface{}); ok {
+			properties = append(properties, p)
+		}
+	}
+
+	return properties
+}
+
+func vcardText(values []interface{}) string {
+	var parts []string
+
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			if v != "" {
+				parts = append(parts, v)
+			}
+		case []interface{}:
+			if text := vcardText(v); text != "" {
+				parts = append(parts, text)
+			}
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,42 @@
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestEntityVCard(t *testing.T) {
+	var entity Entity
+
+	body := `{
+		"objectClassName": "entity",
+		"vcardArray": ["vcard", [
+			["version", {}, "text", "4.0"],
+			["fn", {}, "text", "Example Networks"],
+			["adr", {}, "text", ["", "", "1 Main St", "Springfield", "", "12345", "US"]],
+			["email", {"type": "work"}, "text", "noc@example.net"],
+			["EMAIL", {}, "text", "abuse@example.net"]
+		]]
+	}`
+
+	if err := json.Unmarshal([]byte(body), &entity); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		expected []string
+	}{
+		{name: "fn", expected: []string{"Example Networks"}},
+		{name: "adr", expected: []string{"1 Main St, Springfield, 12345, US"}},
+		{name: "email", expected: []string{"noc@example.net", "abuse@example.net"}},
+		{name: "tel", expected: nil},
+	}
+
+	for i, test := range tests {
+		if values := entity.VCard(test.name); fmt.Sprintf("%q", values) != fmt.Sprintf("%q", test.expected) {
+			t.Fatalf("At index %d (%s): expected %q, got %q", i, test.name, test.expected, values)
+		}
+	}
+}
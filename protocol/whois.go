@@ -0,0 +1,161 @@
+package protocol
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Whois renders a *Domain, *Nameserver, *IPNetwork, *Autnum or *Entity as
+// the "Key: value" text of classic port 43 whois, for tools that can only
+// parse that. Remarks and notices follow as "%" comment lines.
+func Whois(object interface{}) string {
+	var w whoisWriter
+
+	switch o := object.(type) {
+	case *Domain:
+		w.add("Domain Name", strings.ToUpper(o.LDHName))
+		w.add("Registry Domain ID", o.Handle)
+		w.events(o.Events)
+		w.entities(o.Entities)
+
+		for _, status := range o.Status {
+			w.add("Domain Status", status)
+		}
+
+		for _, nameserver := range o.Nameservers {
+			w.add("Name Server", strings.ToUpper(nameserver.LDHName))
+		}
+
+		switch {
+		case o.SecureDNS != nil && o.SecureDNS.DelegationSigned:
+			w.add("DNSSEC", "signedDelegation")
+		case o.SecureDNS != nil:
+			w.add("DNSSEC", "unsigned")
+		}
+
+		w.remarks(o.Remarks, o.Notices)
+	case *Nameserver:
+		w.add("Server Name", strings.ToUpper(o.LDHName))
+		w.add("Handle", o.Handle)
+
+		if o.IPAddresses != nil {
+			for _, address := range append(append([]string(nil), o.IPAddresses.V4...), o.IPAddresses.V6...) {
+				w.add("IP Address", address)
+			}
+		}
+
+		for _, status := range o.Status {
+			w.add("Status", status)
+		}
+
+		w.events(o.Events)
+		w.entities(o.Entities)
+		w.remarks(o.Remarks, o.Notices)
+	case *IPNetwork:
+		w.add("NetRange", strings.TrimSuffix(o.StartAddress+" - "+o.EndAddress, " - "))
+		w.add("NetName", o.Name)
+		w.add("NetHandle", o.Handle)
+		w.add("Parent", o.ParentHandle)
+		w.add("NetType", o.Type)
+		w.add("Country", o.Country)
+		w.events(o.Events)
+		w.entities(o.Entities)
+		w.remarks(o.Remarks, o.Notices)
+	case *Autnum:
+		switch {
+		case o.StartAutnum != 0 && o.EndAutnum > o.StartAutnum:
+			w.add("ASNumber", fmt.Sprintf("%d - %d", o.StartAutnum, o.EndAutnum))
+		case o.StartAutnum != 0:
+			w.add("ASNumber", fmt.Sprint(o.StartAutnum))
+		}
+
+		w.add("ASName", o.Name)
+		w.add("ASHandle", o.Handle)
+		w.add("Country", o.Country)
+		w.events(o.Events)
+		w.entities(o.Entities)
+		w.remarks(o.Remarks, o.Notices)
+	case *Entity:
+		w.contact("", *o)
+		w.events(o.Events)
+		w.entities(o.Entities)
+		w.remarks(o.Remarks, o.Notices)
+	}
+
+	return w.String()
+}
+
+type whoisWriter struct {
+	strings.Builder
+}
+
+func (w *whoisWriter) add(key, value string) {
+	if value != "" {
+		fmt.Fprintf(w, "%s: %s\n", key, value)
+	}
+}
+
+func (w *whoisWriter) events(events Events) {
+	for _, event := range events.Sorted() {
+		if !event.Date.IsZero() {
+			w.add(whoisKey(event.Action)+" Date", event.Date.UTC().Format(time.RFC3339))
+		}
+	}
+}
+
+func (w *whoisWriter) entities(entities []Entity) {
+	for _, entity := range entities {
+		for _, role := range entity.Roles {
+			w.contact(whoisKey(role)+" ", entity)
+		}
+
+		w.entities(entity.Entities)
+	}
+}
+
+func (w *whoisWriter) contact(prefix string, e Entity) {
+	w.add(prefix+"Handle", e.Handle)
+
+	for _, name := range e.VCard("fn") {
+		w.add(prefix+"Name", name)
+	}
+
+	for _, org := range e.VCard("org") {
+		w.add(prefix+"Organization", org)
+	}
+
+	for _, address := range e.VCard("adr") {
+		w.add(prefix+"Address", address)
+	}
+
+	for _, email := range e.VCard("email") {
+		w.add(prefix+"Email", email)
+	}
+
+	for _, tel := range e.VCard("tel") {
+		w.add(prefix+"Phone", strings.TrimPrefix(tel, "tel:"))
+	}
+}
+
+func (w *whoisWriter) remarks(remarks, notices []Notice) {
+	for _, notice := range append(append([]Notice(nil), remarks...), notices...) {
+		w.WriteString("\n")
+
+		for _, line := range strings.Split(notice.String(), "\n") {
+			w.WriteString(strings.TrimRight("% "+strings.TrimSpace(line), " ") + "\n")
+		}
+	}
+}
+
+// whoisKey title-cases an RDAP role or event action, such as "last changed"
+// into "Last Changed".
+func whoisKey(s string) string {
+	words := strings.Fields(s)
+
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,51 @@
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestWhois(t *testing.T) {
+	var domain Domain
+
+	body := `{
+		"objectClassName": "domain",
+		"handle": "2336799_DOMAIN_COM-VRSN",
+		"ldhName": "example.com",
+		"status": ["client transfer prohibited"],
+		"events": [
+			{"eventAction": "expiration", "eventDate": "2030-08-13T04:00:00Z"},
+			{"eventAction": "registration", "eventDate": "1995-08-14T04:00:00Z"}
+		],
+		"entities": [{
+			"objectClassName": "entity",
+			"handle": "376",
+			"roles": ["registrar"],
+			"vcardArray": ["vcard", [["fn", {}, "text", "RESERVED-Internet Assigned Numbers Authority"]]]
+		}],
+		"nameservers": [{"objectClassName": "nameserver", "ldhName": "a.iana-servers.net"}],
+		"secureDNS": {"delegationSigned": true},
+		"notices": [{"title": "Terms of Use", "description": ["Service subject to terms."]}]
+	}`
+
+	if err := json.Unmarshal([]byte(body), &domain); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Domain Name: EXAMPLE.COM\n" +
+		"Registry Domain ID: 2336799_DOMAIN_COM-VRSN\n" +
+		"Registration Date: 1995-08-14T04:00:00Z\n" +
+		"Expiration Date: 2030-08-13T04:00:00Z\n" +
+		"Registrar Handle: 376\n" +
+		"Registrar Name: RESERVED-Internet Assigned Numbers Authority\n" +
+		"Domain Status: client transfer prohibited\n" +
+		"Name Server: A.IANA-SERVERS.NET\n" +
+		"DNSSEC: signedDelegation\n" +
+		"\n" +
+		"% Terms of Use\n" +
+		"% Service subject to terms.\n"
+
+	if text := Whois(&domain); text != expected {
+		t.Fatalf("expected %q, got %q", expected, text)
+	}
+}
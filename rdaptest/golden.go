@@ -0,0 +1,195 @@
+package rdaptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/garslo/rdap-client/protocol"
+)
+
+// RecordEnv names the environment variable that switches Golden from
+// replaying fixtures to recording them from the live servers.
+const RecordEnv = "RDAPTEST_RECORD"
+
+// Golden returns a client for tests that exercise real-world payloads. By
+// default every response is replayed from the golden files in dir, so tests
+// never touch the network. With RDAPTEST_RECORD=1 set, requests go to the live
+// servers and the responses are written to dir. Only hosts in allow may be
+// contacted, so a stray request can't turn into a fixture.
+//
+// A golden file holds a response body as the server sent it, at the path
+// FixturePath gives its URL. It is replayed as application/rdap+json, with
+// the errorCode of an RDAP error object as status and 200 otherwise, so
+// only those answers are recorded; redirects are followed but not recorded,
+// and tests should query the authoritative server directly.
+func Golden(t testing.TB, dir string, allow ...string) *http.Client {
+	t.Helper()
+
+	if os.Getenv(RecordEnv) != "1" {
+		return &http.Client{Transport: goldenTransport{dir: dir}}
+	}
+
+	return &http.Client{
+		Transport: goldenTransport{
+			dir:       dir,
+			record:    true,
+			transport: allowlistTransport{allow: allow, transport: http.DefaultTransport},
+		},
+	}
+}
+
+// queryTypes are the path segments RDAP queries start with.
+var queryTypes = map[string]bool{
+	"domain":      true,
+	"nameserver":  true,
+	"entity":      true,
+	"ip":          true,
+	"autnum":      true,
+	"domains":     true,
+	"nameservers": true,
+	"entities":    true,
+	"help":        true,
+}
+
+// FixturePath names the golden file of a query after the server host and
+// the query, as in "rdap.arin.net/ip_192.0.2.0.json" for
+// https://rdap.arin.net/registry/ip/192.0.2.0, so that the same query to
+// two registries never shares a file. Characters that are unsafe in file
+// names become "_".
+func FixturePath(u *url.URL) string {
+	host := strings.ToLower(u.Hostname())
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	for i := len(segments) - 1; i >= 0; i-- {
+		if queryTypes[segments[i]] {
+			segments = segments[i:]
+			break
+		}
+	}
+
+	name := strings.Join(segments, "_")
+
+	if u.RawQuery != "" {
+		query, _ := url.QueryUnescape(u.Query().Encode())
+		name += "_" + query
+	}
+
+	return filepath.Join(fileSafe(host), fileSafe(name)+".json")
+}
+
+func fileSafe(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune(".-=@", r):
+			return r
+		}
+
+		return '_'
+	}, name)
+}
+
+// fixtureStatus returns the status a golden file is replayed with.
+func fixtureStatus(body []byte) int {
+	var rdapError struct {
+		ErrorCode int `json:"errorCode"`
+	}
+
+	if json.Unmarshal(body, &rdapError) == nil && rdapError.ErrorCode >= 400 {
+		return rdapError.ErrorCode
+	}
+
+	return http.StatusOK
+}
+
+// goldenTransport answers requests from the golden files in dir or, when
+// record is set, from transport, writing their answers to dir.
+type goldenTransport struct {
+	dir       string
+	record    bool
+	transport http.RoundTripper
+}
+
+func (t goldenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := filepath.Join(t.dir, FixturePath(req.URL))
+
+	if !t.record {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+
+		body, err := os.ReadFile(path)
+
+		if err != nil {
+			return nil, fmt.Errorf("rdaptest: no golden file for %s: %w", req.URL, err)
+		}
+
+		status := fixtureStatus(body)
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+			StatusCode:    status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {protocol.MediaTypeRDAP}},
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+
+	if err != nil || resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if fixtureStatus(body) != resp.StatusCode {
+		return nil, fmt.Errorf("rdaptest: %s answered %d without a matching RDAP error object, which a golden file can't record", req.URL, resp.StatusCode)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(path, body, 0644); err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return resp, nil
+}
+
+type allowlistTransport struct {
+	allow     []string
+	transport http.RoundTripper
+}
+
+func (t allowlistTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := strings.ToLower(req.URL.Hostname())
+
+	for _, allowed := range t.allow {
+		if host == strings.ToLower(allowed) {
+			return t.transport.RoundTrip(req)
+		}
+	}
+
+	return nil, fmt.Errorf("rdaptest: %s is not in the golden allowlist", host)
+}
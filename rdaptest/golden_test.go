@@ -0,0 +1,211 @@
+package rdaptest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/garslo/rdap-client/client"
+)
+
+func TestGolden(t *testing.T) {
+	server := NewServer()
+	server.Handle("domain/example.com", http.StatusOK, `{"objectClassName": "domain"}`)
+
+	dir, err := os.MkdirTemp("", "rdaptest-golden")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	get := func(client *http.Client, url string) (string, error) {
+		resp, err := client.Get(url)
+
+		if err != nil {
+			return "", err
+		}
+
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+
+		return string(body), err
+	}
+
+	t.Setenv(RecordEnv, "1")
+	recorder := Golden(t, dir, "127.0.0.1")
+
+	if body, err := get(recorder, server.BaseURL()+"domain/example.com"); err != nil || body != `{"objectClassName": "domain"}` {
+		t.Fatalf("expected the live response to be recorded, got %q (%v)", body, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "127.0.0.1", "domain_example.com.json")); err != nil {
+		t.Fatalf("expected the golden file to be named after the query: %s", err)
+	}
+
+	if _, err := get(Golden(t, dir, "rdap.example.net"), server.BaseURL()+"domain/example.com"); err == nil {
+		t.Fatalf("expected hosts outside the allowlist to be refused")
+	}
+
+	server.Close()
+	t.Setenv(RecordEnv, "")
+
+	if body, err := get(Golden(t, dir), server.BaseURL()+"domain/example.com"); err != nil || body != `{"objectClassName": "domain"}` {
+		t.Fatalf("expected the golden response to be replayed, got %q (%v)", body, err)
+	}
+}
+
+func TestFixturePath(t *testing.T) {
+	tests := []struct {
+		description string
+		url         string
+		expected    string
+	}{
+		{
+			description: "it should name a lookup after the registry and the query",
+			url:         "https://rdap.arin.net/registry/ip/192.0.2.0",
+			expected:    "rdap.arin.net/ip_192.0.2.0.json",
+		},
+		{
+			description: "it should keep registries under the same name apart",
+			url:         "https://rdap.nic.fr/help",
+			expected:    "rdap.nic.fr/help.json",
+		},
+		{
+			description: "it should lower-case the host",
+			url:         "https://RDAP.nic.uk/help",
+			expected:    "rdap.nic.uk/help.json",
+		},
+		{
+			description: "it should replace characters unsafe in file names",
+			url:         "https://rdap.lacnic.net/rdap/ip/2001:db8::/32",
+			expected:    "rdap.lacnic.net/ip_2001_db8___32.json",
+		},
+		{
+			description: "it should keep the parameters of a search",
+			url:         "https://rdap.verisign.com/com/v1/domains?name=example*.com",
+			expected:    "rdap.verisign.com/domains_name=example_.com.json",
+		},
+	}
+
+	for i, test := range tests {
+		u, _ := url.Parse(test.url)
+
+		if path := filepath.ToSlash(FixturePath(u)); path != test.expected {
+			t.Fatalf("At index %d (%s): expected %s, got %s", i, test.description, test.expected, path)
+		}
+	}
+}
+
+// TestFixtures replays the checked-in golden files, or records them again
+// with RDAPTEST_RECORD=1, and decodes each without lenient decoding.
+func TestFixtures(t *testing.T) {
+	tests := []struct {
+		description   string
+		query         func(*client.Client) (interface{}, error)
+		expected      string
+		expectedError error
+	}{
+		{
+			description: "arin ip network",
+			query: func(c *client.Client) (interface{}, error) {
+				c.Host = "https://rdap.arin.net/registry/"
+				return c.IP(context.Background(), net.ParseIP("192.0.2.0"))
+			},
+			expected: "ip network",
+		},
+		{
+			description: "lacnic ipv6 network",
+			query: func(c *client.Client) (interface{}, error) {
+				c.Host = "https://rdap.lacnic.net/rdap/"
+				return c.IP(context.Background(), net.ParseIP("2001:db8::"))
+			},
+			expected: "ip network",
+		},
+		{
+			description: "ripe autnum",
+			query: func(c *client.Client) (interface{}, error) {
+				c.Host = "https://rdap.db.ripe.net/"
+				return c.Autnum(context.Background(), 64496)
+			},
+			expected: "autnum",
+		},
+		{
+			description: "ripe autnum not found",
+			query: func(c *client.Client) (interface{}, error) {
+				c.Host = "https://rdap.db.ripe.net/"
+				return c.Autnum(context.Background(), 4200000000)
+			},
+			expectedError: client.ErrNotFound,
+		},
+		{
+			description: "apnic entity",
+			query: func(c *client.Client) (interface{}, error) {
+				return c.Entity(context.Background(), "https://rdap.apnic.net/", "IRT-EXAMPLE-AP")
+			},
+			expected: "entity",
+		},
+		{
+			description: "verisign domain",
+			query: func(c *client.Client) (interface{}, error) {
+				c.Host = "https://rdap.verisign.com/com/v1/"
+				return c.Domain(context.Background(), "example.com")
+			},
+			expected: "domain",
+		},
+		{
+			description: "verisign nameserver",
+			query: func(c *client.Client) (interface{}, error) {
+				c.Host = "https://rdap.verisign.com/com/v1/"
+				return c.Nameserver(context.Background(), "a.iana-servers.net")
+			},
+			expected: "nameserver",
+		},
+	}
+
+	golden := Golden(t, "testdata", "rdap.arin.net", "rdap.lacnic.net", "rdap.db.ripe.net", "rdap.apnic.net", "rdap.verisign.com")
+
+	for i, test := range tests {
+		object, err := test.query(&client.Client{HTTP: golden})
+
+		if !errors.Is(err, test.expectedError) || (err != nil) != (test.expectedError != nil) {
+			t.Fatalf("At index %d (%s): expected error %v, got %v", i, test.description, test.expectedError, err)
+		}
+
+		if test.expectedError != nil {
+			continue
+		}
+
+		// Decode the fixture again, strictly, into a bare value of the
+		// type the query returned.
+		raw := object.(interface{ Raw() json.RawMessage }).Raw()
+		strict := reflect.New(reflect.TypeOf(object).Elem()).Interface()
+
+		if err := json.Unmarshal(raw, strict); err != nil {
+			t.Fatalf("At index %d (%s): expected the fixture to decode strictly, got %v", i, test.description, err)
+		}
+
+		if className := reflect.ValueOf(strict).Elem().FieldByName("ObjectClassName").String(); className != test.expected {
+			t.Fatalf("At index %d (%s): expected objectClassName %q, got %q", i, test.description, test.expected, className)
+		}
+	}
+
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "*", "*.json"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fixtures) != len(tests) {
+		t.Fatalf("expected a query for each of the %d fixtures %v, got %d", len(fixtures), fixtures, len(tests))
+	}
+}
@@ -0,0 +1,242 @@
+// Package rdaptest provides an in-process RDAP server for testing code that
+// bootstraps and queries RDAP without reaching real registries.
+//
+// The package follows semantic versioning like the rest of the module. The
+// fixtures it serves are an exception: they may be refreshed in any release,
+// so tests should assert on the fields they need, not on whole responses.
+package rdaptest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/garslo/rdap-client/bootstrap"
+	"github.com/garslo/rdap-client/protocol"
+)
+
+const (
+	BootstrapDNS  = bootstrap.DNS
+	BootstrapIPv4 = bootstrap.IPv4
+	BootstrapIPv6 = bootstrap.IPv6
+	BootstrapASN  = bootstrap.ASN
+)
+
+type response struct {
+	status   int
+	header   http.Header
+	body     []byte
+	location string
+}
+
+// Server serves bootstrap registries under /bootstrap/<kind>.json and canned
+// RDAP responses under /rdap/. Paths without a canned response are answered
+// with an RFC 9083 not-found error.
+type Server struct {
+	*httptest.Server
+
+	mutex      sync.Mutex
+	registries map[bootstrap.Kind]bootstrap.ServiceRegistry
+	responses  map[string]response
+	requests   []string
+}
+
+func NewServer() *Server {
+	s := &Server{
+		registries: map[bootstrap.Kind]bootstrap.ServiceRegistry{},
+		responses:  map[string]response{},
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// BaseURL returns the RDAP base URL objects are served under, suitable for
+// use in bootstrap service entries.
+func (s *Server) BaseURL() string {
+	return s.URL + "/rdap/"
+}
+
+func (s *Server) BootstrapURL(kind bootstrap.Kind) string {
+	return s.URL + "/bootstrap/" + string(kind) + ".json"
+}
+
+// Registries returns bootstrap registries fetched from this server, for use
+// as client.Client's Bootstrap.
+func (s *Server) Registries() *bootstrap.Registries {
+	return &bootstrap.Registries{
+		Client: s.Client(),
+		URLs: map[bootstrap.Kind]string{
+			BootstrapDNS:  s.BootstrapURL(BootstrapDNS),
+			BootstrapIPv4: s.BootstrapURL(BootstrapIPv4),
+			BootstrapIPv6: s.BootstrapURL(BootstrapIPv6),
+			BootstrapASN:  s.BootstrapURL(BootstrapASN),
+		},
+	}
+}
+
+// Registry builds a bootstrap registry pointing entries at this server.
+func (s *Server) Registry(entries ...string) bootstrap.ServiceRegistry {
+	return bootstrap.ServiceRegistry{
+		Version:     "1.0",
+		Publication: time.Now().UTC().Truncate(time.Second),
+		Services: bootstrap.ServicesList{
+			{entries, {s.BaseURL()}},
+		},
+	}
+}
+
+func (s *Server) SetRegistry(kind bootstrap.Kind, registry bootstrap.ServiceRegistry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.registries[kind] = registry
+}
+
+// Handle serves body with status for path, which is relative to BaseURL
+// (e.g. "domain/example.com"). Bodies that are not []byte or string are
+// encoded as JSON.
+func (s *Server) Handle(path string, status int, body interface{}) {
+	var b []byte
+
+	switch v := body.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		var err error
+
+		if b, err = json.Marshal(body); err != nil {
+			panic(err)
+		}
+	}
+
+	s.set(path, response{status: status, body: b})
+}
+
+// HandleError serves an RFC 9083 error object for path.
+func (s *Server) HandleError(path string, status int, title string, description ...string) {
+	s.Handle(path, status, errorBody(status, title, description))
+}
+
+// HandleRedirect redirects requests for path to location, which may point
+// at another server.
+func (s *Server) HandleRedirect(path string, status int, location string) {
+	s.set(path, response{status: status, location: location})
+}
+
+// HandleHeader adds a header to the canned response for path, for example
+// Retry-After on a 429.
+func (s *Server) HandleHeader(path, name, value string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	r := s.responses[strings.TrimPrefix(path, "/")]
+
+	if r.header == nil {
+		r.header = http.Header{}
+	}
+
+	r.header.Add(name, value)
+	s.responses[strings.TrimPrefix(path, "/")] = r
+}
+
+// Requests returns the paths requested so far, in order.
+func (s *Server) Requests() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) set(path string, r response) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.responses[strings.TrimPrefix(path, "/")] = r
+}
+
+func errorBody(status int, title string, description []string) map[string]interface{} {
+	body := map[string]interface{}{
+		"rdapConformance": []string{"rdap_level_0"},
+		"errorCode":       status,
+		"title":           title,
+	}
+
+	if len(description) > 0 {
+		body["description"] = description
+	}
+
+	return body
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	s.requests = append(s.requests, r.URL.RequestURI())
+	s.mutex.Unlock()
+
+	if strings.HasPrefix(r.URL.Path, "/bootstrap/") {
+		s.serveBootstrap(w, r)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/rdap/")
+
+	if path == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mutex.Lock()
+	resp, ok := s.responses[path]
+	s.mutex.Unlock()
+
+	if !ok {
+		b, _ := json.Marshal(errorBody(http.StatusNotFound, "Not Found", nil))
+		resp = response{status: http.StatusNotFound, body: b}
+	}
+
+	for name, values := range resp.header {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+
+	if resp.location != "" {
+		http.Redirect(w, r, resp.location, resp.status)
+		return
+	}
+
+	w.Header().Set("Content-Type", protocol.MediaTypeRDAP)
+	w.WriteHeader(resp.status)
+	w.Write(resp.body)
+}
+
+func (s *Server) serveBootstrap(w http.ResponseWriter, r *http.Request) {
+	kind := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/bootstrap/"), ".json")
+
+	s.mutex.Lock()
+	registry, ok := s.registries[bootstrap.Kind(kind)]
+	s.mutex.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	b, err := json.Marshal(registry)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Last-Modified", registry.Publication.UTC().Format(http.TimeFormat))
+	w.Write(b)
+}
@@ -0,0 +1,97 @@
+package rdaptest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/garslo/rdap-client/bootstrap"
+	"github.com/garslo/rdap-client/client"
+	"github.com/garslo/rdap-client/protocol"
+)
+
+func TestServer(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.SetRegistry(BootstrapDNS, server.Registry("com"))
+	server.Handle("domain/example.com", http.StatusOK, protocol.Domain{ObjectClassName: "domain", LDHName: "example.com"})
+	server.HandleError("domain/blocked.com", http.StatusTooManyRequests, "Too Many Requests")
+	server.HandleHeader("domain/blocked.com", "Retry-After", "30")
+	server.HandleRedirect("domain/moved.com", http.StatusMovedPermanently, server.BaseURL()+"domain/example.com")
+
+	registry, err := bootstrap.FetchServiceRegistry(context.Background(), server.Client(), server.BootstrapURL(BootstrapDNS))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uris, _ := registry.MatchDomain("example.com"); !reflect.DeepEqual([]string{server.BaseURL()}, uris) {
+		t.Fatalf("expected bootstrap to point at %s, got %v", server.BaseURL(), uris)
+	}
+
+	tests := []struct {
+		description  string
+		path         string
+		expectedCode int
+		expected     map[string]interface{}
+	}{
+		{
+			description:  "it should serve a canned object",
+			path:         "domain/example.com",
+			expectedCode: http.StatusOK,
+			expected:     map[string]interface{}{"objectClassName": "domain", "ldhName": "example.com"},
+		},
+		{
+			description:  "it should follow a canned redirect",
+			path:         "domain/moved.com",
+			expectedCode: http.StatusOK,
+			expected:     map[string]interface{}{"objectClassName": "domain", "ldhName": "example.com"},
+		},
+		{
+			description:  "it should serve a canned error",
+			path:         "domain/blocked.com",
+			expectedCode: http.StatusTooManyRequests,
+			expected:     map[string]interface{}{"rdapConformance": []interface{}{"rdap_level_0"}, "errorCode": float64(429), "title": "Too Many Requests"},
+		},
+		{
+			description:  "it should answer unknown objects with not found",
+			path:         "domain/unknown.com",
+			expectedCode: http.StatusNotFound,
+			expected:     map[string]interface{}{"rdapConformance": []interface{}{"rdap_level_0"}, "errorCode": float64(404), "title": "Not Found"},
+		},
+	}
+
+	for i, test := range tests {
+		resp, err := server.Client().Get(server.BaseURL() + test.path)
+
+		if err != nil {
+			t.Fatalf("At index %d (%s): unexpected error %s", i, test.description, err)
+		}
+
+		var body map[string]interface{}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+
+		if err != nil {
+			t.Fatalf("At index %d (%s): unexpected error %s", i, test.description, err)
+		}
+
+		if resp.StatusCode != test.expectedCode || !reflect.DeepEqual(test.expected, body) {
+			t.Fatalf("At index %d (%s): expected %d %v, got %d %v", i, test.description, test.expectedCode, test.expected, resp.StatusCode, body)
+		}
+	}
+
+	c := &client.Client{HTTP: server.Client(), Bootstrap: server.Registries()}
+	availability, err := c.Available(context.Background(), "unknown.com")
+
+	if err != nil || !availability.Available {
+		t.Fatalf("expected unknown.com to be available, got %+v (%v)", availability, err)
+	}
+
+	if requests := server.Requests(); requests[0] != "/bootstrap/dns.json" || len(requests) != 8 {
+		t.Fatalf("unexpected requests %v", requests)
+	}
+}